@@ -0,0 +1,41 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// writeAzureDevOps writes the layout PublishCodeCoverageResults expects to
+// dir: the Cobertura report as coverage.cobertura.xml and the HTML report in
+// report/.  When publish is not nil, the codecoverage.publish logging command
+// attaching both to the pipeline run is written to it.
+func (cov *Coverage) writeAzureDevOps(dir string, publish io.Writer) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	reportDir := filepath.Join(dir, "report")
+	if err := cov.writeHTML(reportDir); err != nil {
+		return err
+	}
+	summaryFile := filepath.Join(dir, "coverage.cobertura.xml")
+	if err := writeFile(summaryFile, cov.writeXML); err != nil {
+		return err
+	}
+
+	if publish == nil {
+		return nil
+	}
+	_, err = fmt.Fprintf(publish, "##vso[codecoverage.publish codecoveragetool=Cobertura;summaryfile=%s;reportdirectory=%s]\n",
+		azureEscape(summaryFile), azureEscape(reportDir))
+	return err
+}
+
+var azureEscaper = strings.NewReplacer("%", "%AZP25", ";", "%3B", "\r", "%0D", "\n", "%0A", "]", "%5D")
+
+// azureEscape escapes a logging command property value.
+func azureEscape(s string) string {
+	return azureEscaper.Replace(s)
+}
@@ -0,0 +1,55 @@
+package cobertura
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteAzureDevOps(t *testing.T) {
+	t.Parallel()
+
+	coverage := Coverage{
+		LinesCovered: 1,
+		LinesValid:   2,
+		Packages: []*Package{{Name: "example.com/foo", Classes: []*Class{
+			{Name: "-", Filename: "foo/foo.go", Methods: []*Method{}, Lines: Lines{{Number: 1, Hits: 1}, {Number: 2}}},
+		}}},
+	}
+
+	dir := filepath.Join(t.TempDir(), "azure;devops")
+	var publish strings.Builder
+	if err := coverage.writeAzureDevOps(dir, &publish); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := os.ReadFile(filepath.Join(dir, "coverage.cobertura.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateXML(strings.NewReader(string(report))); err != nil {
+		t.Errorf("invalid Cobertura report: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "report", "index.html")); err != nil {
+		t.Errorf("missing HTML report: %v", err)
+	}
+
+	escaped := azureEscape(dir)
+	expected := "##vso[codecoverage.publish codecoveragetool=Cobertura;summaryfile=" +
+		escaped + "/coverage.cobertura.xml;reportdirectory=" + escaped + "/report]\n"
+	if publish.String() != expected {
+		t.Errorf("unexpected logging command:\n%s\nexpected:\n%s", publish.String(), expected)
+	}
+	if strings.Contains(escaped, ";") {
+		t.Errorf("unescaped property value %q", escaped)
+	}
+}
+
+func TestAzureEscape(t *testing.T) {
+	t.Parallel()
+
+	if actual := azureEscape("a;b]c%d\r\n"); actual != "a%3Bb%5Dc%AZP25d%0D%0A" {
+		t.Errorf("unexpected escaping %q", actual)
+	}
+}
@@ -0,0 +1,40 @@
+package cobertura
+
+// linePercent returns the percentage of covered lines, 0 when no line is
+// valid.
+func linePercent(covered, valid int64) float64 {
+	if valid == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(valid)
+}
+
+// baselineViolations returns the total and the packages whose line coverage
+// dropped from the baseline report by more than tolerance.
+func baselineViolations(coverage, baseline *Coverage, tolerance float64) []*ThresholdError {
+	var violations []*ThresholdError
+	drop := func(name string, actual, previous float64) {
+		if previous-actual > tolerance {
+			violations = append(violations, &ThresholdError{
+				Kind: ThresholdBaseline, Package: name, Actual: actual, Required: previous - tolerance, Baseline: previous,
+			})
+		}
+	}
+
+	drop("", linePercent(coverage.NumLinesWithHits(), coverage.NumLines()),
+		linePercent(baseline.NumLinesWithHits(), baseline.NumLines()))
+
+	previous := make(map[string]*Package, len(baseline.Packages))
+	for _, pkg := range baseline.Packages {
+		previous[pkg.Name] = pkg
+	}
+	for _, pkg := range coverage.Packages {
+		old := previous[pkg.Name]
+		if old == nil || pkg.NumLines() == 0 || old.NumLines() == 0 {
+			continue
+		}
+		drop(pkg.Name, linePercent(pkg.NumLinesWithHits(), pkg.NumLines()),
+			linePercent(old.NumLinesWithHits(), old.NumLines()))
+	}
+	return violations
+}
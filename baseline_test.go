@@ -0,0 +1,52 @@
+package cobertura
+
+import (
+	"strings"
+	"testing"
+)
+
+func baselineCoverage(hits ...[]int64) *Coverage {
+	coverage := &Coverage{}
+	for index, pkgHits := range hits {
+		lines := Lines{}
+		for number, hit := range pkgHits {
+			lines = append(lines, &Line{Number: number + 1, Hits: hit})
+		}
+		coverage.Packages = append(coverage.Packages, &Package{
+			Name: []string{"example.com/a", "example.com/b", "example.com/c"}[index],
+			Classes: []*Class{{
+				Name: "-", Filename: "f.go", Lines: lines,
+				Methods: []*Method{{Name: "f", Lines: lines}},
+			}},
+		})
+	}
+	return coverage
+}
+
+func TestCheckRulesBaseline(t *testing.T) {
+	t.Parallel()
+
+	baseline := baselineCoverage([]int64{1, 1, 0, 0}, []int64{1, 1}, []int64{0})
+	coverage := baselineCoverage([]int64{1, 0, 0, 0}, []int64{1, 1})
+
+	err := checkRules(coverage, Rules{Baseline: baseline})
+	if err == nil {
+		t.Fatal("no error for a regression")
+	}
+	expected := "line coverage regressed from the baseline:\n" +
+		"  total: 57.14% -> 50.00% (-7.14)\n" +
+		"  example.com/a: 50.00% -> 25.00% (-25.00)"
+	if err.Error() != expected {
+		t.Errorf("error:\n%s\nexpected:\n%s", err, expected)
+	}
+
+	if err := checkRules(coverage, Rules{Baseline: baseline, Tolerance: 25}); err != nil {
+		t.Errorf("error within the tolerance: %v", err)
+	}
+
+	worse := baselineCoverage([]int64{0, 0, 0, 0}, []int64{1, 0})
+	err = checkRules(worse, Rules{Baseline: baseline})
+	if err == nil || !strings.Contains(err.Error(), "  total: 57.14% -> 16.67% (-40.48)") {
+		t.Errorf("missing the total drop in %v", err)
+	}
+}
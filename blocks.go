@@ -0,0 +1,56 @@
+package cobertura
+
+import "sort"
+
+// blockIndex finds the profile blocks around a position of a file in
+// logarithmic time instead of scanning them from the first one, which made
+// the conversion of files of many functions quadratic.
+type blockIndex struct {
+	blocks []ProfileBlock // sorted by start
+	// furthest[i] is the furthest end of blocks[:i+1]: blocks may overlap
+	// in merged profiles, so their ends are not sorted
+	furthest []blockEnd
+}
+
+// blockEnd is the end of a profile block.
+type blockEnd struct {
+	line, col int
+}
+
+func newBlockIndex(blocks []ProfileBlock) *blockIndex {
+	furthest := make([]blockEnd, len(blocks))
+	for index, block := range blocks {
+		furthest[index] = blockEnd{block.EndLine, block.EndCol}
+		if index > 0 && before(block.EndLine, block.EndCol, furthest[index-1].line, furthest[index-1].col) {
+			furthest[index] = furthest[index-1]
+		}
+	}
+	return &blockIndex{blocks: blocks, furthest: furthest}
+}
+
+// blockIndex returns the index of the blocks of the profile of the visited
+// file, built on first use.
+func (v *fileVisitor) blockIndex() *blockIndex {
+	if v.blocks == nil {
+		v.blocks = newBlockIndex(v.profile.Blocks)
+	}
+	return v.blocks
+}
+
+// endingAfter returns the blocks from the first one which may end after
+// line.col: all the blocks before it end at or before it.
+func (x *blockIndex) endingAfter(line, col int) []ProfileBlock {
+	first := sort.Search(len(x.furthest), func(index int) bool {
+		return before(line, col, x.furthest[index].line, x.furthest[index].col)
+	})
+	return x.blocks[first:]
+}
+
+// startingFrom returns the blocks from the first one starting at or after
+// line.col.
+func (x *blockIndex) startingFrom(line, col int) []ProfileBlock {
+	first := sort.Search(len(x.blocks), func(index int) bool {
+		return !before(x.blocks[index].StartLine, x.blocks[index].StartCol, line, col)
+	})
+	return x.blocks[first:]
+}
@@ -0,0 +1,88 @@
+package cobertura
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestBlockIndex(t *testing.T) {
+	t.Parallel()
+
+	// sorted by start, the second one overlapping the next ones as in
+	// merged profiles
+	blocks := []ProfileBlock{
+		{StartLine: 1, StartCol: 5, EndLine: 2, EndCol: 3},
+		{StartLine: 3, StartCol: 1, EndLine: 9, EndCol: 1},
+		{StartLine: 4, StartCol: 2, EndLine: 5, EndCol: 8},
+		{StartLine: 6, StartCol: 1, EndLine: 7, EndCol: 1},
+		{StartLine: 10, StartCol: 1, EndLine: 11, EndCol: 1},
+	}
+	index := newBlockIndex(blocks)
+	for line := 0; line <= 12; line++ {
+		for col := 0; col <= 9; col++ {
+			// the blocks the linear scans may not skip
+			ending, starting := len(blocks), len(blocks)
+			for i := len(blocks) - 1; i >= 0; i-- {
+				if before(line, col, blocks[i].EndLine, blocks[i].EndCol) {
+					ending = i
+				}
+				if !before(blocks[i].StartLine, blocks[i].StartCol, line, col) {
+					starting = i
+				}
+			}
+			if actual := index.endingAfter(line, col); len(actual) < len(blocks)-ending {
+				t.Errorf("%d blocks ending after %d.%d, expected at least %d", len(actual), line, col, len(blocks)-ending)
+			}
+			if actual := index.startingFrom(line, col); len(actual) != len(blocks)-starting {
+				t.Errorf("%d blocks starting from %d.%d, expected %d", len(actual), line, col, len(blocks)-starting)
+			}
+		}
+	}
+	if actual := index.endingAfter(5, 1); len(actual) != 4 {
+		t.Errorf("%d blocks ending after 5.1, expected the 4 from the overlapping one", len(actual))
+	}
+}
+
+// BenchmarkFileVisitor converts a generated file of many functions, whose
+// blocks were scanned from the first one, and whose class rates were
+// computed again, for every function.
+func BenchmarkFileVisitor(b *testing.B) {
+	for _, funcs := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprint(funcs), func(b *testing.B) {
+			var src strings.Builder
+			src.WriteString("package p\n")
+			profile := &Profile{FileName: "p.go", Mode: "count"}
+			for i := 0; i < funcs; i++ {
+				line := 2 + 6*i
+				fmt.Fprintf(&src, "func F%d(x int) int {\n\tif x > 0 {\n\t\treturn 1\n\t}\n\treturn 0\n}\n", i)
+				profile.Blocks = append(profile.Blocks,
+					ProfileBlock{StartLine: line, StartCol: 21, EndLine: line + 1, EndCol: 11, NumStmt: 1, Count: 2},
+					ProfileBlock{StartLine: line + 1, StartCol: 11, EndLine: line + 3, EndCol: 3, NumStmt: 1, Count: i % 2},
+					ProfileBlock{StartLine: line + 4, StartCol: 2, EndLine: line + 4, EndCol: 10, NumStmt: 1, Count: 1},
+				)
+			}
+			fset := token.NewFileSet()
+			parsed, err := parser.ParseFile(fset, "p.go", src.String(), 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				visitor := &fileVisitor{
+					fset:     fset,
+					fileName: "p.go",
+					classes:  map[string]*Class{},
+					pkg:      &Package{Name: "p"},
+					profile:  profile,
+				}
+				ast.Walk(visitor, parsed)
+				visitor.setRates()
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package cobertura
+
+import (
+	"encoding/xml"
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// branchCount is the number of outcomes of the branch statements of a line,
+// and how many of them were taken.
+type branchCount struct {
+	covered, valid int
+}
+
+// branches returns the branch coverage of the if, for, range, switch and
+// select statements of the function by line, from the counts of the profile
+// blocks of their bodies and of the blocks evaluating them.  In set mode, the
+// implicit outcomes, the false branch of an if without else and the missing
+// default of a switch, are only known to be taken when no explicit outcome
+// was.
+func (v *fileVisitor) branches(n *ast.FuncDecl) map[int]branchCount {
+	if n.Body == nil {
+		return nil
+	}
+	counts := v.profile.Mode != "set"
+	branches := map[int]branchCount{}
+	add := func(pos token.Pos, taken ...bool) {
+		line := v.fset.Position(pos).Line
+		count := branches[line]
+		for _, t := range taken {
+			count.valid++
+			if t {
+				count.covered++
+			}
+		}
+		branches[line] = count
+	}
+
+	ast.Inspect(n.Body, func(node ast.Node) bool {
+		switch s := node.(type) {
+		case *ast.IfStmt:
+			evaluated := v.containingCount(s.Pos())
+			body := v.bodyCount(s.Body.Lbrace, s.Body.End())
+			var otherwise bool
+			switch e := s.Else.(type) {
+			case *ast.BlockStmt:
+				otherwise = v.bodyCount(e.Lbrace, e.End()) > 0
+			case *ast.IfStmt:
+				otherwise = v.containingCount(e.Pos()) > 0
+			default:
+				otherwise = (counts && evaluated > body) || (!counts && evaluated > 0 && body == 0)
+			}
+			add(s.Pos(), body > 0, otherwise)
+		case *ast.ForStmt:
+			if s.Cond != nil {
+				add(s.Pos(), v.bodyCount(s.Body.Lbrace, s.Body.End()) > 0, v.containingCount(s.Pos()) > 0)
+			}
+		case *ast.RangeStmt:
+			add(s.Pos(), v.bodyCount(s.Body.Lbrace, s.Body.End()) > 0, v.containingCount(s.Pos()) > 0)
+		case *ast.SwitchStmt:
+			v.addClauses(add, s.Pos(), s.Body, counts)
+		case *ast.TypeSwitchStmt:
+			v.addClauses(add, s.Pos(), s.Body, counts)
+		case *ast.SelectStmt:
+			for _, stmt := range s.Body.List {
+				clause := stmt.(*ast.CommClause)
+				add(s.Pos(), v.bodyCount(clause.Colon, clause.End()) > 0)
+			}
+		}
+		return true
+	})
+	return branches
+}
+
+// addClauses adds the outcomes of the case clauses of a switch, and of its
+// implicit default if it has none.
+func (v *fileVisitor) addClauses(add func(token.Pos, ...bool), pos token.Pos, body *ast.BlockStmt, counts bool) {
+	var taken int64
+	hasDefault := false
+	for _, stmt := range body.List {
+		clause := stmt.(*ast.CaseClause)
+		count := v.bodyCount(clause.Colon, clause.End())
+		taken += count
+		hasDefault = hasDefault || clause.List == nil
+		add(pos, count > 0)
+	}
+	if !hasDefault {
+		evaluated := v.containingCount(pos)
+		add(pos, (counts && evaluated > taken) || (!counts && evaluated > 0 && taken == 0))
+	}
+}
+
+// containingCount returns the count of the profile block holding pos.
+func (v *fileVisitor) containingCount(pos token.Pos) int64 {
+	p := v.fset.Position(pos)
+	for _, block := range v.blockIndex().endingAfter(p.Line, p.Column) {
+		if before(p.Line, p.Column, block.StartLine, block.StartCol) {
+			break
+		}
+		if before(p.Line, p.Column, block.EndLine, block.EndCol) {
+			return int64(block.Count)
+		}
+	}
+	return 0
+}
+
+// bodyCount returns the count of the first profile block starting between
+// start and end, the block entering a body.  Depending on the Go version, it
+// starts at the brace or colon of the body or at its first statement.
+func (v *fileVisitor) bodyCount(start, end token.Pos) int64 {
+	s, e := v.fset.Position(start), v.fset.Position(end)
+	for _, block := range v.blockIndex().startingFrom(s.Line, s.Column) {
+		if before(block.StartLine, block.StartCol, s.Line, s.Column) {
+			continue
+		}
+		if before(e.Line, e.Column, block.StartLine, block.StartCol) {
+			break
+		}
+		return int64(block.Count)
+	}
+	return 0
+}
+
+// before reports whether line1.col1 is before line2.col2.
+func before(line1, col1, line2, col2 int) bool {
+	return line1 < line2 || (line1 == line2 && col1 < col2)
+}
+
+// SetBranches marks the line as a branch point with the outcomes, covered of
+// valid taken, or as not a branch point when there is none.
+func (line *Line) SetBranches(covered, valid int) {
+	line.branchesCovered, line.branchesValid = covered, valid
+	if valid == 0 {
+		line.Branch, line.ConditionCoverage = false, ""
+		return
+	}
+	line.Branch = true
+	line.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)", 100*covered/valid, covered, valid)
+}
+
+// branchCounts returns the outcomes of the line taken and in total.
+func (line *Line) branchCounts() (covered, valid int) {
+	return line.branchesCovered, line.branchesValid
+}
+
+// UnmarshalXML decodes a line of a report, reading its branch outcomes from
+// its condition coverage.
+func (line *Line) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type xmlLine Line // without the methods, not to recurse
+	if err := d.DecodeElement((*xmlLine)(line), &start); err != nil {
+		return err
+	}
+	if !line.Branch || line.ConditionCoverage == "" {
+		return nil
+	}
+	var percent, covered, valid int
+	if _, err := fmt.Sscanf(line.ConditionCoverage, "%d%% (%d/%d)", &percent, &covered, &valid); err != nil {
+		return fmt.Errorf("line %d: bad condition-coverage %q", line.Number, line.ConditionCoverage)
+	}
+	line.branchesCovered, line.branchesValid = covered, valid
+	return nil
+}
+
+// mergeBranches merges the branch outcomes of the same line of another
+// report, keeping the most outcomes, as the taken ones cannot be matched.
+func (line *Line) mergeBranches(other *Line) {
+	covered, valid := line.branchCounts()
+	otherCovered, otherValid := other.branchCounts()
+	line.SetBranches(max(covered, otherCovered), max(valid, otherValid))
+}
+
+// NumBranches returns the number of branch outcomes of the lines.
+func (lines Lines) NumBranches() (numBranches int64) {
+	for _, line := range lines {
+		_, valid := line.branchCounts()
+		numBranches += int64(valid)
+	}
+	return numBranches
+}
+
+// NumBranchesCovered returns the number of branch outcomes of the lines which
+// were taken.
+func (lines Lines) NumBranchesCovered() (numBranchesCovered int64) {
+	for _, line := range lines {
+		covered, _ := line.branchCounts()
+		numBranchesCovered += int64(covered)
+	}
+	return numBranchesCovered
+}
+
+// branchRate returns covered/valid, 0 when there is no branch.
+func branchRate(covered, valid int64) float32 {
+	if valid == 0 {
+		return 0
+	}
+	return float32(covered) / float32(valid)
+}
@@ -0,0 +1,131 @@
+package cobertura
+
+import (
+	"context"
+	"encoding/xml"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const branchSource = `package p
+
+func F(x int) int {
+	if x > 0 {
+		return 1
+	}
+	switch x {
+	case -1:
+		return -1
+	case -2:
+		return -2
+	}
+	return 0
+}
+`
+
+func TestFileVisitorBranches(t *testing.T) {
+	t.Parallel()
+
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "p.go", branchSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := func(counts ...int) []ProfileBlock {
+		return []ProfileBlock{
+			{StartLine: 3, StartCol: 19, EndLine: 4, EndCol: 11, NumStmt: 1, Count: counts[0]},
+			{StartLine: 4, StartCol: 11, EndLine: 6, EndCol: 3, NumStmt: 1, Count: counts[1]},
+			{StartLine: 7, StartCol: 2, EndLine: 7, EndCol: 10, NumStmt: 1, Count: counts[2]},
+			{StartLine: 8, StartCol: 10, EndLine: 9, EndCol: 12, NumStmt: 1, Count: counts[3]},
+			{StartLine: 10, StartCol: 10, EndLine: 11, EndCol: 12, NumStmt: 1, Count: counts[4]},
+			{StartLine: 13, StartCol: 2, EndLine: 13, EndCol: 10, NumStmt: 1, Count: counts[5]},
+		}
+	}
+
+	for _, test := range []struct {
+		name     string
+		mode     string
+		counts   []int
+		expected map[int]branchCount
+	}{
+		{"count", "count", []int{5, 2, 3, 1, 0, 2}, map[int]branchCount{4: {2, 2}, 7: {2, 3}}},
+		{"set", "set", []int{1, 1, 1, 1, 0, 1}, map[int]branchCount{4: {1, 2}, 7: {1, 3}}},
+		{"set not taken", "set", []int{1, 0, 1, 0, 0, 1}, map[int]branchCount{4: {1, 2}, 7: {1, 3}}},
+		{"not run", "set", []int{0, 0, 0, 0, 0, 0}, map[int]branchCount{4: {0, 2}, 7: {0, 3}}},
+	} {
+		profile := &Profile{FileName: "p.go", Mode: test.mode, Blocks: blocks(test.counts...)}
+		visitor := &fileVisitor{fset: fset, profile: profile}
+		actual := visitor.branches(parsed.Decls[0].(*ast.FuncDecl))
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: branches %v, expected %v", test.name, actual, test.expected)
+		}
+	}
+}
+
+func TestLineBranches(t *testing.T) {
+	t.Parallel()
+
+	line := &Line{Number: 1}
+	line.SetBranches(1, 3)
+	if !line.Branch || line.ConditionCoverage != "33% (1/3)" {
+		t.Errorf("line %+v, expected a branch with 33%% (1/3)", line)
+	}
+	if covered, valid := line.branchCounts(); covered != 1 || valid != 3 {
+		t.Errorf("branch counts %d/%d, expected 1/3", covered, valid)
+	}
+
+	var other Line
+	if err := xml.Unmarshal([]byte(`<line number="1" hits="1" branch="true" condition-coverage="100% (2/2)"/>`), &other); err != nil {
+		t.Fatal(err)
+	}
+	line.mergeBranches(&other)
+	if line.ConditionCoverage != "66% (2/3)" {
+		t.Errorf("merged condition coverage %q, expected 66%% (2/3)", line.ConditionCoverage)
+	}
+	if err := xml.Unmarshal([]byte(`<line number="2" hits="1" branch="true" condition-coverage="half"/>`), &other); err == nil {
+		t.Error("no error for a malformed condition coverage")
+	}
+
+	line.SetBranches(0, 0)
+	if line.Branch || line.ConditionCoverage != "" {
+		t.Errorf("line %+v, expected no branch", line)
+	}
+}
+
+func TestConvertBranches(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	coverage, err := convert(context.Background(), in, &Ignore{}, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coverage.BranchesValid == 0 || coverage.BranchesCovered == 0 {
+		t.Fatalf("branches %d/%d, expected some", coverage.BranchesCovered, coverage.BranchesValid)
+	}
+	if rate := branchRate(coverage.BranchesCovered, coverage.BranchesValid); coverage.BranchRate != rate {
+		t.Errorf("branch rate %v, expected %v", coverage.BranchRate, rate)
+	}
+
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			if class.Filename != "testdata/func2.go" {
+				continue
+			}
+			for _, line := range class.Lines {
+				if line.Number == 9 && line.ConditionCoverage != "50% (1/2)" {
+					t.Errorf("func2.go:9 condition coverage %q, expected 50%% (1/2)", line.ConditionCoverage)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cobertura
+
+import (
+	"sort"
+	"time"
+)
+
+// NewCoverage returns an empty coverage of the source directories, dated
+// now, to build a report with AddPackage, AddClass, AddMethod and AddLine
+// before calling Recompute.
+func NewCoverage(sources ...string) *Coverage {
+	cov := &Coverage{Timestamp: time.Now().UnixMilli(), Packages: []*Package{}}
+	for _, source := range sources {
+		cov.Sources = appendIfUnique(cov.Sources, source)
+	}
+	return cov
+}
+
+// AddPackage returns the package of the coverage with the name, added if
+// missing.
+func (cov *Coverage) AddPackage(name string) *Package {
+	for _, pkg := range cov.Packages {
+		if pkg.Name == name {
+			return pkg
+		}
+	}
+	pkg := &Package{Name: name, Classes: []*Class{}}
+	cov.Packages = append(cov.Packages, pkg)
+	return pkg
+}
+
+// AddClass returns the class of the package with the name and filename,
+// added if missing.
+func (pkg *Package) AddClass(name, filename string) *Class {
+	for _, class := range pkg.Classes {
+		if class.Name == name && class.Filename == filename {
+			return class
+		}
+	}
+	class := &Class{Name: name, Filename: filename, Methods: []*Method{}, Lines: Lines{}}
+	pkg.Classes = append(pkg.Classes, class)
+	return class
+}
+
+// AddMethod returns the method of the class with the name and signature,
+// added if missing.
+func (class *Class) AddMethod(name, signature string) *Method {
+	for _, method := range class.Methods {
+		if method.Name == name && method.Signature == signature {
+			return method
+		}
+	}
+	method := &Method{Name: name, Signature: signature, Lines: Lines{}}
+	class.Methods = append(class.Methods, method)
+	return method
+}
+
+// AddLine adds hits to the line with the number of the method of the class,
+// added if missing to both, the lines being kept sorted by number.  The class
+// and its method share the line, as the totals only count the lines of the
+// methods.
+func (class *Class) AddLine(method *Method, number int, hits int64) *Line {
+	line := class.Lines.find(number)
+	if line == nil {
+		line = &Line{Number: number}
+		class.Lines = class.Lines.insert(line)
+	}
+	line.Hits += hits
+	if method.Lines.find(number) == nil {
+		method.Lines = method.Lines.insert(line)
+	}
+	return line
+}
+
+// find returns the line with the number of the lines sorted by number, or
+// nil.
+func (lines Lines) find(number int) *Line {
+	index := sort.Search(len(lines), func(i int) bool { return lines[i].Number >= number })
+	if index < len(lines) && lines[index].Number == number {
+		return lines[index]
+	}
+	return nil
+}
+
+// insert returns the lines sorted by number with the line inserted.
+func (lines Lines) insert(line *Line) Lines {
+	index := sort.Search(len(lines), func(i int) bool { return lines[i].Number >= line.Number })
+	lines = append(lines, nil)
+	copy(lines[index+1:], lines[index:])
+	lines[index] = line
+	return lines
+}
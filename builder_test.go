@@ -0,0 +1,60 @@
+package cobertura
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	t.Parallel()
+
+	cov := NewCoverage("/src", "/src")
+	if len(cov.Sources) != 1 {
+		t.Errorf("sources %v, expected a single one", cov.Sources)
+	}
+	pkg := cov.AddPackage("example.com/p")
+	if cov.AddPackage("example.com/p") != pkg {
+		t.Error("package added twice")
+	}
+	class := pkg.AddClass("T", "p/t.go")
+	if pkg.AddClass("T", "p/t.go") != class {
+		t.Error("class added twice")
+	}
+	method := class.AddMethod("Run", "()")
+	if class.AddMethod("Run", "()") != method {
+		t.Error("method added twice")
+	}
+	other := class.AddMethod("Stop", "()")
+
+	class.AddLine(method, 12, 1)
+	class.AddLine(method, 10, 2)
+	class.AddLine(method, 12, 3)
+	class.AddLine(other, 20, 0)
+	cov.Recompute()
+
+	var numbers []int
+	for _, line := range class.Lines {
+		numbers = append(numbers, line.Number)
+	}
+	if len(numbers) != 3 || numbers[0] != 10 || numbers[1] != 12 || numbers[2] != 20 {
+		t.Errorf("class lines %v, expected 10, 12 and 20", numbers)
+	}
+	if len(method.Lines) != 2 || method.Lines[1].Hits != 4 || method.Lines[1] != class.Lines[1] {
+		t.Errorf("method lines %v, expected 2 lines shared with the class, line 12 hit 4 times", method.Lines)
+	}
+	if method.LineRate != 1 || other.LineRate != 0 || class.LineRate != 2.0/3 || pkg.LineRate != 2.0/3 {
+		t.Errorf("rates %v %v %v %v, expected 1, 0, 2/3 and 2/3", method.LineRate, other.LineRate, class.LineRate, pkg.LineRate)
+	}
+	if cov.LinesValid != 3 || cov.LinesCovered != 2 {
+		t.Errorf("totals %d/%d, expected 2/3", cov.LinesCovered, cov.LinesValid)
+	}
+
+	var out bytes.Buffer
+	if err := cov.writeXML(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `<class name="T" filename="p/t.go" line-rate="0.6666667"`) {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+}
@@ -0,0 +1,135 @@
+package cobertura
+
+import (
+	"fmt"
+	"go/build"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// excludedFileError returns the error of a profile file left out of its
+// package by the build constraints of the conversion, naming the tags which
+// would include it, or nil if the file is not among the ignored files of
+// the package.
+func excludedFileError(pkg *packages.Package, profileName string) error {
+	filename := filepath.Base(cgoSourceName(profileName))
+	for _, path := range pkg.IgnoredFiles {
+		if filepath.Base(path) != filename {
+			continue
+		}
+		msg := filename + " is excluded from package " + pkg.ID
+		suggestion := "skip it with -keep-going"
+		expr := buildConstraint(path)
+		switch tags := includingTags(expr); {
+		case expr == nil:
+			msg += " by its name, for another GOOS or GOARCH"
+		case tags == nil:
+			msg += fmt.Sprintf(" by the build constraint %q, which -tags cannot satisfy", expr.String())
+		default:
+			msg += fmt.Sprintf(" by the build constraint %q: convert with -tags %s like the tests,", expr.String(), strings.Join(tags, ","))
+			suggestion = "convert with -tags " + strings.Join(tags, ",")
+		}
+		return &kindError{
+			kind:       ErrSourceMissing,
+			msg:        msg + " or skip it with -keep-going",
+			reason:     "excluded by build constraints",
+			suggestion: suggestion,
+		}
+	}
+	return nil
+}
+
+// buildConstraint returns the build constraint of the Go file, its
+// //go:build or // +build lines before the package clause, or nil if it
+// has none or cannot be read.
+func buildConstraint(path string) constraint.Expr {
+	parsed, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+	var plusBuild constraint.Expr
+	for _, group := range parsed.Comments {
+		if group.Pos() > parsed.Package {
+			break
+		}
+		for _, comment := range group.List {
+			expr, err := constraint.Parse(comment.Text)
+			switch {
+			case err != nil:
+			case constraint.IsGoBuild(comment.Text):
+				return expr
+			case plusBuild == nil:
+				plusBuild = expr
+			default:
+				plusBuild = &constraint.AndExpr{X: plusBuild, Y: expr}
+			}
+		}
+	}
+	return plusBuild
+}
+
+// includingTags returns the tags which, added to the ones of the default
+// build context, satisfy the build constraint, or nil if none do.
+func includingTags(expr constraint.Expr) []string {
+	if expr == nil {
+		return nil
+	}
+	defaults := map[string]bool{build.Default.GOOS: true, build.Default.GOARCH: true}
+	for _, tag := range append(build.Default.ReleaseTags, build.Default.BuildTags...) {
+		defaults[tag] = true
+	}
+	defaults["cgo"] = build.Default.CgoEnabled
+	defaults["unix"] = build.Default.GOOS != "windows" && build.Default.GOOS != "plan9" &&
+		build.Default.GOOS != "js" && build.Default.GOOS != "wasip1"
+
+	added := map[string]bool{}
+	var collect func(expr constraint.Expr, negated bool)
+	collect = func(expr constraint.Expr, negated bool) {
+		switch e := expr.(type) {
+		case *constraint.TagExpr:
+			if !negated && !defaults[e.Tag] && !knownTag(e.Tag) {
+				added[e.Tag] = true
+			}
+		case *constraint.NotExpr:
+			collect(e.X, !negated)
+		case *constraint.AndExpr:
+			collect(e.X, negated)
+			collect(e.Y, negated)
+		case *constraint.OrExpr:
+			collect(e.X, negated)
+			collect(e.Y, negated)
+		}
+	}
+	collect(expr, false)
+	if len(added) == 0 || !expr.Eval(func(tag string) bool { return defaults[tag] || added[tag] }) {
+		return nil
+	}
+	tags := make([]string, 0, len(added))
+	for tag := range added {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// knownTag reports whether the tag is set by the toolchain rather than by
+// -tags, as the GOOS, GOARCH and Go release ones.
+func knownTag(tag string) bool {
+	if strings.HasPrefix(tag, "go1.") {
+		return true
+	}
+	switch tag {
+	case "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js", "linux",
+		"nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+		"386", "amd64", "arm", "arm64", "loong64", "mips", "mipsle", "mips64", "mips64le",
+		"ppc64", "ppc64le", "riscv64", "s390x", "wasm", "cgo", "unix", "gc", "gccgo":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package cobertura
+
+import (
+	"errors"
+	"go/build/constraint"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestIncludingTags(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		line     string
+		expected []string
+	}{
+		{"//go:build testdata", []string{"testdata"}},
+		{"//go:build integration && !short", []string{"integration"}},
+		{"//go:build e2e || (integration && slow)", []string{"e2e", "integration", "slow"}},
+		{"//go:build !testdata", nil},
+		{"//go:build plan9 && testdata", nil},
+	} {
+		expr, err := constraint.Parse(test.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := includingTags(expr); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: tags %v, expected %v", test.line, actual, test.expected)
+		}
+	}
+}
+
+func TestExcludedFileError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tagged := filepath.Join(dir, "tagged.go")
+	if err := os.WriteFile(tagged, []byte("//go:build integration\n\npackage p\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "x_plan9.go")
+	if err := os.WriteFile(other, []byte("package p\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{ID: "example.com/p", IgnoredFiles: []string{tagged, other}}
+
+	err := excludedFileError(pkg, "example.com/p/tagged.go")
+	if !errors.Is(err, ErrSourceMissing) || !strings.Contains(err.Error(), "convert with -tags integration") {
+		t.Errorf("error %v, expected a source missing without -tags integration", err)
+	}
+	err = excludedFileError(pkg, "example.com/p/x_plan9.go")
+	if err == nil || !strings.Contains(err.Error(), "by its name") {
+		t.Errorf("error %v, expected a file excluded by its name", err)
+	}
+	if err := excludedFileError(pkg, "example.com/p/missing.go"); err != nil {
+		t.Errorf("error %v of a file out of the package, expected none", err)
+	}
+}
@@ -0,0 +1,98 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Check parses the profile read from in and resolves its packages and
+// sources like a conversion, printing the problems found, such as missing
+// files, unparsable sources or blocks past the end of their lines, without
+// building the report.  It fails if any problem is found.
+func Check(in io.Reader, out io.Writer, ignore Matcher, buildTags ...string) error {
+	return check(in, out, ignore, nil, buildTags)
+}
+
+// check is Check, reading the profile and loading its packages with opts.
+func check(in io.Reader, out io.Writer, ignore Matcher, opts *Options, buildTags []string) error {
+	profiles, err := parseProfiles(context.Background(), in, ignore, opts)
+	if err != nil {
+		return withExitCode(profileExitCode(err), err)
+	}
+
+	pkgs, err := getPackages(context.Background(), profiles, buildTags, opts)
+	if err != nil {
+		return withExitCode(exitPackages, err)
+	}
+
+	problems := 0
+	report := func(fileName, format string, args ...any) {
+		problems++
+		_, _ = fmt.Fprintf(out, "%s: %s\n", fileName, fmt.Sprintf(format, args...))
+	}
+
+	pkgMap := make(map[string]*packages.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, pkgErr := range pkg.Errors {
+			report(pkg.ID, "package load error: %s", pkgErr.Error())
+		}
+		pkgMap[pkg.ID] = pkg
+	}
+
+	for _, profile := range profiles {
+		pkgPkg := lookupPackage(pkgMap, getPackageName(profile.FileName))
+		absFilePath, data, err := resolveSource(profile, pkgPkg, nil)
+		if err != nil {
+			report(profile.FileName, "%v", err)
+			continue
+		}
+		if matchFile(ignore, trimModulePath(profile.FileName, pkgPkg.Module.Path), data) {
+			continue
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), absFilePath, data, 0); err != nil {
+			report(profile.FileName, "unparsable source: %v", err)
+			continue
+		}
+
+		lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+		for _, block := range profile.Blocks {
+			if stale := staleBlock(block, lines); stale != "" {
+				report(profile.FileName, "block %d.%d,%d.%d %s, the profile may be stale",
+					block.StartLine, block.StartCol, block.EndLine, block.EndCol, stale)
+			}
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("check failed: %d problem(s) found", problems)
+	}
+	_, _ = fmt.Fprintf(out, "no problem found in %d file(s)\n", len(profiles))
+	return nil
+}
+
+// staleBlock returns why the block does not fit the lines of its source, or
+// "" if it fits.  Columns are 1-based byte offsets, the end one being past
+// the last byte of the block.
+func staleBlock(block ProfileBlock, lines [][]byte) string {
+	switch {
+	case block.StartLine < 1 || block.EndLine < block.StartLine:
+		return "has a bad line range"
+	case block.EndLine > len(lines):
+		return fmt.Sprintf("ends past the last line %d", len(lines))
+	case block.StartCol > len(lines[block.StartLine-1])+1:
+		return fmt.Sprintf("starts past the end of line %d", block.StartLine)
+	case block.EndCol > len(lines[block.EndLine-1])+1:
+		return fmt.Sprintf("ends past the end of line %d", block.EndLine)
+	default:
+		return ""
+	}
+}
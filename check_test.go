@@ -0,0 +1,78 @@
+package cobertura
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 0
+github.com/franchb/gocover-cobertura/testdata/func4.go:6.16,8.3 1 0
+github.com/franchb/gocover-cobertura/testdata/func5.go:7.23,8.16 1 0
+github.com/franchb/gocover-cobertura/testdata/func5.go:8.16,40.3 1 0
+github.com/franchb/gocover-cobertura/testdata/missing.go:1.1,2.2 1 0
+`
+	var out bytes.Buffer
+	err := Check(strings.NewReader(profile), &out, &Ignore{}, "testdata")
+	if err == nil || !strings.Contains(err.Error(), "2 problem(s)") {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, expected := range []string{
+		"testdata/func5.go: block 8.16,40.3 ends past the last line 11, the profile may be stale\n",
+		"testdata/missing.go: missing from the files of package github.com/franchb/gocover-cobertura/testdata\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("missing %q in:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "func4.go") {
+		t.Errorf("problem reported for func4.go:\n%s", out.String())
+	}
+}
+
+func TestCheckNoProblem(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	// func1.go and func2.go are stale in testdata_set.txt
+	var out bytes.Buffer
+	ignore := &Ignore{Files: regexp.MustCompile(`func[12]\.go$`)}
+	if err := Check(in, &out, ignore, "testdata"); err != nil {
+		t.Fatalf("%v:\n%s", err, out.String())
+	}
+	if expected := "no problem found in 3 file(s)\n"; out.String() != expected {
+		t.Errorf("output %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestStaleBlock(t *testing.T) {
+	t.Parallel()
+
+	lines := bytes.Split([]byte("package p\n\nfunc f() {\n}"), []byte("\n"))
+	for _, test := range []struct {
+		block    ProfileBlock
+		expected string
+	}{
+		{ProfileBlock{StartLine: 3, StartCol: 10, EndLine: 4, EndCol: 2}, ""},
+		{ProfileBlock{StartLine: 3, StartCol: 11, EndLine: 4, EndCol: 2}, ""},
+		{ProfileBlock{StartLine: 3, StartCol: 12, EndLine: 4, EndCol: 2}, "starts past the end of line 3"},
+		{ProfileBlock{StartLine: 3, StartCol: 10, EndLine: 4, EndCol: 3}, "ends past the end of line 4"},
+		{ProfileBlock{StartLine: 3, StartCol: 10, EndLine: 9, EndCol: 2}, "ends past the last line 4"},
+		{ProfileBlock{StartLine: 4, StartCol: 1, EndLine: 3, EndCol: 2}, "has a bad line range"},
+	} {
+		if actual := staleBlock(test.block, lines); actual != test.expected {
+			t.Errorf("staleBlock(%+v) = %q, expected %q", test.block, actual, test.expected)
+		}
+	}
+}
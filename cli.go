@@ -0,0 +1,663 @@
+package cobertura
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// inputFormats are the coverage formats readable with -input-format.
+var inputFormats = map[string]func(context.Context, io.Reader, Matcher, *Options, []string) (*Coverage, error){
+	"go":        convert,
+	"lcov":      convertLCOV,
+	"cobertura": convertCobertura,
+	"jacoco":    convertJaCoCo,
+}
+
+// Run runs the gocover-cobertura command with the flags of the command line
+// and of the environment.  Its error carries the exit code of the failure,
+// given by ExitCode.
+func Run() error {
+	return RunArgs(os.Args[1:])
+}
+
+// RunArgs is like Run, with the flags and arguments of args instead of the
+// ones of the command line.  The flags are parsed apart from the flag
+// package ones, so it may be called several times.
+func RunArgs(args []string) error {
+	var ignore Ignore
+	var opts Options
+	flags := flag.NewFlagSet("gocover-cobertura", flag.ContinueOnError)
+
+	flags.BoolVar(&opts.ByFiles, "by-files", false, "code coverage by file, not class")
+	flags.StringVar(&opts.GenericReceivers, "generic-receivers", genericReceiversCanonical,
+		"class names of generic receivers: canonical (Cache[K,V]) or strip (Cache)")
+	flags.StringVar(&opts.ClassNaming, "class-naming", classNamingReceiver,
+		"class names: receiver, file, file-basename, package+file or receiver+file")
+	flags.BoolVar(&opts.MethodSignatures, "method-signatures", false, "set the signature of methods to their parameter and result types")
+	flags.BoolVar(&opts.QualifiedMethods, "qualified-method-names", false, "name methods Type.Method, qualified by their receiver type")
+	flags.StringVar(&opts.PackageNaming, "package-naming", packageNamingImportPath,
+		"name packages by their import-path, or by the directory of their class filenames")
+	flags.BoolVar(&opts.InitClass, "init-class", false, "put the init functions in a class named <init> rather than with the other functions")
+	flags.BoolVar(&opts.Statements, "statements", false, "count statements instead of lines in the line rates and totals, as go tool cover -func")
+	flags.BoolVar(&opts.ModuleFilenames, "module-filenames", false, "prefix class filenames with the module path")
+	absoluteFilenames := flags.Bool("absolute-filenames", false, "use absolute paths as class filenames, as '-filename-style absolute'")
+	flags.StringVar(&opts.FilenameStyle, "filename-style", filenameStyleModule,
+		"class filenames and sources: module-relative, repo-relative or absolute")
+	flags.BoolVar(&opts.Devendor, "devendor", false, "report vendored files under their upstream import paths")
+	flags.BoolVar(&opts.TrimModulePrefix, "trim-module-prefix", false, "strip the module path from package names and class filenames")
+	failUnder := flags.Float64("fail-under", 0, "fail if the total line coverage percentage is below this threshold")
+	baselineFile := flags.String("baseline", "", "fail if the line coverage of the total or a package dropped from this Cobertura or JaCoCo report")
+	baselineTolerance := flags.Float64("baseline-tolerance", 0, "percentage points the coverage may drop from '-baseline' without failing")
+	deltaFile := flags.String("delta", "", "also write the coverage changes from '-baseline' per package and file to this file")
+	deltaFormat := flags.String("delta-format", "markdown", "format of '-delta': markdown or json")
+	thresholdsFile := flags.String("thresholds", "", "fail if packages are below the minimum coverage of the \"pattern: percent\" rules of this file")
+	flags.BoolVar(&opts.FailOnEmpty, "fail-on-empty", false, "fail if the profile has no blocks or every entry was ignored")
+	flags.BoolVar(&ignore.GeneratedFiles, "ignore-gen-files", false, "ignore generated files")
+	flags.Var((*regexpList)(&ignore.GeneratedMarkers), "gen-marker",
+		"with -ignore-gen-files, also ignore files whose head matches this regexp (repeatable)")
+	flags.Var((*globList)(&ignore.GeneratedNames), "gen-file-pattern",
+		"with -ignore-gen-files, also ignore files whose name matches this pattern, as *.pb.go (repeatable)")
+	flags.BoolVar(&ignore.TestFiles, "ignore-test-files", false, "ignore _test.go files")
+	flags.Var((*presetList)(&ignore.Names), "ignore-preset",
+		"ignore the files of these code generators, whatever their content: "+strings.Join(presetNames(), ", ")+"; may be repeated or comma separated")
+	flags.BoolVar(&ignore.Vendor, "ignore-vendor", false, "ignore files under vendor directories")
+	flags.BoolVar(&ignore.Deps, "ignore-deps", false, "ignore the packages of dependencies and of the standard library, profiled with -coverpkg=all")
+	ignoreFuncsRe := flags.String("ignore-funcs", "", "ignore functions whose name, or Type.Method for methods, matches this regexp")
+	ignoreDirsRe := flags.String("ignore-dirs", "", "ignore dirs matching this regexp")
+	ignoreFilesRe := flags.String("ignore-files", "", "ignore files matching this regexp")
+	matchDirsRe := flags.String("match-dirs", "", "only report the dirs matching this regexp")
+	matchFilesRe := flags.String("match-files", "", "only report the files matching this regexp")
+	var fromFiles stringList
+	flags.Var(&fromFiles, "from", "load coverage from file, for example coverage.out; may be repeated or comma separated to merge profiles")
+	inputFormat := flags.String("input-format", "auto", "input format: auto, go, lcov, cobertura or jacoco")
+	httpTimeout := flags.Duration("http-timeout", time.Minute, "timeout of the downloads of '-from' and '-merge' URLs")
+	var httpHeaders headerList
+	flags.Var(&httpHeaders, "http-header", "add this \"Name: value\" header, with $VAR expanded, to the downloads but not to redirects to other hosts; may be repeated")
+	var mergeFiles stringList
+	flags.Var(&mergeFiles, "merge", "merge this Cobertura or JaCoCo report into the converted coverage; may be repeated or comma separated")
+	fromCovDir := flags.String("from-covdir", "", "load binary coverage from a GOCOVERDIR directory")
+	toFile := flags.String("to", "", "write XML result to file")
+	strict := flags.Bool("strict", false, "fail, listing all of them, if any profile source is missing or unparsable, before converting")
+	fileCache := flags.String("file-cache", "", "cache the conversion of the files in this directory, to reuse it for the unchanged files")
+	packageCache := flags.String("package-cache", "", "cache the packages loaded for the profiles in this directory, to reuse them across conversions")
+	parallel := flags.Int("parallel", runtime.GOMAXPROCS(0), "number of files parsed concurrently")
+	keepGoing := flags.Bool("keep-going", false, "skip the profile entries whose source is missing or unparsable with a warning instead of failing")
+	partial := flags.Bool("partial", false, "still write the report of the other profile entries when some cannot be converted, before failing")
+	checkOnly := flags.Bool("check-only", false, "only check that the profile, its packages and sources can be converted, without writing a report")
+	timestampUnit := flags.String("timestamp-unit", timestampUnitSeconds, "unit of the timestamp of the Cobertura report: seconds, or ms as the earlier versions")
+	timestamp := flags.String("timestamp", "", "time of the report, as Unix seconds or RFC 3339, instead of SOURCE_DATE_EPOCH or the current time")
+	deterministic := flags.Bool("deterministic", false, "sort the report for identical reports of identical coverage, with a zero timestamp unless '-timestamp' or SOURCE_DATE_EPOCH is set")
+	srcRoot := flags.String("src-root", "", "replace the <sources> of the report by this directory")
+	var pathMaps pathMapList
+	flags.Var(&pathMaps, "path-map", "rewrite the source paths and class filenames starting with from as starting with to, given as from=to; may be repeated")
+	gzipOutput := flags.Bool("gzip", false, "compress the report with gzip, the default when '-to' ends with .gz")
+	format := flags.String("format", "cobertura", "output format: "+strings.Join(Formatters(), ", "))
+	tags := flags.String("tags", "", "Go build tags")
+	codecovUpload := flags.Bool("codecov-upload", false, "upload the Cobertura report to Codecov, using the CODECOV_TOKEN environment variable")
+	validate := flags.Bool("validate", false, "check the report against the Cobertura DTD before writing it")
+	showUncovered := flags.Bool("show-uncovered", false, "also print the spans of lines without hits, grouped by package, to stderr")
+	markdownFile := flags.String("markdown", "", "also write a Markdown summary to this file")
+	htmlDir := flags.String("html-dir", "", "also write an HTML report to this directory")
+	splitDir := flags.String("split-by-package", "", "also write a Cobertura report per package and an index.json to this directory")
+	azureDevOpsDir := flags.String("azure-devops", "", "also write the Cobertura and HTML reports to this directory for Azure Pipelines")
+	postCmd := flags.String("post-cmd", "", "run this command, split on white spaces out of quotes, once the report is written, for example \"upload.sh {output}\"")
+	stream := flags.Bool("stream", false, "write the packages of the Cobertura report as they are converted, bounding memory to about a package")
+	maxProfileLineSize := flags.String("max-profile-line", "", "longest line of the profiles, 1M by default, for example 16M")
+	maxMemory := flags.String("max-memory", "", "spill line data to disk beyond this size, for example 512M")
+
+	printVersion := flags.Bool("version", false, "print the version and exit")
+	verbose := flags.Bool("v", false, "log the loaded packages, the ignored files and the time spent per phase")
+	quiet := flags.Bool("q", false, "log errors only")
+	diagnostics := flags.String("diagnostics", diagnosticsText, "format of the warnings and errors: text, or json for one object per line with the file, reason and suggestion")
+	diagnosticsFile := flags.String("diagnostics-file", "", "write the warnings and errors to this file instead of stderr")
+	showProgress := flags.Bool("progress", false, "print the number of packages processed to stderr")
+	cpuProfile := flags.String("cpuprofile", "", "write a CPU profile of the conversion to this file")
+	memProfile := flags.String("memprofile", "", "write a heap profile to this file once converted")
+	traceFile := flags.String("trace", "", "write an execution trace of the conversion to this file")
+	showTimings := flags.Bool("timings", false, "print the time spent per phase to stderr")
+
+	flags.Usage = func() { printUsage(flags) }
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+	if err := setFlagsFromEnv(flags, os.LookupEnv); err != nil {
+		return withExitCode(exitUsage, err)
+	}
+
+	if *printVersion {
+		fmt.Println(version())
+		return nil
+	}
+
+	stopProfiling, profilingErr := startProfiling(*cpuProfile, *memProfile, *traceFile)
+	if profilingErr != nil {
+		return fmt.Errorf("could not start profiling: %w", profilingErr)
+	}
+	defer func() {
+		if err := stopProfiling(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "could not write profile: %v\n", err)
+		}
+	}()
+	var timings *phaseTimings
+	if *showTimings {
+		timings = newPhaseTimings()
+		defer func() { _ = timings.write(os.Stderr) }()
+	}
+
+	formatter, ok := LookupFormatter(*format)
+	if !ok {
+		return usageErrorf("unknown '-format' %q", *format)
+	}
+	if _, ok := inputFormats[*inputFormat]; !ok && *inputFormat != "auto" {
+		return usageErrorf("unknown '-input-format' %q", *inputFormat)
+	}
+	if *inputFormat != "go" && *inputFormat != "auto" && *fromCovDir != "" {
+		return usageErrorf("'-from-covdir' requires the go input format")
+	}
+	if *validate && *format != "cobertura" {
+		return usageErrorf("'-validate' requires the cobertura format")
+	}
+	if strings.Contains(*postCmd, "{output}") && *toFile == "" {
+		return usageErrorf("'-post-cmd' uses {output} but no '-to' file is given")
+	}
+	if _, err := splitCommand(*postCmd); err != nil {
+		return usageErrorf("bad '-post-cmd': %w", err)
+	}
+	if opts.GenericReceivers != genericReceiversCanonical && opts.GenericReceivers != genericReceiversStrip {
+		return usageErrorf("bad '-generic-receivers' style %q, expected canonical or strip", opts.GenericReceivers)
+	}
+	switch opts.ClassNaming {
+	case classNamingReceiver, classNamingFile, classNamingFileBasename, classNamingPackageFile, classNamingReceiverFile:
+	default:
+		return usageErrorf("unknown '-class-naming' strategy %q", opts.ClassNaming)
+	}
+	if *timestampUnit != timestampUnitSeconds && *timestampUnit != timestampUnitMillis {
+		return usageErrorf("bad '-timestamp-unit' %q, expected seconds or ms", *timestampUnit)
+	}
+	opts.TimestampMillis = *timestampUnit == timestampUnitMillis
+	if opts.PackageNaming != packageNamingImportPath && opts.PackageNaming != packageNamingDirectory {
+		return usageErrorf("bad '-package-naming' %q, expected import-path or directory", opts.PackageNaming)
+	}
+	if opts.ByFiles && opts.ClassNaming != classNamingReceiver {
+		return usageErrorf("'-by-files' and '-class-naming' are mutually exclusive")
+	}
+	switch opts.FilenameStyle {
+	case filenameStyleModule, filenameStyleRepo, filenameStyleAbsolute:
+	default:
+		return usageErrorf("unknown '-filename-style' %q", opts.FilenameStyle)
+	}
+	if *absoluteFilenames {
+		if opts.FilenameStyle == filenameStyleRepo {
+			return usageErrorf("'-absolute-filenames' and '-filename-style repo-relative' are mutually exclusive")
+		}
+		opts.FilenameStyle = filenameStyleAbsolute
+	}
+	if opts.ModuleFilenames && opts.FilenameStyle != filenameStyleModule {
+		return usageErrorf("'-module-filenames' requires '-filename-style module-relative'")
+	}
+	if *diagnostics != diagnosticsText && *diagnostics != diagnosticsJSON {
+		return usageErrorf("bad '-diagnostics' format %q, expected text or json", *diagnostics)
+	}
+	if *verbose && *quiet {
+		return usageErrorf("'-v' and '-q' are mutually exclusive")
+	}
+	if opts.ModuleFilenames && opts.TrimModulePrefix {
+		return usageErrorf("'-module-filenames' and '-trim-module-prefix' are mutually exclusive")
+	}
+	if opts.Devendor && opts.FilenameStyle != filenameStyleModule {
+		return usageErrorf("'-devendor' requires '-filename-style module-relative'")
+	}
+	if len(fromFiles) > 0 && *fromCovDir != "" {
+		return usageErrorf("'-from' and '-from-covdir' are mutually exclusive")
+	}
+	if *parallel < 1 {
+		return usageErrorf("bad '-parallel' value %d, expected at least 1", *parallel)
+	}
+	if opts.Statements && len(mergeFiles) > 0 {
+		return usageErrorf("'-statements' and '-merge' are mutually exclusive")
+	}
+	if *strict && *keepGoing {
+		return usageErrorf("'-strict' and '-keep-going' are mutually exclusive")
+	}
+	if *partial && (*strict || *keepGoing) {
+		return usageErrorf("'-partial' cannot be combined with '-strict' or '-keep-going'")
+	}
+	if *deltaFile != "" && *baselineFile == "" {
+		return usageErrorf("'-delta' requires '-baseline'")
+	}
+	if *deltaFormat != "markdown" && *deltaFormat != "json" {
+		return usageErrorf("unknown '-delta-format' %q", *deltaFormat)
+	}
+	if *stream {
+		if err := checkStreamFlags(flags, *format); err != nil {
+			return withExitCode(exitUsage, err)
+		}
+	}
+
+	var err error
+	var thresholds []PackageRule
+	if *thresholdsFile != "" {
+		if thresholds, err = readThresholds(*thresholdsFile); err != nil {
+			return usageErrorf("bad '-thresholds' file: %w", err)
+		}
+	}
+
+	var codecov *codecovUploader
+	if *codecovUpload {
+		if codecov, err = newCodecovUploader(); err != nil {
+			return usageErrorf("'-codecov-upload': %w", err)
+		}
+	}
+
+	if *maxProfileLineSize != "" {
+		size, err := parseMemorySize(*maxProfileLineSize)
+		if err != nil {
+			return usageErrorf("bad '-max-profile-line' value: %w", err)
+		}
+		if size < 1 || size > 1<<30 {
+			return usageErrorf("bad '-max-profile-line' value %q, expected between 1 and 1G", *maxProfileLineSize)
+		}
+		opts.MaxProfileLine = int(size)
+	}
+
+	if *ignoreDirsRe != "" {
+		ignore.Dirs, err = regexp.Compile(*ignoreDirsRe)
+		if err != nil {
+			return usageErrorf("bad '-ignore-dirs' regexp: %w", err)
+		}
+	}
+
+	if *ignoreFuncsRe != "" {
+		ignore.Funcs, err = regexp.Compile(*ignoreFuncsRe)
+		if err != nil {
+			return usageErrorf("bad '-ignore-funcs' regexp: %w", err)
+		}
+	}
+
+	if *matchDirsRe != "" {
+		ignore.MatchDirs, err = regexp.Compile(*matchDirsRe)
+		if err != nil {
+			return usageErrorf("bad '-match-dirs' regexp: %w", err)
+		}
+	}
+
+	if *matchFilesRe != "" {
+		ignore.MatchFiles, err = regexp.Compile(*matchFilesRe)
+		if err != nil {
+			return usageErrorf("bad '-match-files' regexp: %w", err)
+		}
+	}
+
+	if *ignoreFilesRe != "" {
+		ignore.Files, err = regexp.Compile(*ignoreFilesRe)
+		if err != nil {
+			return usageErrorf("bad '-ignore-files' regexp: %w", err)
+		}
+	}
+
+	inputs := []io.Reader{newGunzipReader(os.Stdin)}
+	to := os.Stdout
+
+	opener := newInputOpener(*httpTimeout, httpHeaders)
+	if fromFiles, err = expandGlobs(fromFiles); err != nil {
+		return usageErrorf("bad '-from': %w", err)
+	}
+	if mergeFiles, err = expandGlobs(mergeFiles); err != nil {
+		return usageErrorf("bad '-merge': %w", err)
+	}
+	if len(fromFiles) > 0 {
+		inputs = make([]io.Reader, 0, len(fromFiles))
+		for _, name := range fromFiles {
+			file, err := opener.open(name)
+			if err != nil {
+				return withExitCode(exitInput, fmt.Errorf("could not open file %s: %w", name, err))
+			}
+			defer file.Close()
+			inputs = append(inputs, newGunzipReader(file))
+		}
+	}
+
+	if *fromCovDir != "" {
+		profile, err := openCovDir(*fromCovDir)
+		if err != nil {
+			return withExitCode(exitInput, fmt.Errorf("could not read coverage directory %s: %w", *fromCovDir, err))
+		}
+		defer profile.Close()
+		inputs = []io.Reader{profile}
+	}
+
+	inFormat := *inputFormat
+	if inFormat == "auto" && (*fromCovDir != "" || flags.NArg() > 0) {
+		inFormat = "go"
+	} else if inFormat == "auto" {
+		buffered := bufio.NewReader(inputs[0])
+		inputs[0] = buffered
+		if inFormat, err = detectInputFormat(buffered); err != nil {
+			return withExitCode(exitInput, fmt.Errorf("could not detect the input format: %w", err))
+		}
+	}
+
+	from := inputs[0]
+	if len(inputs) > 1 && inFormat == "go" {
+		from = mergeProfileReaders(inputs, opts.maxProfileLine())
+	} else if len(inputs) > 1 {
+		// the other formats merge the records of the same file
+		readers := make([]io.Reader, 0, 2*len(inputs))
+		for _, input := range inputs {
+			readers = append(readers, input, strings.NewReader("\n"))
+		}
+		from = io.MultiReader(readers...)
+	}
+
+	var buildTags []string
+	if tags != nil && len(*tags) > 0 {
+		buildTags = strings.Split(strings.TrimSpace(*tags), ",")
+	}
+
+	if *checkOnly {
+		if inFormat != "go" {
+			return usageErrorf("'-check-only' requires the go input format")
+		}
+		return check(from, os.Stdout, &ignore, &opts, buildTags)
+	}
+
+	if toFile != nil && *toFile != "" {
+		to, err = os.Create(*toFile)
+		if err != nil {
+			return fmt.Errorf("could not open file %s: %w", *toFile, err)
+		}
+		defer to.Close()
+	}
+
+	var out io.Writer = to
+	var gz *gzip.Writer
+	if *gzipOutput || strings.HasSuffix(*toFile, ".gz") {
+		gz = gzip.NewWriter(to)
+		out = gz
+	}
+	closeOutput := func() error {
+		if gz != nil {
+			return gz.Close()
+		}
+		return nil
+	}
+
+	if flags.NArg() > 0 {
+		if inFormat != "go" {
+			return usageErrorf("commands require the go input format")
+		}
+		var stdin io.Reader = os.Stdin
+		if len(fromFiles) == 0 && *fromCovDir == "" {
+			stdin = nil // the profile is read from it
+		}
+		if err := runCommand(flags.Args(), from, stdin, out, &ignore, &opts, buildTags); err != nil {
+			return err
+		}
+		return closeOutput()
+	}
+
+	level := slog.LevelInfo
+	if *verbose {
+		level = slog.LevelDebug
+	} else if *quiet {
+		level = slog.LevelError
+	}
+	var diagOut io.Writer = os.Stderr
+	if *diagnosticsFile != "" {
+		file, err := os.Create(*diagnosticsFile)
+		if err != nil {
+			return fmt.Errorf("could not open file %s: %w", *diagnosticsFile, err)
+		}
+		defer file.Close()
+		diagOut = file
+	}
+	var handler slog.Handler = slog.NewTextHandler(diagOut, &slog.HandlerOptions{Level: level})
+	if *diagnostics == diagnosticsJSON {
+		handler = slog.NewJSONHandler(diagOut, &slog.HandlerOptions{Level: level})
+	}
+	opts.Logger = slog.New(handler)
+	opts.Strict, opts.KeepGoing, opts.Partial = *strict, *keepGoing, *partial
+	opts.Parallel = *parallel
+	opts.PackageCache, opts.FileCache = *packageCache, *fileCache
+	opts.timings = timings
+
+	if *showProgress {
+		opts.Progress = newProgressPrinter(os.Stderr, isTerminal(os.Stderr))
+	}
+
+	if *maxMemory != "" {
+		opts.MaxMemory, err = parseMemorySize(*maxMemory)
+		if err != nil {
+			return usageErrorf("bad '-max-memory' value: %w", err)
+		}
+	}
+
+	reportAt, hasReportTime, err := reportTime(*timestamp, os.LookupEnv)
+	if err != nil {
+		return withExitCode(exitUsage, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	convert := inputFormats[inFormat]
+	if *stream {
+		if inFormat != "go" {
+			return usageErrorf("'-stream' requires the go input format")
+		}
+		convert = convertStream
+	}
+	coverage, err := convert(ctx, from, &ignore, &opts, buildTags)
+	if err != nil && *diagnostics == diagnosticsJSON {
+		logFailure(opts.Logger, err)
+	}
+	if err != nil && coverage == nil {
+		return withExitCode(exitParse, fmt.Errorf("code coverage conversion failed: %w", err))
+	}
+	defer coverage.close()
+	var partialErr error
+	if err != nil {
+		// the report of the other files is written before failing
+		partialErr = withExitCode(exitParse, fmt.Errorf("code coverage conversion failed: %w", err))
+	}
+
+	for _, name := range mergeFiles {
+		if err = mergeReportFile(coverage, opener, name); err != nil {
+			return withExitCode(exitInput, fmt.Errorf("could not merge %s: %w", name, err))
+		}
+	}
+	coverage.remapPaths(*srcRoot, pathMaps)
+	if hasReportTime {
+		coverage.Timestamp = reportAt.UnixMilli()
+	} else if *deterministic {
+		coverage.Timestamp = 0
+	}
+	if *deterministic {
+		coverage.sortDeterministic()
+	}
+
+	var baseline *Coverage
+	if *baselineFile != "" {
+		if baseline, err = readReportFile(opener, *baselineFile); err != nil {
+			return withExitCode(exitInput, fmt.Errorf("could not read baseline %s: %w", *baselineFile, err))
+		}
+	}
+
+	start := time.Now()
+	if *validate {
+		var buf bytes.Buffer
+		if err = formatter.Write(&buf, coverage); err != nil {
+			return fmt.Errorf("code coverage conversion failed: %w", err)
+		}
+		if err = ValidateXML(bytes.NewReader(buf.Bytes())); err != nil {
+			return fmt.Errorf("invalid Cobertura report: %w", err)
+		}
+		if _, err = buf.WriteTo(out); err != nil {
+			return fmt.Errorf("code coverage conversion failed: %w", err)
+		}
+	} else if err = formatter.Write(out, coverage); err != nil {
+		return fmt.Errorf("code coverage conversion failed: %w", err)
+	}
+	if err = closeOutput(); err != nil {
+		return fmt.Errorf("code coverage conversion failed: %w", err)
+	}
+	opts.Logger.Debug("wrote report", "format", *format, "duration", time.Since(start))
+	timings.record("write report", time.Since(start))
+
+	if *markdownFile != "" {
+		if err = writeFile(*markdownFile, coverage.writeMarkdown); err != nil {
+			return fmt.Errorf("markdown summary failed: %w", err)
+		}
+	}
+
+	if *showUncovered {
+		if err = coverage.writeUncovered(os.Stderr); err != nil {
+			return fmt.Errorf("uncovered lines failed: %w", err)
+		}
+	}
+
+	if *deltaFile != "" {
+		delta := newCoverageDelta(coverage, baseline)
+		write := delta.writeMarkdown
+		if *deltaFormat == "json" {
+			write = delta.writeJSON
+		}
+		if err = writeFile(*deltaFile, write); err != nil {
+			return fmt.Errorf("delta report failed: %w", err)
+		}
+	}
+
+	if *htmlDir != "" {
+		if err = coverage.writeHTML(*htmlDir); err != nil {
+			return fmt.Errorf("HTML report failed: %w", err)
+		}
+	}
+
+	if *splitDir != "" {
+		if err = coverage.writeSplitByPackage(*splitDir); err != nil {
+			return fmt.Errorf("per package reports failed: %w", err)
+		}
+	}
+
+	if *azureDevOpsDir != "" {
+		var publish io.Writer
+		if os.Getenv("TF_BUILD") != "" {
+			publish = os.Stderr
+		}
+		if err = coverage.writeAzureDevOps(*azureDevOpsDir, publish); err != nil {
+			return fmt.Errorf("Azure DevOps report failed: %w", err)
+		}
+	}
+
+	if codecov != nil {
+		var buf bytes.Buffer
+		if err = coverage.writeXML(&buf); err != nil {
+			return fmt.Errorf("code coverage conversion failed: %w", err)
+		}
+		name := "coverage.xml"
+		if *toFile != "" {
+			name = strings.TrimSuffix(filepath.Base(*toFile), ".gz")
+		}
+		reportURL, err := codecov.upload(name, buf.Bytes(), codecovParams())
+		if err != nil {
+			return fmt.Errorf("codecov upload failed: %w", err)
+		}
+		opts.Logger.Info("uploaded to Codecov", "report", reportURL)
+	}
+
+	if *postCmd != "" {
+		if err = runPostCommand(*postCmd, *toFile, coverage); err != nil {
+			return fmt.Errorf("post command failed: %w", err)
+		}
+	}
+
+	if partialErr != nil {
+		return partialErr
+	}
+	rules := Rules{Min: *failUnder, Packages: thresholds, Baseline: baseline, Tolerance: *baselineTolerance}
+	return withExitCode(exitThreshold, checkRules(coverage, rules))
+}
+
+// writeFile creates the named file, with "-" meaning the standard output,
+// and writes to it.
+func writeFile(name string, write func(io.Writer) error) error {
+	if name == "-" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(name)
+	if err != nil {
+		return fmt.Errorf("could not open file %s: %w", name, err)
+	}
+	if err := write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// runCommand runs the command of args on the profile read from in.  stdin is
+// nil when the profile is read from the standard input.
+func runCommand(args []string, in, stdin io.Reader, out io.Writer, ignore Matcher, opts *Options, buildTags []string) error {
+	switch args[0] {
+	case "explain":
+		if len(args) != 2 {
+			return usageErrorf("usage: explain file.go:line")
+		}
+		if err := explain(in, out, ignore, args[1], opts, buildTags); err != nil {
+			return fmt.Errorf("explain failed: %w", err)
+		}
+		return nil
+	case "stats":
+		if err := stats(in, out, ignore, opts, buildTags); err != nil {
+			return fmt.Errorf("stats failed: %w", err)
+		}
+		return nil
+	case "suggest-ignores":
+		root := "."
+		if len(args) > 2 {
+			return usageErrorf("usage: suggest-ignores [dir]")
+		} else if len(args) == 2 {
+			root = args[1]
+		}
+		if err := SuggestIgnores(root, out); err != nil {
+			return fmt.Errorf("suggest-ignores failed: %w", err)
+		}
+		return nil
+	case "annotate-diff", "uncovered-diff":
+		if len(args) > 2 {
+			return usageErrorf("usage: %s [patch]", args[0])
+		}
+		patch := stdin
+		if len(args) == 2 && args[1] != "-" {
+			file, err := os.Open(args[1])
+			if err != nil {
+				return withExitCode(exitInput, fmt.Errorf("could not open file %s: %w", args[1], err))
+			}
+			defer file.Close()
+			patch = file
+		} else if patch == nil {
+			return usageErrorf("%s reads the diff from stdin, give the profile with '-from'", args[0])
+		}
+		if err := annotateDiff(in, patch, out, ignore, args[0] == "uncovered-diff", opts, buildTags); err != nil {
+			return fmt.Errorf("%s failed: %w", args[0], err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
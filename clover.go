@@ -0,0 +1,110 @@
+package cobertura
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+)
+
+type cloverCoverage struct {
+	XMLName   xml.Name      `xml:"coverage"`
+	Generated int64         `xml:"generated,attr"`
+	Clover    string        `xml:"clover,attr"`
+	Project   cloverProject `xml:"project"`
+}
+
+type cloverProject struct {
+	Timestamp int64            `xml:"timestamp,attr"`
+	Metrics   cloverMetrics    `xml:"metrics"`
+	Packages  []*cloverPackage `xml:"package"`
+}
+
+type cloverPackage struct {
+	Name    string        `xml:"name,attr"`
+	Metrics cloverMetrics `xml:"metrics"`
+	Files   []*cloverFile `xml:"file"`
+}
+
+type cloverFile struct {
+	Name    string        `xml:"name,attr"`
+	Path    string        `xml:"path,attr"`
+	Metrics cloverMetrics `xml:"metrics"`
+	Lines   []*cloverLine `xml:"line"`
+}
+
+type cloverLine struct {
+	Num   int    `xml:"num,attr"`
+	Count int64  `xml:"count,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type cloverMetrics struct {
+	Packages            int64 `xml:"packages,attr,omitempty"`
+	Files               int64 `xml:"files,attr,omitempty"`
+	Statements          int64 `xml:"statements,attr"`
+	CoveredStatements   int64 `xml:"coveredstatements,attr"`
+	Conditionals        int64 `xml:"conditionals,attr"`
+	CoveredConditionals int64 `xml:"coveredconditionals,attr"`
+	Methods             int64 `xml:"methods,attr"`
+	CoveredMethods      int64 `xml:"coveredmethods,attr"`
+	Elements            int64 `xml:"elements,attr"`
+	CoveredElements     int64 `xml:"coveredelements,attr"`
+}
+
+func (m *cloverMetrics) add(other cloverMetrics) {
+	m.Statements += other.Statements
+	m.CoveredStatements += other.CoveredStatements
+	m.Methods += other.Methods
+	m.CoveredMethods += other.CoveredMethods
+	m.Elements += other.Elements
+	m.CoveredElements += other.CoveredElements
+}
+
+// writeClover writes the coverage as a Clover XML document, with every line
+// counted as a statement.
+func (cov *Coverage) writeClover(out io.Writer) error {
+	doc := cloverCoverage{
+		Generated: cov.Timestamp,
+		Clover:    "4.4.1",
+		Project:   cloverProject{Timestamp: cov.Timestamp},
+	}
+
+	files, err := cov.files()
+	if err != nil {
+		return err
+	}
+
+	var cloverPkg *cloverPackage
+	for _, file := range files {
+		if cloverPkg == nil || cloverPkg.Name != file.Package {
+			cloverPkg = &cloverPackage{Name: file.Package}
+			doc.Project.Packages = append(doc.Project.Packages, cloverPkg)
+		}
+
+		cloverFile := &cloverFile{Name: path.Base(file.Filename), Path: file.Filename}
+		for _, method := range file.Methods {
+			cloverFile.Metrics.Methods++
+			if method.NumLinesWithHits() > 0 {
+				cloverFile.Metrics.CoveredMethods++
+			}
+		}
+		for _, line := range file.Lines {
+			cloverFile.Lines = append(cloverFile.Lines, &cloverLine{Num: line.Number, Count: line.Hits, Type: "stmt"})
+			cloverFile.Metrics.Statements++
+			if line.Hits > 0 {
+				cloverFile.Metrics.CoveredStatements++
+			}
+		}
+		cloverFile.Metrics.Elements = cloverFile.Metrics.Statements + cloverFile.Metrics.Methods
+		cloverFile.Metrics.CoveredElements = cloverFile.Metrics.CoveredStatements + cloverFile.Metrics.CoveredMethods
+
+		cloverPkg.Files = append(cloverPkg.Files, cloverFile)
+		cloverPkg.Metrics.add(cloverFile.Metrics)
+		cloverPkg.Metrics.Files++
+		doc.Project.Metrics.add(cloverFile.Metrics)
+		doc.Project.Metrics.Files++
+	}
+	doc.Project.Metrics.Packages = int64(len(doc.Project.Packages))
+
+	return encodeXML(out, doc, "")
+}
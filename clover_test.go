@@ -0,0 +1,60 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os"
+	"testing"
+)
+
+func TestWriteClover(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	coverage, err := convert(context.Background(), in, &Ignore{GeneratedFiles: true}, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	var out bytes.Buffer
+	if err := coverage.writeClover(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc cloverCoverage
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Project.Packages) != 1 {
+		t.Fatalf("expected 1 package, got %d", len(doc.Project.Packages))
+	}
+	metrics := doc.Project.Metrics
+	if metrics.Statements != coverage.LinesValid || metrics.CoveredStatements != coverage.LinesCovered {
+		t.Errorf("project statements %d/%d do not match lines %d/%d",
+			metrics.CoveredStatements, metrics.Statements, coverage.LinesCovered, coverage.LinesValid)
+	}
+
+	var func2 *cloverFile
+	for _, file := range doc.Project.Packages[0].Files {
+		if file.Path == "testdata/func2.go" {
+			func2 = file
+		}
+	}
+	if func2 == nil {
+		t.Fatal("testdata/func2.go not found")
+	}
+	if func2.Name != "func2.go" || func2.Metrics.Methods != 3 || func2.Metrics.CoveredMethods != 1 {
+		t.Errorf("unexpected func2.go file: %+v", func2)
+	}
+	if len(func2.Lines) != 8 || func2.Lines[0].Num != 8 || func2.Lines[0].Count != 1 {
+		t.Errorf("unexpected func2.go lines: %+v", func2.Lines)
+	}
+}
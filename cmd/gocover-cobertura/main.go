@@ -0,0 +1,16 @@
+// Command gocover-cobertura converts the coverage profiles of go test to
+// Cobertura XML reports.  See the README for its flags.
+package main
+
+import (
+	"os"
+
+	cobertura "github.com/franchb/gocover-cobertura"
+)
+
+func main() {
+	if err := cobertura.Run(); err != nil {
+		_, _ = os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(cobertura.ExitCode(err))
+	}
+}
@@ -1,9 +1,13 @@
-package main
+package cobertura
 
 import (
 	"encoding/xml"
+	"io/fs"
 )
 
+// Coverage is a Cobertura report, the <coverage> root element.  Its
+// Timestamp is in milliseconds since the Unix epoch, and is written in
+// seconds in the XML document unless Options.TimestampMillis is set.
 type Coverage struct {
 	XMLName         xml.Name   `xml:"coverage"`
 	LineRate        float32    `xml:"line-rate,attr"`
@@ -17,12 +21,28 @@ type Coverage struct {
 	Complexity      float32    `xml:"complexity,attr"`
 	Sources         []*Source  `xml:"sources>source"`
 	Packages        []*Package `xml:"packages>package"`
+
+	spillStore    *spillStore
+	linesInMemory int64
+	sourceFiles   map[string]sourceFile // by class filename
+	stream        *packageStream        // written packages, when streaming
+
+	timestampMillis bool // writes the timestamp in milliseconds
+}
+
+// sourceFile locates the source and profile of a class filename.
+type sourceFile struct {
+	path    string
+	fsys    fs.FS // nil for the host file system
+	profile *Profile
 }
 
+// Source is a directory the class filenames are relative to.
 type Source struct {
 	Path string `xml:",chardata"`
 }
 
+// Package is the coverage of a Go package.
 type Package struct {
 	Name       string   `xml:"name,attr"`
 	LineRate   float32  `xml:"line-rate,attr"`
@@ -31,6 +51,8 @@ type Package struct {
 	Classes    []*Class `xml:"classes>class"`
 }
 
+// Class is the coverage of the methods of a receiver type, or of a file
+// with -by-files.  Its lines are the ones of its methods.
 type Class struct {
 	Name       string    `xml:"name,attr"`
 	Filename   string    `xml:"filename,attr"`
@@ -39,8 +61,11 @@ type Class struct {
 	Complexity float32   `xml:"complexity,attr"`
 	Methods    []*Method `xml:"methods>method"`
 	Lines      Lines     `xml:"lines>line"`
+
+	spilled *spilledLines
 }
 
+// Method is the coverage of a function or method.
 type Method struct {
 	Name       string  `xml:"name,attr"`
 	Signature  string  `xml:"signature,attr"`
@@ -48,11 +73,28 @@ type Method struct {
 	BranchRate float32 `xml:"branch-rate,attr"`
 	Complexity float32 `xml:"complexity,attr"`
 	Lines      Lines   `xml:"lines>line"`
+
+	line       int // of the declaration
+	spilled    *spilledLines
+	statements *statementCount // with -statements
 }
 
+// statementCount is the number of statements of a function, as counted by
+// the profile blocks, and how many of them were run.
+type statementCount struct {
+	covered, valid int64
+}
+
+// Line is the hit count of a source line holding statements.  Branch and
+// ConditionCoverage are set by SetBranches from the branch outcomes of the
+// line, and read back from them when decoding a report.
 type Line struct {
-	Number int   `xml:"number,attr"`
-	Hits   int64 `xml:"hits,attr"`
+	Number            int    `xml:"number,attr"`
+	Hits              int64  `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr,omitempty"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+
+	branchesCovered, branchesValid int
 }
 
 // Lines is a slice of Line pointers, with some convenience methods.
@@ -98,19 +140,48 @@ func (lines *Lines) AddOrUpdateLine(lineNumber int, hits int64) {
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits.
 func (method Method) HitRate() float32 {
-	return method.Lines.HitRate()
+	return float32(method.NumLinesWithHits()) / float32(method.NumLines())
 }
 
-// NumLines returns the number of lines.
+// NumLines returns the number of lines, or of statements with -statements.
 func (method Method) NumLines() int64 {
+	if method.statements != nil {
+		return method.statements.valid
+	}
+	if method.spilled != nil {
+		return method.spilled.numLines
+	}
 	return method.Lines.NumLines()
 }
 
-// NumLinesWithHits returns the number of lines with a hit count > 0.
+// NumLinesWithHits returns the number of lines with a hit count > 0, or of
+// statements run with -statements.
 func (method Method) NumLinesWithHits() int64 {
+	if method.statements != nil {
+		return method.statements.covered
+	}
+	if method.spilled != nil {
+		return method.spilled.numLinesWithHits
+	}
 	return method.Lines.NumLinesWithHits()
 }
 
+// NumBranches returns the number of branch outcomes.
+func (method Method) NumBranches() int64 {
+	if method.spilled != nil {
+		return method.spilled.numBranches
+	}
+	return method.Lines.NumBranches()
+}
+
+// NumBranchesCovered returns the number of branch outcomes which were taken.
+func (method Method) NumBranchesCovered() int64 {
+	if method.spilled != nil {
+		return method.spilled.numBranchesCovered
+	}
+	return method.Lines.NumBranchesCovered()
+}
+
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits.
 func (class Class) HitRate() float32 {
@@ -134,6 +205,22 @@ func (class Class) NumLinesWithHits() (numLinesWithHits int64) {
 	return numLinesWithHits
 }
 
+// NumBranches returns the number of branch outcomes.
+func (class Class) NumBranches() (numBranches int64) {
+	for _, method := range class.Methods {
+		numBranches += method.NumBranches()
+	}
+	return numBranches
+}
+
+// NumBranchesCovered returns the number of branch outcomes which were taken.
+func (class Class) NumBranchesCovered() (numBranchesCovered int64) {
+	for _, method := range class.Methods {
+		numBranchesCovered += method.NumBranchesCovered()
+	}
+	return numBranchesCovered
+}
+
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits.
 func (pkg Package) HitRate() float32 {
@@ -156,6 +243,22 @@ func (pkg Package) NumLinesWithHits() (numLinesWithHits int64) {
 	return numLinesWithHits
 }
 
+// NumBranches returns the number of branch outcomes.
+func (pkg Package) NumBranches() (numBranches int64) {
+	for _, class := range pkg.Classes {
+		numBranches += class.NumBranches()
+	}
+	return numBranches
+}
+
+// NumBranchesCovered returns the number of branch outcomes which were taken.
+func (pkg Package) NumBranchesCovered() (numBranchesCovered int64) {
+	for _, class := range pkg.Classes {
+		numBranchesCovered += class.NumBranchesCovered()
+	}
+	return numBranchesCovered
+}
+
 // HitRate returns a float32 from 0.0 to 1.0 representing what fraction of lines
 // have hits.
 func (cov Coverage) HitRate() float32 {
@@ -177,3 +280,19 @@ func (cov Coverage) NumLinesWithHits() (numLinesWithHits int64) {
 	}
 	return numLinesWithHits
 }
+
+// NumBranches returns the number of branch outcomes.
+func (cov Coverage) NumBranches() (numBranches int64) {
+	for _, pkg := range cov.Packages {
+		numBranches += pkg.NumBranches()
+	}
+	return numBranches
+}
+
+// NumBranchesCovered returns the number of branch outcomes which were taken.
+func (cov Coverage) NumBranchesCovered() (numBranchesCovered int64) {
+	for _, pkg := range cov.Packages {
+		numBranchesCovered += pkg.NumBranchesCovered()
+	}
+	return numBranchesCovered
+}
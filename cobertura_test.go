@@ -1,4 +1,4 @@
-package main_test
+package cobertura_test
 
 import (
 	"encoding/xml"
@@ -21,7 +21,7 @@ func Test_Main(t *testing.T) {
 	temp, err := os.Create(fname)
 	assert.NoError(t, err)
 	os.Stdout = temp
-	assert.NoError(t, cobertura.Run())
+	assert.NoError(t, cobertura.RunArgs(nil))
 	outputBytes, err := os.ReadFile(fname)
 	assert.NoError(t, err)
 
@@ -0,0 +1,182 @@
+package cobertura
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	codecovDefaultURL = "https://codecov.io"
+	codecovAttempts   = 3
+
+	defaultCodecovRetryDelay = 2 * time.Second
+)
+
+// codecovRetryDelay is the delay before the first retry, doubled on each
+// following retry.
+var codecovRetryDelay = defaultCodecovRetryDelay
+
+// errCodecovPermanent marks upload failures which retrying cannot fix.
+var errCodecovPermanent = errors.New("permanent failure")
+
+// codecovUploader uploads reports through the Codecov v4 upload API.
+type codecovUploader struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+func newCodecovUploader() (*codecovUploader, error) {
+	token := os.Getenv("CODECOV_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("CODECOV_TOKEN is not set")
+	}
+	baseURL := os.Getenv("CODECOV_URL")
+	if baseURL == "" {
+		baseURL = codecovDefaultURL
+	}
+	return &codecovUploader{
+		client:  &http.Client{Timeout: time.Minute},
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+	}, nil
+}
+
+// codecovParams returns the upload parameters describing the build, taken
+// from the CI environment.
+func codecovParams() url.Values {
+	params := url.Values{}
+	params.Set("package", "gocover-cobertura")
+	params.Set("commit", currentCommit())
+	for param, names := range map[string][]string{
+		"branch": {"CODECOV_BRANCH", "GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "GIT_BRANCH"},
+		"slug":   {"CODECOV_SLUG", "GITHUB_REPOSITORY", "CI_PROJECT_PATH"},
+		"build":  {"CODECOV_BUILD", "GITHUB_RUN_ID", "CI_JOB_ID", "BUILD_NUMBER"},
+		"flags":  {"CODECOV_FLAGS"},
+	} {
+		for _, name := range names {
+			if value := os.Getenv(name); value != "" {
+				params.Set(param, value)
+				break
+			}
+		}
+	}
+	return params
+}
+
+// upload sends the named report, returning the URL of the Codecov report.
+func (u *codecovUploader) upload(name string, report []byte, params url.Values) (string, error) {
+	var body bytes.Buffer
+	_, _ = fmt.Fprintf(&body, "# path=%s\n", name)
+	body.Write(report)
+	body.WriteString("\n<<<<<< EOF\n")
+
+	var reportURL, uploadURL string
+	err := u.retry(func() error {
+		var err error
+		reportURL, uploadURL, err = u.requestUpload(params)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = u.retry(func() error {
+		return u.put(uploadURL, body.Bytes())
+	})
+	if err != nil {
+		return "", err
+	}
+	return reportURL, nil
+}
+
+// requestUpload asks Codecov where to upload the report.
+func (u *codecovUploader) requestUpload(params url.Values) (string, string, error) {
+	req, err := http.NewRequest(http.MethodPost, u.baseURL+"/upload/v4?"+params.Encode(), nil)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", errCodecovPermanent, err)
+	}
+	// the token is sent in a header, as the URLs end up in error messages
+	req.Header.Set("Authorization", "token "+u.token)
+	req.Header.Set("Accept", "text/plain")
+
+	body, err := u.do(req)
+	if err != nil {
+		return "", "", err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	var lines []string
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < 2 {
+		return "", "", fmt.Errorf("%w: unexpected Codecov response %q", errCodecovPermanent, body)
+	}
+	return lines[0], lines[1], nil
+}
+
+func (u *codecovUploader) put(uploadURL string, report []byte) error {
+	req, err := http.NewRequest(http.MethodPut, uploadURL, bytes.NewReader(report))
+	if err != nil {
+		return fmt.Errorf("%w: %w", errCodecovPermanent, err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	_, err = u.do(req)
+	return err
+}
+
+// do sends the request, returning the body of its response.  The errors
+// name the host of the request but not its URL, whose query may hold
+// credentials, as the signature of the upload URL.
+func (u *codecovUploader) do(req *http.Request) ([]byte, error) {
+	resp, err := u.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, fmt.Errorf("%s %s: %s", req.Method, req.URL.Host, resp.Status)
+	case resp.StatusCode >= http.StatusBadRequest:
+		return nil, fmt.Errorf("%w: %s %s: %s: %s",
+			errCodecovPermanent, req.Method, req.URL.Host, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// retry calls fn until it succeeds, fails permanently or runs out of attempts.
+func (u *codecovUploader) retry(fn func() error) error {
+	delay := codecovRetryDelay
+	var err error
+	for attempt := 1; attempt <= codecovAttempts; attempt++ {
+		if err = fn(); err == nil || errors.Is(err, errCodecovPermanent) {
+			return err
+		}
+		if attempt < codecovAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", codecovAttempts, err)
+}
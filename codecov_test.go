@@ -0,0 +1,70 @@
+package cobertura
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+//nolint:paralleltest // modifies the package level retry delay
+func TestCodecovUpload(t *testing.T) {
+	codecovRetryDelay = 0
+	t.Cleanup(func() { codecovRetryDelay = defaultCodecovRetryDelay })
+
+	var attempts atomic.Int32
+	var uploaded string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/upload/v4":
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			if r.Header.Get("Authorization") != "token secret" || r.URL.Query().Has("token") || r.URL.Query().Get("commit") != "abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = io.WriteString(w, "https://codecov.example/report\n"+server.URL+"/storage\n")
+		case r.Method == http.MethodPut && r.URL.Path == "/storage":
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	uploader := &codecovUploader{client: server.Client(), baseURL: server.URL, token: "secret"}
+	reportURL, err := uploader.upload("coverage.xml", []byte("<coverage/>"), url.Values{"commit": {"abc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reportURL != "https://codecov.example/report" {
+		t.Errorf("unexpected report URL %s", reportURL)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts.Load())
+	}
+	if uploaded != "# path=coverage.xml\n<coverage/>\n<<<<<< EOF\n" {
+		t.Errorf("unexpected upload %q", uploaded)
+	}
+
+	uploader.token = "wrong"
+	_, err = uploader.upload("coverage.xml", []byte("<coverage/>"), url.Values{"commit": {"abc"}})
+	if err == nil || !strings.Contains(err.Error(), "401 Unauthorized") {
+		t.Errorf("expected an authorization error, got %v", err)
+	}
+
+	// transport errors do not leak the URLs
+	server.Close()
+	uploader.token = "secret"
+	_, err = uploader.upload("coverage.xml", []byte("<coverage/>"), url.Values{"commit": {"abc"}, "slug": {"org/repo"}})
+	if err == nil || strings.Contains(err.Error(), "org") || !strings.Contains(err.Error(), "giving up") {
+		t.Errorf("expected a transport error without the URL, got %v", err)
+	}
+}
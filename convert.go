@@ -0,0 +1,910 @@
+package cobertura
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const DTDDecl = `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`
+
+// Namings of the packages of the report, for example/internal/foo.
+const (
+	packageNamingImportPath = "import-path" // example/internal/foo
+	packageNamingDirectory  = "directory"   // internal/foo, as the class filenames
+)
+
+// Styles of generic receiver names in class names.
+const (
+	genericReceiversCanonical = "canonical" // Cache[K,V]
+	genericReceiversStrip     = "strip"     // Cache
+)
+
+// Styles of class filenames, and of the sources they are relative to.
+const (
+	filenameStyleModule   = "module-relative" // relative to the module directories
+	filenameStyleRepo     = "repo-relative"   // relative to the repository roots
+	filenameStyleAbsolute = "absolute"        // absolute, with / as the source
+)
+
+// Strategies of class naming, for the method of a *Type receiver in
+// example/internal/foo/bar.go.
+const (
+	classNamingReceiver     = "receiver"      // Type
+	classNamingFile         = "file"          // internal/foo/bar.go
+	classNamingFileBasename = "file-basename" // bar.go
+	classNamingPackageFile  = "package+file"  // example/internal/foo/bar.go
+	classNamingReceiverFile = "receiver+file" // Type@bar.go
+)
+
+var errEmptyCoverage = errors.New("no coverage data: the profile is empty or every entry was ignored")
+
+// Convert converts the go test coverage profile read from in to a Cobertura
+// XML report written to out, loading the packages of the profile with the
+// build tags.
+func Convert(in io.Reader, out io.Writer, ignore Matcher, buildTags ...string) error {
+	return ConvertWithOptions(in, out, ignore, nil, buildTags...)
+}
+
+// ConvertWithOptions is like Convert, with additional settings given by opts,
+// which may be nil.
+func ConvertWithOptions(in io.Reader, out io.Writer, ignore Matcher, opts *Options, buildTags ...string) error {
+	return ConvertContext(context.Background(), in, out, ignore, opts, buildTags...)
+}
+
+// ConvertContext is like ConvertWithOptions, stopping with the error of ctx
+// once it is done, while the packages are loaded, as on a cold module cache,
+// or the profiles are parsed.
+func ConvertContext(ctx context.Context, in io.Reader, out io.Writer, ignore Matcher, opts *Options, buildTags ...string) error {
+	coverage, err := convert(ctx, in, ignore, opts, buildTags)
+	if coverage == nil {
+		return err
+	}
+	defer coverage.close()
+
+	if writeErr := coverage.writeXML(out); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// convert parses the profiles read from in and builds the coverage report,
+// returned along with the error of the failed files with Options.Partial.
+// The caller must close the returned coverage.
+func convert(ctx context.Context, in io.Reader, ignore Matcher, opts *Options, buildTags []string) (*Coverage, error) {
+	logger := opts.logger()
+
+	start := time.Now()
+	profiles, err := parseProfiles(ctx, in, ignore, opts)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, withExitCode(profileExitCode(err), err)
+	}
+	logger.Debug("parsed profiles", "files", len(profiles), "duration", time.Since(start))
+	opts.phaseTimings().record("parse profiles", time.Since(start))
+
+	coverage, err := convertProfiles(ctx, profiles, ignore, buildTags, opts)
+	if err != nil {
+		// a partial coverage, with Options.Partial
+		return coverage, err
+	}
+
+	if opts.failOnEmpty() && coverage.LinesValid == 0 {
+		coverage.close()
+		return nil, errEmptyCoverage
+	}
+	return coverage, nil
+}
+
+// writeXML writes the coverage as a Cobertura XML document.
+func (cov *Coverage) writeXML(out io.Writer) error {
+	if cov.stream != nil {
+		return cov.stream.writeXML(out, cov)
+	}
+	return encodeXML(out, cov, DTDDecl)
+}
+
+// encodeXML writes doc as an indented XML document, with an optional
+// doctype declaration.
+func encodeXML(out io.Writer, doc any, doctype string) error {
+	_, _ = fmt.Fprint(out, xml.Header)
+	if doctype != "" {
+		_, _ = fmt.Fprintln(out, doctype)
+	}
+
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(out)
+	return nil
+}
+
+// convertProfiles loads the packages referenced by profiles and builds the
+// coverage report from them.
+func convertProfiles(ctx context.Context, profiles []*Profile, ignore Matcher, buildTags []string, opts *Options) (*Coverage, error) {
+	return buildCoverage(ctx, profiles, ignore, buildTags, opts, nil)
+}
+
+// buildCoverage is convertProfiles, writing the packages to stream as they
+// are converted when it is not nil.
+func buildCoverage(ctx context.Context, profiles []*Profile, ignore Matcher, buildTags []string, opts *Options, stream *packageStream) (*Coverage, error) {
+	logger := opts.logger()
+	if err := opts.check(); err != nil {
+		return nil, withExitCode(exitUsage, err)
+	}
+
+	start := time.Now()
+	pkgs, err := getPackages(ctx, profiles, buildTags, opts)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, withExitCode(exitPackages, err)
+	}
+	logger.Debug("loaded packages", "profiles", len(profiles), "packages", len(pkgs), "duration", time.Since(start))
+	opts.phaseTimings().record("load packages", time.Since(start))
+
+	pkgMap := make(map[string]*packages.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, pkgErr := range pkg.Errors {
+			logger.Warn("package load error", "package", pkg.ID, "error", pkgErr.Error())
+		}
+		if pkg.Module == nil {
+			logger.Warn("package is not part of a module", "package", pkg.ID)
+			continue
+		}
+		logger.Debug("loaded package", "package", pkg.ID, "module", pkg.Module.Path)
+		pkgMap[pkg.ID] = pkg
+	}
+
+	if opts.strict() {
+		start = time.Now()
+		if err := checkSources(profiles, pkgMap, opts.sourceFS(), opts.parallel()); err != nil {
+			return nil, withExitCode(exitPackages, err)
+		}
+		opts.phaseTimings().record("check sources", time.Since(start))
+	}
+
+	start = time.Now()
+	coverage := &Coverage{Sources: []*Source{}, Packages: nil, Timestamp: time.Now().UnixNano() / int64(time.Millisecond), stream: stream}
+	coverage.timestampMillis = opts.timestampMillis()
+	if err := coverage.parseProfiles(ctx, profiles, pkgMap, ignore, opts); err != nil {
+		var filesErr *filesError
+		if opts.partial() && ctx.Err() == nil && errors.As(err, &filesErr) {
+			return coverage, withExitCode(exitPackages, err)
+		}
+		coverage.close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, withExitCode(exitPackages, err)
+	}
+	logger.Debug("built coverage", "packages", len(coverage.Packages), "lines", coverage.LinesValid, "duration", time.Since(start))
+	opts.phaseTimings().record("convert files", time.Since(start))
+	return coverage, nil
+}
+
+// close releases the resources held by the coverage, such as spill files.
+func (cov *Coverage) close() {
+	if cov.spillStore != nil {
+		_ = cov.spillStore.Close()
+		cov.spillStore = nil
+	}
+	if cov.stream != nil {
+		_ = cov.stream.Close()
+		cov.stream = nil
+	}
+}
+
+func getPackages(ctx context.Context, profiles []*Profile, buildTags []string, opts *Options) ([]*packages.Package, error) {
+	if len(profiles) == 0 {
+		return []*packages.Package{}, nil
+	}
+
+	pkgNames := make([]string, len(profiles))
+	for index := range profiles {
+		pkgNames[index] = getPackageName(profiles[index].FileName)
+	}
+	pkgs, err := loadPackages(ctx, pkgNames, buildTags, opts.packageCache(), opts.logger())
+	resolveModules(pkgs)
+	return pkgs, err
+}
+
+// listPackages lists the packages with go list.
+func listPackages(ctx context.Context, pkgNames, buildTags []string) ([]*packages.Package, error) {
+	// Without NeedCompiledGoFiles, go list neither runs cgo, which needs a C
+	// compiler, nor lists the cgo processed copies of the files: GoFiles
+	// holds the original cgo files, as named by the profiles.
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedFiles | packages.NeedModule,
+	}
+	if len(buildTags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(buildTags, ",")}
+	}
+	return packages.Load(cfg, pkgNames...)
+}
+
+func appendIfUnique(sources []*Source, dir string) []*Source {
+	for _, source := range sources {
+		if source.Path == dir {
+			return sources
+		}
+	}
+	return append(sources, &Source{dir})
+}
+
+// fileSource returns the directory which, joined with the class filename,
+// is the path of the file, so that viewers find the file under the
+// <source> of the report, "/" for absolute filenames, or "" if there is
+// none, as for the filenames prefixed with their module path.
+func fileSource(classFileName, absFilePath string) string {
+	absPath := filepath.ToSlash(absFilePath)
+	if classFileName == absPath {
+		return "/"
+	}
+	suffix := "/" + classFileName
+	if len(absPath) < len(suffix) || !strings.EqualFold(absPath[len(absPath)-len(suffix):], suffix) {
+		return ""
+	}
+	if dir := absPath[:len(absPath)-len(suffix)]; dir != "" {
+		return filepath.FromSlash(dir)
+	}
+	return "/"
+}
+
+func getPackageName(filename string) string {
+	// NOTE: Windows vs. Linux, the profiles of either may be converted on the other
+	index := strings.LastIndexAny(filename, "/\\")
+	if index < 0 {
+		return ""
+	}
+	return strings.TrimRight(filename[:index], "/\\")
+}
+
+// findAbsFilePath returns the path of the source of the profile file among
+// the files of the package, matching its name case-insensitively when it is
+// unambiguous.  The cgo files, importing "C", are looked up
+// among the ignored files too, read from fsys, as go list leaves them out
+// when cgo is disabled where the conversion runs, unlike where the tests ran.
+func findAbsFilePath(pkg *packages.Package, profileName string, fsys fs.FS) string {
+	filename := filepath.Base(cgoSourceName(strings.ReplaceAll(profileName, "\\", "/")))
+	for _, fullpath := range pkg.GoFiles {
+		if filepath.Base(fullpath) == filename {
+			return fullpath
+		}
+	}
+	// the case of the file names may differ on case-insensitive file systems
+	found := ""
+	for _, fullpath := range pkg.GoFiles {
+		if strings.EqualFold(filepath.Base(fullpath), filename) {
+			if found != "" {
+				return ""
+			}
+			found = fullpath
+		}
+	}
+	if found != "" {
+		return found
+	}
+	for _, fullpath := range pkg.IgnoredFiles {
+		if filepath.Base(fullpath) == filename && isCgoFile(fsys, fullpath) {
+			return fullpath
+		}
+	}
+	return ""
+}
+
+// cgoSourceName returns the name of the original source of a cgo processed
+// file, foo.go for foo.cgo1.go, as named by the profiles of some Go
+// versions, or fileName itself.
+func cgoSourceName(fileName string) string {
+	if base, ok := strings.CutSuffix(fileName, ".cgo1.go"); ok {
+		return base + ".go"
+	}
+	return fileName
+}
+
+// isCgoFile reports whether the Go source file of fsys imports "C".
+func isCgoFile(fsys fs.FS, path string) bool {
+	data, err := readSource(fsys, path)
+	if err != nil {
+		return false
+	}
+	parsed, err := parser.ParseFile(token.NewFileSet(), path, data, parser.ImportsOnly)
+	if err != nil {
+		return false
+	}
+	for _, spec := range parsed.Imports {
+		if spec.Path.Value == `"C"` {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupPackage returns the package with the given import path.  As import
+// paths may differ in case only (renamed organizations, Windows checkouts),
+// it falls back to a case-insensitive match when it is unambiguous.  The
+// profiles of packages out of modules name them by their absolute
+// directory, as C:\src\app, which is matched against the directory of the
+// package files.
+func lookupPackage(pkgMap map[string]*packages.Package, pkgName string) *packages.Package {
+	if pkg, ok := pkgMap[pkgName]; ok {
+		return pkg
+	}
+	var found *packages.Package
+	for id, pkg := range pkgMap {
+		if strings.EqualFold(id, pkgName) {
+			if found != nil {
+				return nil
+			}
+			found = pkg
+		}
+	}
+	if found != nil || !isAbsPath(pkgName) {
+		return found
+	}
+	for _, pkg := range pkgMap {
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+		if dir := getPackageName(pkg.GoFiles[0]); samePath(dir, pkgName) || samePath(realPath(dir), realPath(pkgName)) {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// trimModulePath returns fileName relative to modulePath, comparing the
+// module prefix case-insensitively.  The file name is returned unchanged
+// when it is not part of the module.
+func trimModulePath(fileName, modulePath string) string {
+	if len(fileName) <= len(modulePath) || fileName[len(modulePath)] != '/' {
+		return fileName
+	}
+	if !strings.EqualFold(fileName[:len(modulePath)], modulePath) {
+		return fileName
+	}
+	return fileName[len(modulePath)+1:]
+}
+
+// trimModulePrefixOf returns the import path relative to its module, or "."
+// for the root package of the module.
+func trimModulePrefixOf(importPath, modulePath string) string {
+	if strings.EqualFold(importPath, modulePath) {
+		return "."
+	}
+	return trimModulePath(importPath, modulePath)
+}
+
+// devendorPath returns the upstream import path of a path pointing into a
+// vendor directory, that is everything after the last vendor element.
+func devendorPath(p string) (string, bool) {
+	p = filepath.ToSlash(p)
+	if strings.HasPrefix(p, "vendor/") {
+		return p[len("vendor/"):], true
+	}
+	if index := strings.LastIndex(p, "/vendor/"); index >= 0 {
+		return p[index+len("/vendor/"):], true
+	}
+	return p, false
+}
+
+func (cov *Coverage) parseProfiles(ctx context.Context, profiles []*Profile, pkgMap map[string]*packages.Package, ignore Matcher, opts *Options) error {
+	logger := opts.logger()
+	maxMemory := opts.maxMemory()
+	progress := opts.progress()
+	keepGoing := opts.keepGoing()
+
+	// the profiles are sorted by file name, so the files of a package follow each other
+	total := 0
+	for index, profile := range profiles {
+		if index == 0 || getPackageName(profile.FileName) != getPackageName(profiles[index-1].FileName) {
+			total++
+		}
+	}
+
+	cache := newFileCache(opts.fileCache(), logger)
+	parsed := parseFiles(profiles, opts.parallel(), func(profile *Profile) (*parsedFile, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return parseFile(profile, lookupPackage(pkgMap, getPackageName(profile.FileName)), ignore, opts, cache)
+	})
+	defer parsed.stop()
+
+	cov.Packages = []*Package{}
+	var fileErrs []error
+	skipped := 0
+	done := 0
+	pkgStart := 0
+	for index, profile := range profiles {
+		pkgName := getPackageName(profile.FileName)
+		file, err := parsed.next()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if keepGoing {
+				reason, suggestion := diagnose(err)
+				logger.Warn("skipping file", "file", profile.FileName, "reason", reason, "suggestion", suggestion, "error", err)
+				skipped++
+			} else {
+				fileErrs = append(fileErrs, &fileError{file: profile.FileName, err: err})
+			}
+		}
+		if file != nil {
+			cov.addFile(file)
+		}
+		if index == len(profiles)-1 || getPackageName(profiles[index+1].FileName) != pkgName {
+			done++
+			progress(done, total)
+			if cov.stream != nil {
+				if err := cov.stream.flush(cov); err != nil {
+					return err
+				}
+				// the blocks of the profiles, parsed upfront to load their
+				// packages together, are not needed once written
+				for _, written := range profiles[pkgStart : index+1] {
+					written.Blocks = nil
+				}
+			}
+			pkgStart = index + 1
+		}
+
+		if maxMemory > 0 {
+			if err := cov.spillIfNeeded(profile, maxMemory, logger); err != nil {
+				return err
+			}
+		}
+	}
+	if skipped > 0 {
+		logger.Warn("skipped files", "count", skipped)
+	}
+	if cov.stream != nil {
+		cov.stream.setTotals(cov)
+	} else {
+		cov.LinesValid = cov.NumLines()
+		cov.LinesCovered = cov.NumLinesWithHits()
+		cov.LineRate = cov.HitRate()
+		cov.BranchesValid = cov.NumBranches()
+		cov.BranchesCovered = cov.NumBranchesCovered()
+		cov.BranchRate = branchRate(cov.BranchesCovered, cov.BranchesValid)
+	}
+	if len(fileErrs) == 1 {
+		return &filesError{fileErrs[0]}
+	}
+	if len(fileErrs) > 1 {
+		return &filesError{newListError(fmt.Sprintf("%d file(s) cannot be converted:", len(fileErrs)), "\n\t", fileErrs)}
+	}
+	return nil
+}
+
+// filesError is the error of the files of the profiles which cannot be
+// converted, once the other ones are.
+type filesError struct {
+	err error
+}
+
+func (e *filesError) Error() string {
+	return e.err.Error()
+}
+
+func (e *filesError) Unwrap() error {
+	return e.err
+}
+
+// ParseProfile adds the coverage of the source file of the profile, which
+// belongs to pkgPkg, to the coverage.
+func (cov *Coverage) ParseProfile(profile *Profile, pkgPkg *packages.Package, ignore Matcher) error {
+	file, err := parseFile(profile, pkgPkg, ignore, nil, nil)
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		cov.addFile(file)
+	}
+	return nil
+}
+
+// parsedFile is the coverage of a source file, built apart from the
+// coverage so that files can be parsed concurrently.
+type parsedFile struct {
+	pkgName       string
+	classFileName string
+	sourceDir     string
+	absFilePath   string
+	fsys          fs.FS
+	profile       *Profile
+	classes       []*Class
+}
+
+// parseFile parses the source of the profile and builds its classes, or
+// returns nil if the file is ignored.  The classes of unchanged files are
+// taken from the cache, if not nil.  It is safe for concurrent use.
+func parseFile(profile *Profile, pkgPkg *packages.Package, ignore Matcher, opts *Options, cache *fileCache) (*parsedFile, error) {
+	fsys, logger := opts.sourceFS(), opts.logger()
+	if pkgPkg == nil || pkgPkg.Module == nil {
+		return nil, &kindError{kind: ErrPackageNotFound, msg: "package required when using go modules"}
+	}
+	modFileName := trimModulePath(cgoSourceName(profile.FileName), pkgPkg.Module.Path)
+	if isDependency(pkgPkg) && ignoresDeps(ignore) {
+		logger.Debug("ignoring file", "file", modFileName, "reason", "-ignore-deps")
+		return nil, nil
+	}
+	absFilePath := findAbsFilePath(pkgPkg, profile.FileName, fsys)
+	if absFilePath == "" {
+		if err := excludedFileError(pkgPkg, profile.FileName); err != nil {
+			return nil, fmt.Errorf("file path error: %s , %s, %w", pkgPkg, profile.FileName, err)
+		}
+	}
+	fileName := moduleFileName(modFileName, absFilePath, pkgPkg.Module)
+	data, err := readSource(fsys, absFilePath)
+	if err != nil {
+		if absFilePath == "" || errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%w: %w", ErrSourceMissing, err)
+		}
+		return nil, fmt.Errorf("file path error: %s , %s, %w", pkgPkg, profile.FileName, err)
+	}
+	if matchFile(ignore, fileName, data) {
+		logger.Debug("ignoring file", "file", fileName, "reason", ignoreReason(ignore, fileName))
+		return nil, nil
+	}
+	if matchVendor(ignore, pkgPkg.ID+"/"+filepath.Base(fileName)) {
+		// vendored packages may be profiled under their upstream import
+		// paths, but imported from a vendor directory
+		logger.Debug("ignoring file", "file", fileName, "reason", "-ignore-vendor")
+		return nil, nil
+	}
+
+	classFileName := fileName
+	switch {
+	case opts.moduleFilenames() && pkgPkg.Module.Path != "":
+		// NOTE: module paths always use forward slashes, keep the filename consistent
+		classFileName = pkgPkg.Module.Path + "/" + strings.ReplaceAll(modFileName, "\\", "/")
+	case opts.filenameStyle() == filenameStyleAbsolute:
+		classFileName = filepath.ToSlash(absFilePath)
+	case opts.filenameStyle() == filenameStyleRepo:
+		classFileName = repoFileName(fileName, absFilePath, pkgPkg.Module)
+	}
+
+	pkgName := pkgPkg.ID
+	if opts.devendor() {
+		if _, vendored := devendorPath(filepath.Dir(fileName)); vendored {
+			classFileName = pkgPkg.Module.Path + "/" + filepath.ToSlash(fileName)
+		}
+		classFileName, _ = devendorPath(classFileName)
+		pkgName, _ = devendorPath(pkgName)
+	}
+
+	if opts.trimModulePrefix() {
+		pkgName = trimModulePrefixOf(pkgName, pkgPkg.Module.Path)
+		classFileName = trimModulePath(classFileName, pkgPkg.Module.Path)
+	}
+	// NOTE: class filenames use forward slashes, whatever the platform
+	classFileName = strings.ReplaceAll(classFileName, "\\", "/")
+	if opts.packageNaming() == packageNamingDirectory {
+		pkgName = path.Dir(classFileName)
+	}
+
+	sourceDir := fileSource(classFileName, absFilePath)
+	if sourceDir == "" {
+		logger.Debug("no source directory prefixes the class filename", "file", classFileName, "path", absFilePath)
+	}
+
+	file := &parsedFile{
+		pkgName:       pkgName,
+		classFileName: classFileName,
+		sourceDir:     sourceDir,
+		absFilePath:   absFilePath,
+		fsys:          fsys,
+		profile:       profile,
+	}
+	key, cacheable := fileCacheKey(profile, data, classFileName, pkgName, ignore, opts)
+	if cacheable {
+		if file.classes = cache.get(key); file.classes != nil {
+			logger.Debug("reusing cached file", "file", classFileName)
+			return file, nil
+		}
+	}
+
+	fset := token.NewFileSet()
+	// the visitors do not use the objects of identifiers, whose resolution
+	// takes a good part of the parsing
+	parsed, err := parser.ParseFile(fset, absFilePath, data, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("file path error: %s , %s, %w", pkgPkg, profile.FileName, err)
+	}
+	visitor := &fileVisitor{
+		fset:     fset,
+		fileName: classFileName,
+		classes:  make(map[string]*Class),
+		pkg:      &Package{Name: pkgName},
+		profile:  profile,
+		ignore:   ignore,
+		opts:     opts,
+		ignored:  ignoredRanges(fset, parsed, data),
+		inits:    numberInits(parsed),
+	}
+	ast.Walk(visitor, parsed)
+	visitor.setRates()
+	file.classes = visitor.pkg.Classes
+	if cacheable {
+		cache.put(key, file.classes)
+	}
+	return file, nil
+}
+
+// addFile adds the classes of the file to its package in the coverage.
+func (cov *Coverage) addFile(file *parsedFile) {
+	var pkg *Package
+
+	for index := range cov.Packages {
+		if cov.Packages[index].Name == file.pkgName {
+			pkg = cov.Packages[index]
+			break
+		}
+	}
+
+	if pkg == nil {
+		pkg = &Package{Name: file.pkgName, Classes: []*Class{}}
+		cov.Packages = append(cov.Packages, pkg)
+	}
+
+	if file.sourceDir != "" {
+		cov.Sources = appendIfUnique(cov.Sources, file.sourceDir)
+	}
+	if cov.sourceFiles == nil {
+		cov.sourceFiles = map[string]sourceFile{}
+	}
+	cov.sourceFiles[file.classFileName] = sourceFile{path: file.absFilePath, fsys: file.fsys, profile: file.profile}
+
+	pkg.Classes = append(pkg.Classes, file.classes...)
+	pkg.LineRate = pkg.HitRate()
+	pkg.BranchRate = branchRate(pkg.NumBranchesCovered(), pkg.NumBranches())
+}
+
+type fileVisitor struct {
+	fset     *token.FileSet
+	fileName string
+	pkg      *Package
+	classes  map[string]*Class
+	profile  *Profile
+	ignore   Matcher
+	opts     *Options
+	ignored  []lineRange           // by comment directives
+	inits    map[*ast.FuncDecl]int // numbers of the init functions, if several
+	blocks   *blockIndex           // of the profile, see blockIndex
+}
+
+func (v *fileVisitor) Visit(node ast.Node) ast.Visitor {
+	if n, ok := node.(*ast.FuncDecl); ok {
+		start, end := v.fset.Position(n.Pos()).Line, v.fset.Position(n.End()).Line
+		if matchFunc(v.ignore, v.funcName(n)) || contains(v.ignored, start, end) {
+			return v
+		}
+		method := v.method(n)
+		if len(method.Lines) == 0 && overlaps(v.ignored, start, end) {
+			// Every block is excluded by comment directives.
+			return v
+		}
+		class := v.class(n)
+		method.LineRate = method.HitRate()
+		method.BranchRate = branchRate(method.Lines.NumBranchesCovered(), method.Lines.NumBranches())
+		class.Methods = append(class.Methods, method)
+		class.Lines = append(class.Lines, method.Lines...)
+	}
+	return v
+}
+
+// setRates sets the rates of the classes once their methods are visited,
+// rather than after every method, which made files of many functions
+// quadratic.
+func (v *fileVisitor) setRates() {
+	for _, class := range v.pkg.Classes {
+		class.LineRate = class.HitRate()
+		class.BranchRate = branchRate(class.NumBranchesCovered(), class.NumBranches())
+	}
+}
+
+func (v *fileVisitor) method(n *ast.FuncDecl) *Method {
+	start := v.fset.Position(n.Pos())
+	method := &Method{Name: n.Name.Name, line: start.Line}
+	method.Lines = []*Line{}
+	if v.opts.statements() {
+		method.statements = &statementCount{}
+	}
+	if v.opts.methodSignatures() {
+		method.Signature = signature(n.Type)
+	}
+	if v.opts.qualifiedMethods() {
+		method.Name = v.funcName(n)
+	}
+	if number, ok := v.inits[n]; ok {
+		method.Name += "#" + strconv.Itoa(number)
+	}
+
+	end := v.fset.Position(n.End())
+	startLine := start.Line
+	startCol := start.Column
+	endLine := end.Line
+	endCol := end.Column
+
+	// The blocks are sorted, so we can stop counting as soon as we reach the end of the relevant block.
+	for _, block := range v.blockIndex().endingAfter(startLine, startCol) {
+		if block.StartLine > endLine || (block.StartLine == endLine && block.StartCol >= endCol) {
+			// Past the end of the function.
+			break
+		}
+
+		if block.EndLine < startLine || (block.EndLine == startLine && block.EndCol <= startCol) {
+			// Before the beginning of the function
+			continue
+		}
+
+		if overlaps(v.ignored, block.StartLine, block.EndLine) {
+			// Excluded by a comment directive.
+			continue
+		}
+
+		for i := block.StartLine; i <= block.EndLine; i++ {
+			method.Lines.AddOrUpdateLine(i, int64(block.Count))
+		}
+		if method.statements != nil {
+			method.statements.valid += int64(block.NumStmt)
+			if block.Count > 0 {
+				method.statements.covered += int64(block.NumStmt)
+			}
+		}
+	}
+
+	for number, count := range v.branches(n) {
+		for _, line := range method.Lines {
+			if line.Number == number {
+				line.SetBranches(count.covered, count.valid)
+				break
+			}
+		}
+	}
+	return method
+}
+
+func (v *fileVisitor) class(n *ast.FuncDecl) *Class {
+	var className string
+	if v.opts.byFiles() {
+		// className = filepath.Base(v.fileName)
+		//
+		// NOTE(boumenot): ReportGenerator creates links that collide if names are not distinct.
+		// This could be an issue in how I am generating the report, but I have not been able
+		// to figure it out.  The work around is to generate a fully qualified name based on
+		// the file path.
+		//
+		// src/lib/util/foo.go -> src.lib.util.foo.go
+		className = strings.ReplaceAll(v.fileName, "/", ".")
+		className = strings.ReplaceAll(className, "\\", ".")
+	} else {
+		className = v.className(n)
+	}
+	if v.opts.initClass() && isInit(n) {
+		className = "<init>"
+	}
+	class := v.classes[className]
+	if class == nil {
+		class = &Class{Name: className, Filename: v.fileName, Methods: []*Method{}, Lines: []*Line{}}
+		v.classes[className] = class
+		v.pkg.Classes = append(v.pkg.Classes, class)
+	}
+	return class
+}
+
+// funcName returns the name of the function, qualified by its receiver type
+// for methods, as Type.Method.
+func (v *fileVisitor) funcName(n *ast.FuncDecl) string {
+	if n.Recv == nil || len(n.Recv.List) == 0 {
+		return n.Name.Name
+	}
+	return receiverTypeName(n.Recv.List[0].Type, genericReceiversStrip) + "." + n.Name.Name
+}
+
+// className returns the name of the class of the function by the
+// -class-naming strategy.
+func (v *fileVisitor) className(n *ast.FuncDecl) string {
+	switch v.opts.classNaming() {
+	case classNamingFile:
+		return v.fileName
+	case classNamingFileBasename:
+		return path.Base(v.fileName)
+	case classNamingPackageFile:
+		return v.pkg.Name + "/" + path.Base(v.fileName)
+	case classNamingReceiverFile:
+		return v.recvName(n) + "@" + path.Base(v.fileName)
+	default:
+		return v.recvName(n)
+	}
+}
+
+// isInit reports whether the function is a package initialization function.
+func isInit(n *ast.FuncDecl) bool {
+	return n.Recv == nil && n.Name.Name == "init"
+}
+
+// numberInits returns the numbers, from 1 in declaration order, of the init
+// functions of the file if it has several of them, so that their methods
+// are told apart as init#1, init#2, and so on.
+func numberInits(file *ast.File) map[*ast.FuncDecl]int {
+	var inits []*ast.FuncDecl
+	for _, decl := range file.Decls {
+		if n, ok := decl.(*ast.FuncDecl); ok && isInit(n) {
+			inits = append(inits, n)
+		}
+	}
+	if len(inits) < 2 {
+		return nil
+	}
+	numbers := make(map[*ast.FuncDecl]int, len(inits))
+	for index, n := range inits {
+		numbers[n] = index + 1
+	}
+	return numbers
+}
+
+func (v *fileVisitor) recvName(n *ast.FuncDecl) string {
+	if n.Recv == nil || len(n.Recv.List) == 0 {
+		return "-"
+	}
+	return receiverTypeName(n.Recv.List[0].Type, v.opts.genericReceivers())
+}
+
+// receiverTypeName returns the name of the receiver type expr, without
+// pointer indirection.  The type parameters of generic receivers are
+// rendered according to style.
+func receiverTypeName(expr ast.Expr, style string) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X, style)
+	case *ast.ParenExpr:
+		return receiverTypeName(t.X, style)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return genericTypeName(t.X, []ast.Expr{t.Index}, style)
+	case *ast.IndexListExpr:
+		return genericTypeName(t.X, t.Indices, style)
+	default:
+		return "-"
+	}
+}
+
+func genericTypeName(base ast.Expr, params []ast.Expr, style string) string {
+	name := receiverTypeName(base, style)
+	if style == genericReceiversStrip {
+		return name
+	}
+	names := make([]string, len(params))
+	for index, param := range params {
+		names[index] = receiverTypeName(param, style)
+	}
+	return name + "[" + strings.Join(names, ",") + "]"
+}
@@ -0,0 +1,841 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func convertTestdata(t *testing.T, ignore Matcher, opts *Options) Coverage {
+	t.Helper()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	var out bytes.Buffer
+	if err := ConvertWithOptions(in, &out, ignore, opts, "testdata"); err != nil {
+		t.Fatal(err)
+	}
+
+	var cov Coverage
+	if err := xml.Unmarshal(out.Bytes(), &cov); err != nil {
+		t.Fatal(err)
+	}
+	return cov
+}
+
+func TestModuleFilenames(t *testing.T) {
+	t.Parallel()
+
+	cov := convertTestdata(t, &Ignore{GeneratedFiles: true}, &Options{ModuleFilenames: true})
+	if len(cov.Packages) != 1 {
+		t.Fatalf("expected 1 package, got %d", len(cov.Packages))
+	}
+	for _, class := range cov.Packages[0].Classes {
+		if !strings.HasPrefix(class.Filename, "github.com/franchb/gocover-cobertura/testdata/") {
+			t.Errorf("class %s filename %s is not module qualified", class.Name, class.Filename)
+		}
+	}
+}
+
+func TestTrimModulePrefix(t *testing.T) {
+	t.Parallel()
+
+	cov := convertTestdata(t, &Ignore{GeneratedFiles: true}, &Options{TrimModulePrefix: true})
+	if len(cov.Packages) != 1 || cov.Packages[0].Name != "testdata" {
+		t.Fatalf("expected the testdata package, got %v", cov.Packages)
+	}
+	for _, class := range cov.Packages[0].Classes {
+		if !strings.HasPrefix(class.Filename, "testdata/") {
+			t.Errorf("class %s filename %s is not module relative", class.Name, class.Filename)
+		}
+	}
+
+	for importPath, expected := range map[string]string{
+		"github.com/acme/svc/internal/foo": "internal/foo",
+		"github.com/acme/svc":              ".",
+		"github.com/pkg/errors":            "github.com/pkg/errors",
+	} {
+		if actual := trimModulePrefixOf(importPath, "github.com/acme/svc"); actual != expected {
+			t.Errorf("trimModulePrefixOf(%q) = %q, expected %q", importPath, actual, expected)
+		}
+	}
+}
+
+func TestClassNaming(t *testing.T) {
+	t.Parallel()
+
+	for naming, expected := range map[string][]string{
+		classNamingReceiver:     {"Type1"},
+		classNamingFile:         {"testdata/func2.go"},
+		classNamingFileBasename: {"func2.go"},
+		classNamingPackageFile:  {"github.com/franchb/gocover-cobertura/testdata/func2.go"},
+		classNamingReceiverFile: {"Type1@func2.go"},
+	} {
+		cov := convertTestdata(t, &Ignore{GeneratedFiles: true}, &Options{ClassNaming: naming})
+		var names []string
+		for _, class := range cov.Packages[0].Classes {
+			if class.Filename == "testdata/func2.go" {
+				names = append(names, class.Name)
+			}
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("%s: classes of func2.go %v, expected %v", naming, names, expected)
+		}
+	}
+}
+
+func TestMethodSignatures(t *testing.T) {
+	t.Parallel()
+
+	cov := convertTestdata(t, &Ignore{GeneratedFiles: true}, &Options{MethodSignatures: true, QualifiedMethods: true})
+	methods := map[string]string{}
+	for _, class := range cov.Packages[0].Classes {
+		for _, method := range class.Methods {
+			methods[method.Name] = method.Signature
+		}
+	}
+	if signature, ok := methods["Type1.Func2a"]; !ok || signature != "(*int)" {
+		t.Errorf("method Type1.Func2a signature %q, found %t, in %v", signature, ok, methods)
+	}
+}
+
+func TestFailOnEmpty(t *testing.T) {
+	t.Parallel()
+
+	opts := &Options{FailOnEmpty: true}
+	var out bytes.Buffer
+	err := ConvertWithOptions(strings.NewReader("mode: set"), &out, &Ignore{}, opts)
+	if !errors.Is(err, errEmptyCoverage) {
+		t.Errorf("expected empty coverage error, got %v", err)
+	}
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	err = ConvertWithOptions(in, &out, &Ignore{Dirs: regexp.MustCompile(`testdata`)}, opts, "testdata")
+	if !errors.Is(err, errEmptyCoverage) {
+		t.Errorf("expected empty coverage error when everything is ignored, got %v", err)
+	}
+}
+
+func TestAbsoluteFilenames(t *testing.T) {
+	t.Parallel()
+
+	cov := convertTestdata(t, &Ignore{GeneratedFiles: true}, &Options{FilenameStyle: filenameStyleAbsolute})
+	if len(cov.Sources) != 1 || cov.Sources[0].Path != "/" {
+		t.Errorf("expected a single / source, got %v", cov.Sources)
+	}
+	if len(cov.Packages) != 1 {
+		t.Fatalf("expected 1 package, got %d", len(cov.Packages))
+	}
+	for _, class := range cov.Packages[0].Classes {
+		if !filepath.IsAbs(filepath.FromSlash(class.Filename)) {
+			t.Errorf("class %s filename %s is not absolute", class.Name, class.Filename)
+		}
+	}
+}
+
+func TestTrimModulePath(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		FileName   string
+		ModulePath string
+		Expected   string
+	}{
+		{"github.com/foo/bar/baz/zip.go", "github.com/foo/bar", "baz/zip.go"},
+		{"github.com/Foo/Bar/baz/zip.go", "github.com/foo/bar", "baz/zip.go"},
+		{"github.com/foo/barbaz/zip.go", "github.com/foo/bar", "github.com/foo/barbaz/zip.go"},
+		{"github.com/other/bar/zip.go", "github.com/foo/bar", "github.com/other/bar/zip.go"},
+		{"zip.go", "github.com/foo/bar", "zip.go"},
+	} {
+		if actual := trimModulePath(test.FileName, test.ModulePath); actual != test.Expected {
+			t.Errorf("trimModulePath(%s, %s) == %s but should be %s",
+				test.FileName, test.ModulePath, actual, test.Expected)
+		}
+	}
+}
+
+func TestLookupPackage(t *testing.T) {
+	t.Parallel()
+
+	foo := &packages.Package{ID: "github.com/foo/bar"}
+	pkgMap := map[string]*packages.Package{foo.ID: foo}
+
+	if lookupPackage(pkgMap, "github.com/foo/bar") != foo {
+		t.Error("exact match not found")
+	}
+	if lookupPackage(pkgMap, "github.com/Foo/Bar") != foo {
+		t.Error("case-insensitive match not found")
+	}
+	if lookupPackage(pkgMap, "github.com/foo/baz") != nil {
+		t.Error("unexpected match")
+	}
+
+	pkgMap["github.com/FOO/bar"] = &packages.Package{ID: "github.com/FOO/bar"}
+	if lookupPackage(pkgMap, "github.com/Foo/Bar") != nil {
+		t.Error("ambiguous match should not be found")
+	}
+}
+
+func TestConvertWithOptionsLogger(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	var logs bytes.Buffer
+	opts := Options{
+		Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+	if err := ConvertWithOptions(in, io.Discard, &Ignore{GeneratedFiles: true}, &opts, "testdata"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`msg="loaded packages" profiles=5 packages=1`,
+		`msg="ignoring file" file=testdata/func3.go`,
+	} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("logs do not contain %q:\n%s", expected, logs.String())
+		}
+	}
+}
+
+func TestDevendorPath(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		Path     string
+		Expected string
+		Vendored bool
+	}{
+		{"vendor/github.com/pkg/errors/errors.go", "github.com/pkg/errors/errors.go", true},
+		{"example.com/app/vendor/github.com/pkg/errors", "github.com/pkg/errors", true},
+		{"/src/app/vendor/a/vendor/b/b.go", "b/b.go", true},
+		{"example.com/app/vendors/foo.go", "example.com/app/vendors/foo.go", false},
+		{"example.com/app/myvendor/foo.go", "example.com/app/myvendor/foo.go", false},
+	} {
+		actual, vendored := devendorPath(test.Path)
+		if actual != test.Expected || vendored != test.Vendored {
+			t.Errorf("devendorPath(%s) == %s, %t but should be %s, %t",
+				test.Path, actual, vendored, test.Expected, test.Vendored)
+		}
+	}
+}
+
+func TestReceiverTypeName(t *testing.T) {
+	t.Parallel()
+
+	src := `package foo
+
+func (c *Cache[K, V]) Ptr() {}
+func (c Cache[K,   V]) Value() {}
+func (s *Set[T]) One() {}
+func (Plain) Unnamed() {}
+func (p *(Paren)) Paren() {}
+`
+	parsed, err := parser.ParseFile(token.NewFileSet(), "foo.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][2]string{
+		"Ptr":     {"Cache[K,V]", "Cache"},
+		"Value":   {"Cache[K,V]", "Cache"},
+		"One":     {"Set[T]", "Set"},
+		"Unnamed": {"Plain", "Plain"},
+		"Paren":   {"Paren", "Paren"},
+	}
+	for _, decl := range parsed.Decls {
+		fn := decl.(*ast.FuncDecl)
+		recv := fn.Recv.List[0].Type
+		if actual := receiverTypeName(recv, genericReceiversCanonical); actual != expected[fn.Name.Name][0] {
+			t.Errorf("canonical receiver of %s == %s but should be %s", fn.Name.Name, actual, expected[fn.Name.Name][0])
+		}
+		if actual := receiverTypeName(recv, genericReceiversStrip); actual != expected[fn.Name.Name][1] {
+			t.Errorf("stripped receiver of %s == %s but should be %s", fn.Name.Name, actual, expected[fn.Name.Name][1])
+		}
+	}
+}
+
+func TestConvertVerboseLogging(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	var logs bytes.Buffer
+	opts := &Options{Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+	ignore := &Ignore{Files: regexp.MustCompile(`func4\.go$`), GeneratedFiles: true}
+	coverage, err := convert(context.Background(), in, ignore, opts, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	for _, expected := range []string{
+		`msg="parsed profiles"`,
+		`msg="loaded package" package=github.com/franchb/gocover-cobertura/testdata`,
+		`msg="ignoring file" file=github.com/franchb/gocover-cobertura/testdata/func4.go reason=-ignore-files`,
+		`msg="ignoring file" file=testdata/func3.go reason="generated file"`,
+		`msg="built coverage"`,
+	} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("missing %s in the logs:\n%s", expected, logs.String())
+		}
+	}
+}
+
+func TestConvertIgnoreFuncs(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	ignore := &Ignore{Funcs: regexp.MustCompile(`^Func2a$|^Type1\.Func2c$|^Func4$`), GeneratedFiles: true}
+	coverage, err := convert(context.Background(), in, ignore, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	var methods []string
+	var numLines int64
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			if class.Name == "func4" {
+				t.Errorf("class %s of ignored functions only is kept", class.Name)
+			}
+			for _, method := range class.Methods {
+				methods = append(methods, class.Name+"."+method.Name)
+				numLines += method.NumLines()
+			}
+		}
+	}
+	for _, method := range methods {
+		switch method {
+		case "Type1.Func2a", "Type1.Func2c", "-.Func4":
+			t.Errorf("ignored method %s is kept", method)
+		}
+	}
+	if coverage.LinesValid != numLines {
+		t.Errorf("lines valid %d, expected the %d lines of the kept methods", coverage.LinesValid, numLines)
+	}
+}
+
+func TestIgnoreMatchFunc(t *testing.T) {
+	t.Parallel()
+
+	ignore := &Ignore{Funcs: regexp.MustCompile(`^String$|^Type1\.Func2b$`)}
+	for name, expected := range map[string]bool{
+		"String":       true,
+		"Type1.String": true,
+		"Type1.Func2b": true,
+		"Type2.Func2b": false,
+		"Func2b":       false,
+		"Stringer":     false,
+	} {
+		if got := ignore.MatchFunc(name); got != expected {
+			t.Errorf("MatchFunc(%q) = %v, expected %v", name, got, expected)
+		}
+	}
+	if (&Ignore{}).MatchFunc("String") {
+		t.Error("function ignored without -ignore-funcs")
+	}
+}
+
+func TestConvertMatchFiles(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	ignore := &Ignore{MatchFiles: regexp.MustCompile(`/func2\.go$`)}
+	coverage, err := convert(context.Background(), in, ignore, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			if class.Filename != "testdata/func2.go" {
+				t.Errorf("class %s of %s is reported", class.Name, class.Filename)
+			}
+		}
+	}
+	if coverage.LinesValid == 0 {
+		t.Error("no line of func2.go is reported")
+	}
+}
+
+func TestConvertKeepGoing(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 1
+github.com/franchb/gocover-cobertura/testdata/missing.go:1.1,2.2 1 0
+`
+	if _, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, nil, []string{"testdata"}); err == nil {
+		t.Fatal("expected an error without KeepGoing")
+	}
+
+	var logs bytes.Buffer
+	opts := &Options{KeepGoing: true, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	coverage, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, opts, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	if coverage.LinesValid == 0 {
+		t.Error("no line of func4.go is reported")
+	}
+	if !strings.Contains(logs.String(), `msg="skipping file" file=github.com/franchb/gocover-cobertura/testdata/missing.go`) {
+		t.Errorf("no warning for missing.go:\n%s", logs.String())
+	}
+}
+
+func TestConvertPartial(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 1
+github.com/franchb/gocover-cobertura/testdata/missing.go:1.1,2.2 1 0
+github.com/franchb/gocover-cobertura/testdata/other.go:1.1,2.2 1 0
+`
+	coverage, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, nil, []string{"testdata"})
+	if coverage != nil || err == nil {
+		t.Fatalf("coverage %v and error %v, expected no coverage without Partial", coverage, err)
+	}
+	if !strings.HasPrefix(err.Error(), "2 file(s) cannot be converted:") || !errors.Is(err, ErrSourceMissing) {
+		t.Errorf("error %q, expected both files to be listed", err)
+	}
+
+	var out bytes.Buffer
+	err = ConvertWithOptions(strings.NewReader(profile), &out, &Ignore{}, &Options{Partial: true}, "testdata")
+	if err == nil || ExitCode(err) != exitPackages {
+		t.Errorf("error %v, expected the files which cannot be converted", err)
+	}
+	var cov Coverage
+	if err := xml.Unmarshal(out.Bytes(), &cov); err != nil {
+		t.Fatal(err)
+	}
+	if cov.LinesValid == 0 || len(cov.Packages) != 1 {
+		t.Errorf("%d lines in %d packages, expected the lines of func4.go", cov.LinesValid, len(cov.Packages))
+	}
+}
+
+func TestConvertContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ConvertContext(ctx, in, io.Discard, &Ignore{}, nil, "testdata")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+
+	profile := "mode: set\n" + strings.Repeat("github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 0\n", 2048)
+	if _, err := ParseProfilesContext(ctx, strings.NewReader(profile), &Ignore{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error while parsing, got %v", err)
+	}
+}
+
+func TestParseProfilesMalformedLine(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 0
+this is not a block
+
+mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:6.16,8.3 1 0
+`
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	profiles, err := parseProfiles(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || len(profiles[0].Blocks) != 2 {
+		t.Errorf("expected 1 profile of 2 blocks, got %v", profiles)
+	}
+	if expected := `msg="skipping malformed profile line" line=3 text="this is not a block"`; !strings.Contains(logs.String(), expected) {
+		t.Errorf("logs do not contain %q:\n%s", expected, logs.String())
+	}
+	if strings.Count(logs.String(), "malformed") != 1 {
+		t.Errorf("blank or mode lines reported as malformed:\n%s", logs.String())
+	}
+}
+
+func TestParseProfilesCRLF(t *testing.T) {
+	t.Parallel()
+
+	profile := "mode: count\r\n" +
+		"github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 2\r\n" +
+		"github.com/franchb/gocover-cobertura/testdata/func4.go:6.16,8.3 1 99999999999999999999\r\n" +
+		"\r\n"
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	profiles, err := parseProfiles(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].Mode != "count" || len(profiles[0].Blocks) != 1 || profiles[0].Blocks[0].Count != 2 {
+		t.Fatalf("expected 1 profile in count mode of 1 block, got %+v", profiles)
+	}
+	// the count beyond the range of int
+	if expected := `msg="skipping malformed profile line" line=3`; !strings.Contains(logs.String(), expected) || strings.Count(logs.String(), "malformed") != 1 {
+		t.Errorf("logs do not contain %q only:\n%s", expected, logs.String())
+	}
+}
+
+func TestParseProfilesLongLine(t *testing.T) {
+	t.Parallel()
+
+	profile := "mode: set\n" +
+		"github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 0\n" +
+		"github.com/franchb/gocover-cobertura/testdata/" + strings.Repeat("x", 100) + ".go:5.23,6.16 1 0\n"
+	_, err := parseProfiles(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{MaxProfileLine: 100})
+	if err == nil || !strings.Contains(err.Error(), "line 3 is longer than 100 bytes, raise -max-profile-line") {
+		t.Errorf("error %v, expected the long line to be reported", err)
+	}
+
+	if _, err := parseProfiles(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{MaxProfileLine: 200}); err != nil {
+		t.Errorf("error %v with a larger bound", err)
+	}
+}
+
+func TestStatementWeighted(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	profiles, err := ParseProfiles(in, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var covered, valid int64
+	for _, profile := range profiles {
+		if strings.HasSuffix(profile.FileName, "/func1.go") {
+			continue // stale, its blocks are outside of the function of the file
+		}
+		for _, block := range profile.Blocks {
+			valid += int64(block.NumStmt)
+			if block.Count > 0 {
+				covered += int64(block.NumStmt)
+			}
+		}
+	}
+
+	cov := convertTestdata(t, nil, &Options{Statements: true})
+	if cov.LinesCovered != covered || cov.LinesValid != valid {
+		t.Errorf("totals %d/%d, expected the %d/%d statements of the profile", cov.LinesCovered, cov.LinesValid, covered, valid)
+	}
+	if lines := convertTestdata(t, nil, nil).LinesValid; lines == valid {
+		t.Errorf("%d statements, expected to differ from the %d lines", valid, lines)
+	}
+}
+
+const initSource = `package p
+
+func init() {
+	a = 1
+}
+
+func F() {}
+
+func init() {
+	b = 2
+}
+`
+
+// visitInitSource returns the classes of initSource, every block run once.
+func visitInitSource(t *testing.T, opts *Options) []*Class {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "p.go", initSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visitor := &fileVisitor{
+		fset:     fset,
+		fileName: "p.go",
+		classes:  map[string]*Class{},
+		pkg:      &Package{Name: "p"},
+		profile: &Profile{FileName: "p.go", Mode: "set", Blocks: []ProfileBlock{
+			{StartLine: 3, StartCol: 13, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 1},
+			{StartLine: 7, StartCol: 10, EndLine: 7, EndCol: 11, NumStmt: 0, Count: 1},
+			{StartLine: 9, StartCol: 13, EndLine: 11, EndCol: 2, NumStmt: 1, Count: 1},
+		}},
+		opts:  opts,
+		inits: numberInits(parsed),
+	}
+	ast.Walk(visitor, parsed)
+	return visitor.pkg.Classes
+}
+
+func TestInitNames(t *testing.T) {
+	t.Parallel()
+
+	classes := visitInitSource(t, nil)
+	if len(classes) != 1 {
+		t.Fatalf("classes %v, expected a single one", classes)
+	}
+	var names []string
+	for _, method := range classes[0].Methods {
+		names = append(names, method.Name)
+	}
+	if strings.Join(names, " ") != "init#1 F init#2" {
+		t.Errorf("methods %v, expected init#1, F and init#2", names)
+	}
+
+	parsed, err := parser.ParseFile(token.NewFileSet(), "q.go", "package q\n\nfunc init() {}\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numbers := numberInits(parsed); numbers != nil {
+		t.Errorf("numbers %v of a single init, expected none", numbers)
+	}
+}
+
+func TestInitClass(t *testing.T) {
+	t.Parallel()
+
+	classes := visitInitSource(t, &Options{InitClass: true})
+	if len(classes) != 2 || classes[0].Name != "<init>" || classes[1].Name != "-" {
+		t.Fatalf("classes %v, expected <init> then -", classes)
+	}
+	if len(classes[0].Methods) != 2 || len(classes[1].Methods) != 1 {
+		t.Errorf("methods %d and %d, expected the 2 init functions apart", len(classes[0].Methods), len(classes[1].Methods))
+	}
+}
+
+func TestFindAbsFilePathCgo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cgoFile := filepath.Join(dir, "c.go")
+	plainFile := filepath.Join(dir, "p.go")
+	if err := os.WriteFile(cgoFile, []byte("package p\n\nimport \"C\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(plainFile, []byte("//go:build never\n\npackage p\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{
+		GoFiles:      []string{filepath.Join(dir, "g.go")},
+		IgnoredFiles: []string{cgoFile, plainFile},
+	}
+
+	for _, test := range []struct {
+		profileName, expected string
+	}{
+		{"example.com/p/g.go", filepath.Join(dir, "g.go")},
+		{"example.com/p/c.go", cgoFile},
+		{"example.com/p/c.cgo1.go", cgoFile},
+		{"example.com/p/p.go", ""},
+	} {
+		if actual := findAbsFilePath(pkg, test.profileName, nil); actual != test.expected {
+			t.Errorf("%s: path %q, expected %q", test.profileName, actual, test.expected)
+		}
+	}
+
+	// the ignored files are read from the file system of the sources
+	memFile := "/src/p/m.go"
+	pkg.IgnoredFiles = append(pkg.IgnoredFiles, memFile)
+	fsys := fstest.MapFS{fsPath(memFile): &fstest.MapFile{Data: []byte("package p\n\nimport \"C\"\n")}}
+	if actual := findAbsFilePath(pkg, "example.com/p/m.go", fsys); actual != memFile {
+		t.Errorf("cgo file %q of the file system, expected %q", actual, memFile)
+	}
+}
+
+func TestPackageNaming(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		naming, expected string
+	}{
+		{packageNamingImportPath, "github.com/franchb/gocover-cobertura/testdata"},
+		{packageNamingDirectory, "testdata"},
+	} {
+		cov := convertTestdata(t, &Ignore{GeneratedFiles: true}, &Options{PackageNaming: test.naming})
+		if len(cov.Packages) != 1 || cov.Packages[0].Name != test.expected {
+			t.Errorf("%s: packages %v, expected a single %s", test.naming, cov.Packages, test.expected)
+		}
+	}
+}
+
+func TestDevendoredPackageFiles(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "vendor", "example.com", "dep")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{
+		ID:     "example.com/main/vendor/example.com/dep",
+		Module: &packages.Module{Path: "example.com/main", Dir: root},
+	}
+	cov := &Coverage{}
+	for _, name := range []string{"a.go", "b.go"} {
+		source := "package dep\n\nfunc F() int {\n\treturn 1\n}\n"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		pkg.GoFiles = append(pkg.GoFiles, filepath.Join(dir, name))
+		profile := &Profile{
+			FileName: "example.com/main/vendor/example.com/dep/" + name,
+			Mode:     "set",
+			Blocks:   []ProfileBlock{{StartLine: 3, StartCol: 14, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 1}},
+		}
+		file, err := parseFile(profile, pkg, &Ignore{}, &Options{Devendor: true}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cov.addFile(file)
+	}
+	if len(cov.Packages) != 1 || cov.Packages[0].Name != "example.com/dep" || len(cov.Packages[0].Classes) != 2 {
+		t.Errorf("packages %v, expected the files in a single example.com/dep", cov.Packages)
+	}
+}
+
+// writeModule writes the files, by slash separated name, below root and
+// makes it the working directory for the rest of the test.
+func writeModule(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+//nolint:paralleltest // changes the working directory and the environment
+func TestConvertVendoredPackage(t *testing.T) {
+	writeModule(t, t.TempDir(), map[string]string{
+		"go.mod":                        "module example.com/main\n\ngo 1.21\n\nrequire example.com/dep v1.0.0\n",
+		"main.go":                       "package main\n\nimport \"example.com/dep\"\n\nfunc main() {\n\tdep.F()\n}\n",
+		"vendor/modules.txt":            "# example.com/dep v1.0.0\n## explicit; go 1.21\nexample.com/dep\n",
+		"vendor/example.com/dep/dep.go": "package dep\n\nfunc F() int {\n\treturn 1\n}\n",
+	})
+	t.Setenv("GOFLAGS", "-mod=vendor")
+	t.Setenv("GOWORK", "off")
+
+	for _, test := range []struct {
+		devendor bool
+		filename string
+	}{
+		{true, "example.com/dep/dep.go"},
+		{false, "vendor/example.com/dep/dep.go"},
+	} {
+		profile := "mode: set\nexample.com/dep/dep.go:3.14,5.2 1 1\n"
+		cov, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{Devendor: test.devendor}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cov.close()
+		if len(cov.Packages) != 1 || cov.Packages[0].Name != "example.com/dep" {
+			t.Fatalf("devendor %t: packages %v, expected a single example.com/dep", test.devendor, cov.Packages)
+		}
+		classes := cov.Packages[0].Classes
+		if len(classes) != 1 || classes[0].Filename != test.filename {
+			t.Errorf("devendor %t: classes %v, expected a single one of %s", test.devendor, classes, test.filename)
+		}
+	}
+
+	profile := "mode: set\nexample.com/dep/dep.go:3.14,5.2 1 1\n"
+	cov, err := convert(context.Background(), strings.NewReader(profile), &Ignore{Vendor: true}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cov.close()
+	if len(cov.Packages) != 0 {
+		t.Errorf("packages %v, expected the vendored one ignored", cov.Packages)
+	}
+}
+
+//nolint:paralleltest // changes the working directory and the environment
+func TestConvertUnderVendorDir(t *testing.T) {
+	writeModule(t, filepath.Join(t.TempDir(), "vendor", "proj"), map[string]string{
+		"go.mod": "module example.com/proj\n\ngo 1.21\n",
+		"p.go":   "package proj\n\nfunc F() int {\n\treturn 1\n}\n",
+	})
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+
+	profile := "mode: set\nexample.com/proj/p.go:3.14,5.2 1 1\n"
+	cov, err := convert(context.Background(), strings.NewReader(profile), &Ignore{Vendor: true}, &Options{Devendor: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cov.close()
+	if len(cov.Packages) != 1 || len(cov.Packages[0].Classes) != 1 || cov.Packages[0].Classes[0].Filename != "p.go" {
+		t.Errorf("packages %v, expected the class of p.go in a checkout under a vendor directory", cov.Packages)
+	}
+}
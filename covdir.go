@@ -0,0 +1,49 @@
+package cobertura
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// covDirProfile is a text profile converted from binary coverage data, which
+// is removed once closed.
+type covDirProfile struct {
+	*os.File
+}
+
+func (p covDirProfile) Close() error {
+	err := p.File.Close()
+	if removeErr := os.Remove(p.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// openCovDir converts the binary coverage data written to dir by programs
+// built with -cover and run with GOCOVERDIR=dir, using go tool covdata, and
+// opens the resulting text profile.
+func openCovDir(dir string) (covDirProfile, error) {
+	tmp, err := os.CreateTemp("", "gocover-cobertura-*.out")
+	if err != nil {
+		return covDirProfile{}, err
+	}
+	_ = tmp.Close()
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "tool", "covdata", "textfmt", "-i="+dir, "-o="+tmp.Name())
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return covDirProfile{}, fmt.Errorf("go tool covdata: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	file, err := os.Open(tmp.Name())
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return covDirProfile{}, err
+	}
+	return covDirProfile{file}, nil
+}
@@ -0,0 +1,70 @@
+package cobertura
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenCovDir(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.Skip("builds a coverage instrumented program")
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":  "module example.com/covdir\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc main() {\n\tprintln(\"covered\")\n}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	covDir := filepath.Join(dir, "covdata")
+	if err := os.Mkdir(covDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	build := exec.Command("go", "build", "-cover", "-o", "covdir", ".")
+	build.Dir = dir
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v: %s", err, out)
+	}
+	run := exec.Command(filepath.Join(dir, "covdir"))
+	run.Env = append(os.Environ(), "GOCOVERDIR="+covDir)
+	if out, err := run.CombinedOutput(); err != nil {
+		t.Fatalf("run: %v: %s", err, out)
+	}
+
+	profile, err := openCovDir(covDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := profile.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(profile.Name()); !os.IsNotExist(err) {
+		t.Errorf("the converted profile was not removed: %v", err)
+	}
+
+	if !strings.HasPrefix(string(data), "mode: set\n") || !strings.Contains(string(data), "example.com/covdir/main.go:") {
+		t.Errorf("unexpected profile:\n%s", data)
+	}
+}
+
+func TestOpenCovDirError(t *testing.T) {
+	t.Parallel()
+
+	if _, err := openCovDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
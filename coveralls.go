@@ -0,0 +1,64 @@
+package cobertura
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec // Coveralls identifies sources by their MD5 digest
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+type coverallsJob struct {
+	SourceFiles []*coverallsSourceFile `json:"source_files"`
+}
+
+type coverallsSourceFile struct {
+	Name         string   `json:"name"`
+	SourceDigest string   `json:"source_digest"`
+	Coverage     []*int64 `json:"coverage"`
+}
+
+// writeCoveralls writes the coverage as the source_files payload of the
+// Coveralls API.
+func (cov *Coverage) writeCoveralls(out io.Writer) error {
+	files, err := cov.files()
+	if err != nil {
+		return err
+	}
+
+	job := coverallsJob{SourceFiles: make([]*coverallsSourceFile, 0, len(files))}
+	for _, file := range files {
+		numLines := 0
+		sourceFile := &coverallsSourceFile{Name: file.Filename}
+
+		if source, ok := cov.sourceFiles[file.Filename]; ok {
+			src, err := source.read()
+			if err != nil {
+				return err
+			}
+			digest := md5.Sum(src) //nolint:gosec // see import
+			sourceFile.SourceDigest = hex.EncodeToString(digest[:])
+			numLines = bytes.Count(src, []byte("\n"))
+			if len(src) > 0 && src[len(src)-1] != '\n' {
+				numLines++
+			}
+		}
+
+		for _, line := range file.Lines {
+			if line.Number > numLines {
+				numLines = line.Number
+			}
+		}
+		sourceFile.Coverage = make([]*int64, numLines)
+		for _, line := range file.Lines {
+			hits := line.Hits
+			sourceFile.Coverage[line.Number-1] = &hits
+		}
+
+		job.SourceFiles = append(job.SourceFiles, sourceFile)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(job)
+}
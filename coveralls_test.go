@@ -0,0 +1,55 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestWriteCoveralls(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	coverage, err := convert(context.Background(), in, &Ignore{GeneratedFiles: true}, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	var out bytes.Buffer
+	if err := coverage.writeCoveralls(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	var job coverallsJob
+	if err := json.Unmarshal(out.Bytes(), &job); err != nil {
+		t.Fatal(err)
+	}
+
+	var func2 *coverallsSourceFile
+	for _, file := range job.SourceFiles {
+		if file.Name == "testdata/func2.go" {
+			func2 = file
+		}
+	}
+	if func2 == nil {
+		t.Fatal("testdata/func2.go not found")
+	}
+	if len(func2.SourceDigest) != 32 {
+		t.Errorf("bad source digest %q", func2.SourceDigest)
+	}
+	if len(func2.Coverage) != 18 {
+		t.Fatalf("coverage has %d lines but should have 18", len(func2.Coverage))
+	}
+	if func2.Coverage[0] != nil || func2.Coverage[7] == nil || *func2.Coverage[7] != 1 ||
+		func2.Coverage[13] == nil || *func2.Coverage[13] != 0 {
+		t.Errorf("unexpected coverage %v", func2.Coverage)
+	}
+}
@@ -0,0 +1,30 @@
+package cobertura
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// writeCSV writes the coverage as CSV, with a header and a row per function.
+func (cov *Coverage) writeCSV(out io.Writer) error {
+	writer := csv.NewWriter(out)
+	_ = writer.Write([]string{"package", "file", "class", "method", "lines_valid", "lines_covered", "line_rate"})
+	for _, pkg := range cov.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				valid, covered := method.NumLines(), method.NumLinesWithHits()
+				rate := ""
+				if valid > 0 {
+					rate = strconv.FormatFloat(float64(covered)/float64(valid), 'f', 4, 64)
+				}
+				_ = writer.Write([]string{
+					pkg.Name, class.Filename, class.Name, method.Name,
+					strconv.FormatInt(valid, 10), strconv.FormatInt(covered, 10), rate,
+				})
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
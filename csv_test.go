@@ -0,0 +1,36 @@
+package cobertura
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	coverage := Coverage{
+		Packages: []*Package{{Name: "example.com/foo", Classes: []*Class{
+			{Name: "-", Filename: "foo/foo.go", Methods: []*Method{
+				{Name: "Foo", Lines: Lines{{Number: 4, Hits: 1}, {Number: 5, Hits: 0}, {Number: 6, Hits: 0}}},
+				{Name: "Empty", Lines: Lines{}},
+			}},
+			{Name: "Bar", Filename: "foo/bar, baz.go", Methods: []*Method{
+				{Name: "Get", Lines: Lines{{Number: 13, Hits: 2}}},
+			}},
+		}}},
+	}
+
+	var out strings.Builder
+	if err := coverage.writeCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `package,file,class,method,lines_valid,lines_covered,line_rate
+example.com/foo,foo/foo.go,-,Foo,3,1,0.3333
+example.com/foo,foo/foo.go,-,Empty,0,0,
+example.com/foo,"foo/bar, baz.go",Bar,Get,1,1,1.0000
+`
+	if out.String() != expected {
+		t.Errorf("unexpected CSV:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
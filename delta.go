@@ -0,0 +1,169 @@
+package cobertura
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// coverageDelta is the change of the line coverage from a baseline report,
+// in total and for the packages and files whose coverage changed.
+type coverageDelta struct {
+	Total    deltaEntry   `json:"total"`
+	Packages []deltaEntry `json:"packages"`
+	Files    []deltaEntry `json:"files"`
+}
+
+// deltaEntry holds the new and old line coverage percentages of a package or
+// file, nil when missing from a report or without lines to cover.
+type deltaEntry struct {
+	Name   string   `json:"name"`
+	New    *float64 `json:"new"`
+	Old    *float64 `json:"old"`
+	Change *float64 `json:"change"`
+}
+
+// lineCounts are the covered and valid lines of a package or file.
+type lineCounts struct {
+	covered, valid int64
+}
+
+func (c lineCounts) percent() *float64 {
+	if c.valid == 0 {
+		return nil
+	}
+	p := math.Round(10000*float64(c.covered)/float64(c.valid)) / 100
+	return &p
+}
+
+// newCoverageDelta compares the coverage with the baseline.  Packages and
+// files are listed in the order of the coverage, followed by the ones only
+// in the baseline, sorted.
+func newCoverageDelta(coverage, baseline *Coverage) *coverageDelta {
+	newPkgs, pkgNames := packageCounts(coverage)
+	oldPkgs, _ := packageCounts(baseline)
+	newFiles, fileNames := fileCounts(coverage)
+	oldFiles, _ := fileCounts(baseline)
+
+	return &coverageDelta{
+		Total: newDeltaEntry("total",
+			lineCounts{coverage.NumLinesWithHits(), coverage.NumLines()},
+			lineCounts{baseline.NumLinesWithHits(), baseline.NumLines()}),
+		Packages: changedEntries(newPkgs, oldPkgs, pkgNames),
+		Files:    changedEntries(newFiles, oldFiles, fileNames),
+	}
+}
+
+func newDeltaEntry(name string, counts, previous lineCounts) deltaEntry {
+	entry := deltaEntry{Name: name, New: counts.percent(), Old: previous.percent()}
+	if entry.New != nil && entry.Old != nil {
+		change := math.Round(100*(*entry.New-*entry.Old)) / 100
+		entry.Change = &change
+	}
+	return entry
+}
+
+func changedEntries(counts, previous map[string]lineCounts, names []string) []deltaEntry {
+	var removed []string
+	for name := range previous {
+		if _, ok := counts[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+
+	entries := []deltaEntry{}
+	for _, name := range append(names, removed...) {
+		entry := newDeltaEntry(name, counts[name], previous[name])
+		if entry.Change != nil && *entry.Change == 0 {
+			continue
+		}
+		if entry.New == nil && entry.Old == nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func packageCounts(cov *Coverage) (map[string]lineCounts, []string) {
+	counts := make(map[string]lineCounts, len(cov.Packages))
+	names := make([]string, 0, len(cov.Packages))
+	for _, pkg := range cov.Packages {
+		c, ok := counts[pkg.Name]
+		if !ok {
+			names = append(names, pkg.Name)
+		}
+		c.covered += pkg.NumLinesWithHits()
+		c.valid += pkg.NumLines()
+		counts[pkg.Name] = c
+	}
+	return counts, names
+}
+
+func fileCounts(cov *Coverage) (map[string]lineCounts, []string) {
+	counts := map[string]lineCounts{}
+	var names []string
+	for _, pkg := range cov.Packages {
+		for _, class := range pkg.Classes {
+			c, ok := counts[class.Filename]
+			if !ok {
+				names = append(names, class.Filename)
+			}
+			c.covered += class.NumLinesWithHits()
+			c.valid += class.NumLines()
+			counts[class.Filename] = c
+		}
+	}
+	return counts, names
+}
+
+// writeMarkdown writes the delta as a sentence and Markdown tables of the
+// changed packages and files, for bots commenting pull requests.
+func (d *coverageDelta) writeMarkdown(out io.Writer) error {
+	switch {
+	case d.Total.Change == nil:
+		_, _ = fmt.Fprintf(out, "Coverage is %s, it was %s.\n",
+			deltaPercent(d.Total.New), deltaPercent(d.Total.Old))
+	case *d.Total.Change == 0:
+		_, _ = fmt.Fprintf(out, "Coverage is unchanged at %s.\n", deltaPercent(d.Total.New))
+	default:
+		_, _ = fmt.Fprintf(out, "Coverage changed by %+.2f%%, from %s to %s.\n",
+			*d.Total.Change, deltaPercent(d.Total.Old), deltaPercent(d.Total.New))
+	}
+	for _, table := range []struct {
+		title   string
+		entries []deltaEntry
+	}{{"Package", d.Packages}, {"File", d.Files}} {
+		if len(table.entries) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "\n| %s | New | Old | Change |\n", table.title)
+		_, _ = fmt.Fprintln(out, "| --- | ---: | ---: | ---: |")
+		for _, entry := range table.entries {
+			change := "-"
+			if entry.Change != nil {
+				change = fmt.Sprintf("%+.2f%%", *entry.Change)
+			}
+			_, _ = fmt.Fprintf(out, "| %s | %s | %s | %s |\n", markdownEscape(entry.Name),
+				deltaPercent(entry.New), deltaPercent(entry.Old), change)
+		}
+	}
+	return nil
+}
+
+// writeJSON writes the delta as an indented JSON document.
+func (d *coverageDelta) writeJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(d)
+}
+
+func deltaPercent(p *float64) string {
+	if p == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", *p)
+}
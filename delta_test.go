@@ -0,0 +1,69 @@
+package cobertura
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestCoverageDeltaMarkdown(t *testing.T) {
+	t.Parallel()
+
+	baseline := baselineCoverage([]int64{1, 1, 0, 0}, []int64{1, 1}, []int64{0})
+	coverage := baselineCoverage([]int64{1, 0, 0, 0}, []int64{1, 1})
+	coverage.Packages[1].Classes[0].Filename = "g.go"
+
+	var out bytes.Buffer
+	if err := newCoverageDelta(coverage, baseline).writeMarkdown(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `Coverage changed by -7.14%, from 57.14% to 50.00%.
+
+| Package | New | Old | Change |
+| --- | ---: | ---: | ---: |
+| example.com/a | 25.00% | 50.00% | -25.00% |
+| example.com/c | - | 0.00% | - |
+
+| File | New | Old | Change |
+| --- | ---: | ---: | ---: |
+| f.go | 25.00% | 57.14% | -32.14% |
+| g.go | 100.00% | - | - |
+`
+	if out.String() != expected {
+		t.Errorf("delta:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+
+	out.Reset()
+	if err := newCoverageDelta(baseline, baseline).writeMarkdown(&out); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Coverage is unchanged at 57.14%.\n"; out.String() != expected {
+		t.Errorf("delta %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestCoverageDeltaJSON(t *testing.T) {
+	t.Parallel()
+
+	baseline := baselineCoverage([]int64{1, 1, 0})
+	coverage := baselineCoverage([]int64{1, 1, 1})
+
+	var out bytes.Buffer
+	if err := newCoverageDelta(coverage, baseline).writeJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var delta struct {
+		Total    deltaEntry
+		Packages []deltaEntry
+		Files    []deltaEntry
+	}
+	if err := json.Unmarshal(out.Bytes(), &delta); err != nil {
+		t.Fatal(err)
+	}
+	if delta.Total.Change == nil || *delta.Total.Change != 33.33 {
+		t.Errorf("total change %v, expected 33.33 in %s", delta.Total.Change, out.String())
+	}
+	if len(delta.Packages) != 1 || *delta.Packages[0].New != 100 || *delta.Packages[0].Old != 66.67 {
+		t.Errorf("packages %s", out.String())
+	}
+}
@@ -0,0 +1,138 @@
+package cobertura
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// resolveModules sets the modules of the packages to the directories their
+// files are named from: the module holding the vendor directory for the
+// vendored packages, GOROOT/src for the standard library, and the main
+// module for the modules replaced by one of its directories.
+func resolveModules(pkgs []*packages.Package) {
+	var mainDirs []string
+	for _, pkg := range pkgs {
+		if pkg != nil && pkg.Module != nil && pkg.Module.Main && pkg.Module.Dir != "" {
+			mainDirs = append(mainDirs, pkg.Module.Dir)
+		}
+	}
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		if pkg.Module == nil || pkg.Module.Dir == "" {
+			if module := vendoredModule(pkg); module != nil {
+				pkg.Module = module
+				continue
+			}
+		}
+		if pkg.Module == nil {
+			pkg.Module = stdModule(pkg)
+		} else if module := replacedModule(pkg.Module, mainDirs); module != nil {
+			pkg.Module = module
+		}
+	}
+}
+
+// isDependency reports whether the package is out of the main modules, as
+// the dependencies and standard library packages profiled with
+// -coverpkg=all.
+func isDependency(pkg *packages.Package) bool {
+	return pkg.Module == nil || !pkg.Module.Main
+}
+
+// stdModule returns a module without path, rooted at GOROOT/src, for a
+// package of the standard library, which has none, so that its files are
+// named by their import paths as in the profiles.  It returns nil for any
+// other package.
+func stdModule(pkg *packages.Package) *packages.Module {
+	first, _, _ := strings.Cut(pkg.ID, "/")
+	if strings.Contains(first, ".") || len(pkg.GoFiles) == 0 {
+		return nil
+	}
+	root, ok := strings.CutSuffix(filepath.ToSlash(filepath.Dir(pkg.GoFiles[0])), "/"+pkg.ID)
+	if !ok {
+		return nil
+	}
+	return &packages.Module{Dir: filepath.FromSlash(root)}
+}
+
+// ignoresDeps reports whether m ignores the packages out of the main
+// modules.
+func ignoresDeps(m Matcher) bool {
+	depsMatcher, ok := m.(interface{ ignoresDeps() bool })
+	return ok && depsMatcher.ignoresDeps()
+}
+
+// vendoredModule returns the module of a package of a vendor directory,
+// which has no directory in vendor mode, or is missing with old Go
+// versions.  It is rooted at the module holding the vendor directory, so
+// that the files of the package are named vendor/<import path>/... as in
+// the tree.  It returns nil for any other package.
+func vendoredModule(pkg *packages.Package) *packages.Module {
+	if len(pkg.GoFiles) == 0 {
+		return nil
+	}
+	root, ok := strings.CutSuffix(filepath.ToSlash(filepath.Dir(pkg.GoFiles[0])), "/vendor/"+pkg.ID)
+	if !ok {
+		return nil
+	}
+	module := &packages.Module{Path: pkg.ID}
+	if pkg.Module != nil {
+		vendored := *pkg.Module
+		module = &vendored
+	}
+	module.Dir = filepath.FromSlash(root)
+	return module
+}
+
+// moduleFileName returns the name of the file relative to the directory of
+// its module: fileName, relative to the module path, unless absFilePath is
+// elsewhere in the module directory, as the vendored files are.
+func moduleFileName(fileName, absFilePath string, module *packages.Module) string {
+	if module.Dir == "" || absFilePath == "" || filepath.Join(module.Dir, fileName) == absFilePath {
+		return fileName
+	}
+	rel, ok := relPath(module.Dir, absFilePath)
+	if !ok {
+		return fileName
+	}
+	return filepath.ToSlash(rel)
+}
+
+// replacedModule returns the module replaced by a local directory, as with
+// "replace example.com/x => ./x", rooted at the main module holding that
+// directory, so that its files are named x/... as in the tree rather than
+// relative to a module path which does not prefix them.  A replaced module
+// without directory gets the one of its replacement.  It returns nil for
+// any other module.
+func replacedModule(module *packages.Module, mainDirs []string) *packages.Module {
+	if module.Replace == nil || module.Replace.Dir == "" {
+		return nil
+	}
+	replaced := *module
+	if replaced.Dir == "" {
+		replaced.Dir = module.Replace.Dir
+	}
+	if isLocalPath(module.Replace.Path) {
+		for _, dir := range mainDirs {
+			if _, ok := relPath(dir, module.Replace.Dir); ok {
+				replaced.Dir = dir
+				break
+			}
+		}
+	}
+	if replaced.Dir == module.Dir {
+		return nil
+	}
+	return &replaced
+}
+
+// isLocalPath reports whether the path of a replace directive is a
+// directory, rather than a module path.
+func isLocalPath(p string) bool {
+	return p == "." || p == ".." || strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") ||
+		strings.HasPrefix(p, `.\`) || strings.HasPrefix(p, `..\`) || filepath.IsAbs(p)
+}
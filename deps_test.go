@@ -0,0 +1,169 @@
+package cobertura
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestStdModule(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join("go", "src")
+	for _, test := range []struct {
+		id, file string
+		expected bool
+	}{
+		{"net/http", filepath.Join(root, "net", "http", "server.go"), true},
+		{"errors", filepath.Join(root, "errors", "errors.go"), true},
+		{"example.com/p", filepath.Join(root, "example.com", "p", "p.go"), false},
+		{"errors", filepath.Join(root, "other", "errors.go"), false},
+	} {
+		module := stdModule(&packages.Package{ID: test.id, GoFiles: []string{test.file}})
+		if (module != nil) != test.expected {
+			t.Errorf("%s: module %v, expected one: %v", test.id, module, test.expected)
+		} else if module != nil && (module.Dir != root || module.Path != "") {
+			t.Errorf("%s: module %+v, expected no path in %s", test.id, module, root)
+		}
+	}
+}
+
+func TestConvertDeps(t *testing.T) {
+	t.Parallel()
+
+	profile := "mode: set\n" +
+		"errors/errors.go:1.1,2.1 1 1\n" +
+		"github.com/franchb/gocover-cobertura/testdata/func2.go:8.34,9.16 1 1\n"
+
+	for _, test := range []struct {
+		name            string
+		ignore          *Ignore
+		packages, roots int
+	}{
+		{"include", &Ignore{}, 2, 2},
+		{"ignore", &Ignore{Deps: true}, 1, 1},
+	} {
+		coverage, err := convert(context.Background(), strings.NewReader(profile), test.ignore, nil, []string{"testdata"})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(coverage.Packages) != test.packages || len(coverage.Sources) != test.roots {
+			t.Errorf("%s: %d packages and %d sources, expected %d and %d",
+				test.name, len(coverage.Packages), len(coverage.Sources), test.packages, test.roots)
+		}
+		std := false
+		for _, source := range coverage.Sources {
+			std = std || filepath.Base(source.Path) == "src"
+		}
+		if std != (test.roots > 1) {
+			t.Errorf("%s: sources %v, expected GOROOT/src: %v", test.name, coverage.Sources, test.roots > 1)
+		}
+	}
+}
+
+func TestVendoredModule(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join("home", "a")
+	file := filepath.Join(root, "vendor", "example.com", "b", "b.go")
+	for _, module := range []*packages.Module{nil, {Path: "example.com/b", Version: "v1.0.0"}} {
+		pkg := &packages.Package{ID: "example.com/b", GoFiles: []string{file}, Module: module}
+		vendored := vendoredModule(pkg)
+		if vendored == nil || vendored.Dir != root || vendored.Path != "example.com/b" {
+			t.Fatalf("module %+v, expected example.com/b in %s", vendored, root)
+		}
+		if fileName := moduleFileName("b.go", file, vendored); fileName != "vendor/example.com/b/b.go" {
+			t.Errorf("file name %s, expected vendor/example.com/b/b.go", fileName)
+		}
+	}
+
+	pkg := &packages.Package{ID: "example.com/b", GoFiles: []string{filepath.Join(root, "b", "b.go")}}
+	if module := vendoredModule(pkg); module != nil {
+		t.Errorf("module %+v of a package out of vendor, expected none", module)
+	}
+	if fileName := moduleFileName("b/b.go", pkg.GoFiles[0], &packages.Module{Dir: root}); fileName != "b/b.go" {
+		t.Errorf("file name %s, expected b/b.go", fileName)
+	}
+}
+
+func TestReplacedModule(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(string(filepath.Separator)+"home", "a")
+	local := filepath.Join(root, "third", "b")
+	outside := filepath.Join(string(filepath.Separator)+"home", "b")
+	for _, test := range []struct {
+		name     string
+		module   *packages.Module
+		expected string
+	}{
+		{"inside", &packages.Module{Path: "example.com/b", Dir: local, Replace: &packages.Module{Path: "./third/b", Dir: local}}, root},
+		{"outside", &packages.Module{Path: "example.com/b", Dir: outside, Replace: &packages.Module{Path: "../b", Dir: outside}}, ""},
+		{"no dir", &packages.Module{Path: "example.com/b", Replace: &packages.Module{Path: "example.com/fork", Dir: outside}}, outside},
+		{"not replaced", &packages.Module{Path: "example.com/b", Dir: outside}, ""},
+	} {
+		module := replacedModule(test.module, []string{root})
+		switch {
+		case test.expected == "" && module != nil:
+			t.Errorf("%s: module %+v, expected none", test.name, module)
+		case test.expected != "" && (module == nil || module.Dir != test.expected):
+			t.Errorf("%s: module %+v, expected one in %s", test.name, module, test.expected)
+		}
+	}
+
+	file := filepath.Join(local, "b.go")
+	if fileName := moduleFileName("b.go", file, &packages.Module{Path: "example.com/b", Dir: root}); fileName != "third/b/b.go" {
+		t.Errorf("file name %s, expected third/b/b.go", fileName)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		classFileName, absPath, expected string
+	}{
+		{"a.go", "/home/svc/a.go", "/home/svc"},
+		{"internal/a.go", "/home/svc/internal/a.go", "/home/svc"},
+		{"/home/svc/a.go", "/home/svc/a.go", "/"},
+		{"a.go", "/a.go", "/"},
+		{"example.com/svc/a.go", "/home/svc/a.go", ""},
+		{"vc/a.go", "/home/svc/a.go", ""},
+	} {
+		if actual := fileSource(test.classFileName, filepath.FromSlash(test.absPath)); actual != filepath.FromSlash(test.expected) {
+			t.Errorf("%s in %s: source %q, expected %q", test.classFileName, test.absPath, actual, test.expected)
+		}
+	}
+}
+
+func TestSourcesPrefixFilenames(t *testing.T) {
+	t.Parallel()
+
+	// the standard library and this module, under two sources
+	profile := "mode: set\n" +
+		"errors/errors.go:1.1,2.1 1 1\n" +
+		"github.com/franchb/gocover-cobertura/testdata/func2.go:8.34,9.16 1 1\n"
+	for _, style := range []string{filenameStyleModule, filenameStyleRepo, filenameStyleAbsolute} {
+		coverage, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{FilenameStyle: style}, []string{"testdata"})
+		if err != nil {
+			t.Fatalf("%s: %v", style, err)
+		}
+		if style != filenameStyleAbsolute && len(coverage.Sources) != 2 {
+			t.Errorf("%s: sources %v, expected 2", style, coverage.Sources)
+		}
+		for fileName := range coverage.sourceFiles {
+			found := false
+			for _, source := range coverage.Sources {
+				_, err := os.Stat(filepath.Join(source.Path, filepath.FromSlash(fileName)))
+				found = found || err == nil
+			}
+			if !found {
+				t.Errorf("%s: no source of %v prefixes %s", style, coverage.Sources, fileName)
+			}
+		}
+	}
+}
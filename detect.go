@@ -0,0 +1,44 @@
+package cobertura
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// magic numbers of the binary coverage data files of GOCOVERDIR
+	covMetaMagic    = []byte{0x00, 0x63, 0x76, 0x6d}
+	covCounterMagic = []byte{0x00, 0x63, 0x77, 0x6d}
+
+	utf8BOM = []byte{0xef, 0xbb, 0xbf}
+)
+
+// detectInputFormat sniffs the beginning of in, without consuming it, and
+// returns the name of its input format.
+func detectInputFormat(in *bufio.Reader) (string, error) {
+	head, err := in.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	if bytes.HasPrefix(head, covMetaMagic) || bytes.HasPrefix(head, covCounterMagic) {
+		return "", fmt.Errorf("binary coverage data, give its directory to '-from-covdir'")
+	}
+
+	text := bytes.TrimLeft(bytes.TrimPrefix(head, utf8BOM), " \t\r\n")
+	switch {
+	case len(text) == 0, bytes.HasPrefix(text, []byte("mode:")):
+		return "go", nil
+	case bytes.HasPrefix(text, []byte("<")):
+		if isJaCoCo(in) {
+			return "jacoco", nil
+		}
+		return "cobertura", nil
+	case bytes.HasPrefix(text, []byte("TN:")), bytes.HasPrefix(text, []byte("SF:")):
+		return "lcov", nil
+	}
+	return "", fmt.Errorf("unknown input format, set '-input-format'")
+}
@@ -0,0 +1,48 @@
+package cobertura
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectInputFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{"mode: set\nexample.com/a.go:1.1,2.2 1 1\n", "go"},
+		{"", "go"},
+		{"\xef\xbb\xbf\r\nmode: count\n", "go"},
+		{"TN:\nSF:a.c\nDA:1,1\nend_of_record\n", "lcov"},
+		{"SF:a.c\nDA:1,1\nend_of_record\n", "lcov"},
+		{`<?xml version="1.0"?>` + "\n" + `<!DOCTYPE coverage SYSTEM "coverage-04.dtd"><coverage/>`, "cobertura"},
+		{jacocoReport, "jacoco"},
+	} {
+		in := bufio.NewReader(strings.NewReader(tc.input))
+		actual, err := detectInputFormat(in)
+		if err != nil {
+			t.Errorf("%q: %v", tc.input, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("%q: detected %s, expected %s", tc.input, actual, tc.expected)
+		}
+		if rest, _ := io.ReadAll(in); string(rest) != tc.input {
+			t.Errorf("%q: the input was consumed", tc.input)
+		}
+	}
+}
+
+func TestDetectInputFormatErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"\x00cvm\x01\x00", "\x00cwm\x01\x00", "hello"} {
+		if _, err := detectInputFormat(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package cobertura
+
+import (
+	"errors"
+	"go/scanner"
+	"log/slog"
+)
+
+// Formats of the warnings and errors logged by the command.
+const (
+	diagnosticsText = "text"
+	diagnosticsJSON = "json"
+)
+
+// fileError is the error of a profile file which cannot be converted.
+type fileError struct {
+	file string
+	err  error
+}
+
+func (e *fileError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
+// diagnose returns why a file cannot be converted and, if known, what would
+// fix it, for the attributes of its warnings and errors.
+func diagnose(err error) (reason, suggestion string) {
+	var kindErr *kindError
+	var syntaxErr scanner.ErrorList
+	switch {
+	case errors.As(err, &kindErr) && kindErr.reason != "":
+		return kindErr.reason, kindErr.suggestion
+	case errors.Is(err, ErrPackageNotFound):
+		return "package not found", "convert from the module the tests ran in"
+	case errors.Is(err, ErrSourceMissing):
+		return "source missing, the profile may be stale", "regenerate the profile from the current sources"
+	case errors.As(err, &syntaxErr):
+		return "unparsable source", "fix the source or skip it with -ignore-files"
+	}
+	return "conversion error", ""
+}
+
+// fileErrors returns the fileErrors found in the tree of err.
+func fileErrors(err error) []*fileError {
+	switch err := err.(type) {
+	case *fileError:
+		return []*fileError{err}
+	case interface{ Unwrap() []error }:
+		var found []*fileError
+		for _, inner := range err.Unwrap() {
+			found = append(found, fileErrors(inner)...)
+		}
+		return found
+	case interface{ Unwrap() error }:
+		return fileErrors(err.Unwrap())
+	}
+	return nil
+}
+
+// logFailure logs the files of the failed conversion which cannot be
+// converted, one record each, or the error itself if it is not about
+// files, for the readers of -diagnostics json.
+func logFailure(logger *slog.Logger, err error) {
+	files := fileErrors(err)
+	if len(files) == 0 {
+		logger.Error("conversion failed", "error", err, "exit_code", ExitCode(err))
+		return
+	}
+	for _, file := range files {
+		reason, suggestion := diagnose(file.err)
+		logger.Error("cannot convert file", "file", file.file, "reason", reason, "suggestion", suggestion, "error", file.err)
+	}
+}
@@ -0,0 +1,91 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticsJSON(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 1
+github.com/franchb/gocover-cobertura/testdata/missing.go:1.1,2.2 1 0
+`
+	var logs bytes.Buffer
+	opts := &Options{KeepGoing: true, Logger: slog.New(slog.NewJSONHandler(&logs, nil))}
+	coverage, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, opts, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("bad record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, expected the skipped file and their count:\n%s", len(records), logs.String())
+	}
+	skipped := records[0]
+	if skipped["msg"] != "skipping file" || skipped["file"] != "github.com/franchb/gocover-cobertura/testdata/missing.go" {
+		t.Errorf("record %v, expected missing.go to be skipped", skipped)
+	}
+	if skipped["reason"] != "source missing, the profile may be stale" || skipped["suggestion"] == "" {
+		t.Errorf("record %v, expected the reason and suggestion of a stale profile", skipped)
+	}
+	if records[1]["msg"] != "skipped files" || records[1]["count"] != 1.0 {
+		t.Errorf("record %v, expected the count of skipped files", records[1])
+	}
+}
+
+func TestLogFailure(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 1
+github.com/franchb/gocover-cobertura/testdata/func1.go:5.23,6.16 1 1
+github.com/franchb/gocover-cobertura/testdata/missing.go:1.1,2.2 1 0
+`
+	_, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var logs bytes.Buffer
+	logFailure(slog.New(slog.NewJSONHandler(&logs, nil)), err)
+
+	reasons := map[string]string{}
+	suggestions := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record struct {
+			Msg, File, Reason, Suggestion string
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("bad record %q: %v", line, err)
+		}
+		if record.Msg != "cannot convert file" {
+			t.Errorf("record %q, expected a file which cannot be converted", line)
+		}
+		reasons[record.File] = record.Reason
+		suggestions[record.File] = record.Suggestion
+	}
+	for file, reason := range map[string]string{
+		"github.com/franchb/gocover-cobertura/testdata/func1.go":   "excluded by build constraints",
+		"github.com/franchb/gocover-cobertura/testdata/missing.go": "source missing, the profile may be stale",
+	} {
+		if reasons[file] != reason {
+			t.Errorf("reason %q for %s, expected %q", reasons[file], file, reason)
+		}
+	}
+	if suggestion := suggestions["github.com/franchb/gocover-cobertura/testdata/func1.go"]; suggestion != "convert with -tags testdata" {
+		t.Errorf("suggestion %q for func1.go, expected the tags including it", suggestion)
+	}
+}
@@ -0,0 +1,126 @@
+package cobertura
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AnnotateDiff converts the profile read from in and prints the unified diff
+// read from patch with the hit count of every line of the new files which
+// holds statements.  With uncoveredOnly, it only prints the added lines
+// without hits, as file:line, for code review tools.
+func AnnotateDiff(in, patch io.Reader, out io.Writer, ignore Matcher, uncoveredOnly bool, buildTags ...string) error {
+	return annotateDiff(in, patch, out, ignore, uncoveredOnly, nil, buildTags)
+}
+
+// annotateDiff is AnnotateDiff, converting with opts.
+func annotateDiff(in, patch io.Reader, out io.Writer, ignore Matcher, uncoveredOnly bool, opts *Options, buildTags []string) error {
+	coverage, err := convert(context.Background(), in, ignore, opts, buildTags)
+	if err != nil {
+		return err
+	}
+	defer coverage.close()
+
+	hits, err := lineHitsByFile(coverage)
+	if err != nil {
+		return err
+	}
+
+	var fileHits map[int]int64
+	fileName := ""
+	newLine := 0
+	scanner := bufio.NewScanner(patch)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		column := ""
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			fileName = diffFileName(line[len("+++ "):])
+			fileHits = nil
+			for name, lines := range hits {
+				if fileName != "" && pathMatches(name, fileName) {
+					fileHits = lines
+					break
+				}
+			}
+		case strings.HasPrefix(line, "@@ "):
+			newLine, err = hunkNewStart(line)
+			if err != nil {
+				return err
+			}
+		case newLine > 0 && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, " ") || line == ""):
+			if count, ok := fileHits[newLine]; ok {
+				column = strconv.FormatInt(count, 10)
+				if uncoveredOnly && count == 0 && strings.HasPrefix(line, "+") {
+					_, _ = fmt.Fprintf(out, "%s:%d\n", fileName, newLine)
+				}
+			}
+			newLine++
+		}
+		if !uncoveredOnly {
+			_, _ = fmt.Fprintf(out, "%6s %s\n", column, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read diff: %w", err)
+	}
+	return nil
+}
+
+// lineHitsByFile returns the hits of the lines of the coverage by class
+// filename.
+func lineHitsByFile(coverage *Coverage) (map[string]map[int]int64, error) {
+	hits := map[string]map[int]int64{}
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			lines, err := class.loadLines()
+			if err != nil {
+				return nil, err
+			}
+			fileHits := hits[class.Filename]
+			if fileHits == nil {
+				fileHits = map[int]int64{}
+				hits[class.Filename] = fileHits
+			}
+			for _, line := range lines {
+				fileHits[line.Number] += line.Hits
+			}
+		}
+	}
+	return hits, nil
+}
+
+// diffFileName returns the path of a "+++" line of a unified diff, without
+// the b/ prefix of git, or "" for a deleted file.
+func diffFileName(name string) string {
+	if tab := strings.IndexByte(name, '\t'); tab >= 0 {
+		name = name[:tab] // timestamp of diff -u
+	}
+	if name == "/dev/null" {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(name, "b/"); ok {
+		return rest
+	}
+	return name
+}
+
+// hunkNewStart returns the first line in the new file of the hunk header
+// "@@ -l,s +l,s @@".
+func hunkNewStart(header string) (int, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0, fmt.Errorf("bad hunk header %q", header)
+	}
+	start, _, _ := strings.Cut(fields[2][1:], ",")
+	line, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, fmt.Errorf("bad hunk header %q", header)
+	}
+	return line, nil
+}
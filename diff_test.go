@@ -0,0 +1,90 @@
+package cobertura
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+const func2Diff = `diff --git a/testdata/func2.go b/testdata/func2.go
+--- a/testdata/func2.go
++++ b/testdata/func2.go
+@@ -9,7 +9,10 @@ func (r Type1) Func2a(arg1 *int) {
+ 	if *arg1 != 0 {
+-		*arg1 = 2
++		*arg1 = 1
+ 	}
+ }
+ 
++func (r *Type1) Func2b(_ *int) {
++}
++
+ func (r *Type1) Func2c(_ *int) {
+ }
+`
+
+func annotateTestdata(t *testing.T, uncoveredOnly bool) string {
+	t.Helper()
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	var out bytes.Buffer
+	if err := AnnotateDiff(in, strings.NewReader(func2Diff), &out, &Ignore{}, uncoveredOnly, "testdata"); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestAnnotateDiff(t *testing.T) {
+	t.Parallel()
+
+	expected := `       diff --git a/testdata/func2.go b/testdata/func2.go
+       --- a/testdata/func2.go
+       +++ b/testdata/func2.go
+       @@ -9,7 +9,10 @@ func (r Type1) Func2a(arg1 *int) {
+     1  	if *arg1 != 0 {
+       -		*arg1 = 2
+     1 +		*arg1 = 1
+     1  	}
+        }
+        
+     0 +func (r *Type1) Func2b(_ *int) {
+     0 +}
+       +
+     0  func (r *Type1) Func2c(_ *int) {
+     0  }
+`
+	if actual := annotateTestdata(t, false); actual != expected {
+		t.Errorf("annotated diff:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestUncoveredDiff(t *testing.T) {
+	t.Parallel()
+
+	expected := "testdata/func2.go:14\ntestdata/func2.go:15\n"
+	if actual := annotateTestdata(t, true); actual != expected {
+		t.Errorf("uncovered lines:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestHunkNewStart(t *testing.T) {
+	t.Parallel()
+
+	for header, expected := range map[string]int{
+		"@@ -9,7 +9,10 @@ func f() {": 9,
+		"@@ -1 +1 @@":                 1,
+		"@@ -0,0 +1,3 @@":             1,
+	} {
+		if actual, err := hunkNewStart(header); err != nil || actual != expected {
+			t.Errorf("hunkNewStart(%q) = %d, %v, expected %d", header, actual, err, expected)
+		}
+	}
+	if _, err := hunkNewStart("@@ -1,2 @@"); err == nil {
+		t.Error("no error for a bad hunk header")
+	}
+}
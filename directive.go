@@ -0,0 +1,120 @@
+package cobertura
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Comment directives excluding source lines from the coverage.
+const (
+	// ignoreDirective ignores the line it ends, or the declaration or
+	// statement following it when on a line of its own.
+	ignoreDirective = "//coverage:ignore"
+	// ignoreStartDirective and ignoreEndDirective ignore the lines between
+	// them, up to the end of the file if the end is missing.
+	ignoreStartDirective = "//gocover:ignore-start"
+	ignoreEndDirective   = "//gocover:ignore-end"
+)
+
+// lineRange is a range of lines, both included.
+type lineRange struct {
+	start, end int
+}
+
+// ignoredRanges returns the ranges of lines of the parsed file, of source
+// data, excluded by comment directives.  The file must be parsed with its
+// comments.
+func ignoredRanges(fset *token.FileSet, parsed *ast.File, data []byte) []lineRange {
+	var ranges []lineRange
+	start := 0
+	for _, group := range parsed.Comments {
+		for _, comment := range group.List {
+			line := fset.Position(comment.Pos()).Line
+			switch {
+			case isDirective(comment.Text, ignoreStartDirective):
+				if start == 0 {
+					start = line
+				}
+			case isDirective(comment.Text, ignoreEndDirective):
+				if start != 0 {
+					ranges = append(ranges, lineRange{start, line})
+					start = 0
+				}
+			case isDirective(comment.Text, ignoreDirective):
+				if endsCode(fset, comment, data) {
+					ranges = append(ranges, lineRange{line, line})
+				} else if node := nodeStartingAt(fset, parsed, fset.Position(group.End()).Line+1); node != nil {
+					ranges = append(ranges, lineRange{fset.Position(node.Pos()).Line, fset.Position(node.End()).Line})
+				}
+			}
+		}
+	}
+	if start != 0 {
+		ranges = append(ranges, lineRange{start, fset.File(parsed.Pos()).LineCount()})
+	}
+	return ranges
+}
+
+// isDirective reports whether the comment text is the directive, possibly
+// followed by an explanation.
+func isDirective(text, directive string) bool {
+	rest, ok := strings.CutPrefix(text, directive)
+	return ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t')
+}
+
+// endsCode reports whether the comment follows code on its line.
+func endsCode(fset *token.FileSet, comment *ast.Comment, data []byte) bool {
+	offset := fset.Position(comment.Pos()).Offset
+	if offset > len(data) {
+		return false
+	}
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	return len(bytes.TrimSpace(data[lineStart:offset])) > 0
+}
+
+// nodeStartingAt returns the outermost declaration or statement of the file
+// starting at line, or nil if none.
+func nodeStartingAt(fset *token.FileSet, parsed *ast.File, line int) ast.Node {
+	var found ast.Node
+	ast.Inspect(parsed, func(node ast.Node) bool {
+		if found != nil || node == nil {
+			return false
+		}
+		if fset.Position(node.End()).Line < line {
+			return false
+		}
+		switch node.(type) {
+		case ast.Decl, ast.Stmt, ast.Spec:
+			if fset.Position(node.Pos()).Line == line {
+				found = node
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// overlaps reports whether the lines from start to end overlap one of the
+// ranges.
+func overlaps(ranges []lineRange, start, end int) bool {
+	for _, r := range ranges {
+		if start <= r.end && end >= r.start {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether the lines from start to end are all in one of
+// the ranges.
+func contains(ranges []lineRange, start, end int) bool {
+	for _, r := range ranges {
+		if start >= r.start && end <= r.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package cobertura
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+)
+
+const directiveSource = `package p
+
+func A(x int) int {
+	if x > 0 { //coverage:ignore
+		return 1
+	}
+	//coverage:ignore unreachable
+	if x < 0 {
+		panic("negative")
+	}
+	return 0
+}
+
+// B is ignored.
+//
+//coverage:ignore
+func B() {
+	println("b")
+}
+
+func C(x int) {
+	//gocover:ignore-start
+	println(x)
+	println(x)
+	//gocover:ignore-end
+	println(x)
+	// coverage:ignore is not a directive with a space.
+	println(x)
+}
+
+func D() {
+	//gocover:ignore-start
+	println("d")
+}
+`
+
+func parseDirectiveSource(t *testing.T) (*token.FileSet, *ast.File) {
+	t.Helper()
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "p.go", directiveSource, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fset, parsed
+}
+
+func TestIgnoredRanges(t *testing.T) {
+	t.Parallel()
+
+	fset, parsed := parseDirectiveSource(t)
+	ranges := ignoredRanges(fset, parsed, []byte(directiveSource))
+	expected := []lineRange{{4, 4}, {8, 10}, {17, 19}, {22, 25}, {32, 34}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("ranges %v, expected %v", ranges, expected)
+	}
+}
+
+func TestFileVisitorIgnoredRanges(t *testing.T) {
+	t.Parallel()
+
+	fset, parsed := parseDirectiveSource(t)
+	profile := &Profile{FileName: "p.go", Mode: "set", Blocks: []ProfileBlock{
+		{StartLine: 3, StartCol: 19, EndLine: 4, EndCol: 11, NumStmt: 1, Count: 1},
+		{StartLine: 4, StartCol: 11, EndLine: 6, EndCol: 3, NumStmt: 1, Count: 0},
+		{StartLine: 8, StartCol: 2, EndLine: 8, EndCol: 11, NumStmt: 1, Count: 1},
+		{StartLine: 8, StartCol: 11, EndLine: 10, EndCol: 3, NumStmt: 1, Count: 0},
+		{StartLine: 11, StartCol: 2, EndLine: 11, EndCol: 10, NumStmt: 1, Count: 1},
+		{StartLine: 17, StartCol: 10, EndLine: 19, EndCol: 2, NumStmt: 1, Count: 0},
+		{StartLine: 21, StartCol: 15, EndLine: 29, EndCol: 2, NumStmt: 5, Count: 1},
+		{StartLine: 31, StartCol: 10, EndLine: 34, EndCol: 2, NumStmt: 1, Count: 0},
+	}}
+	pkg := &Package{Name: "p"}
+	visitor := &fileVisitor{
+		fset:     fset,
+		fileName: "p.go",
+		classes:  make(map[string]*Class),
+		pkg:      pkg,
+		profile:  profile,
+		ignore:   &Ignore{},
+		ignored:  ignoredRanges(fset, parsed, []byte(directiveSource)),
+	}
+	ast.Walk(visitor, parsed)
+
+	methods := map[string][]int{}
+	for _, class := range pkg.Classes {
+		for _, method := range class.Methods {
+			for _, line := range method.Lines {
+				methods[method.Name] = append(methods[method.Name], line.Number)
+			}
+		}
+	}
+	expected := map[string][]int{"A": {11}}
+	if !reflect.DeepEqual(methods, expected) {
+		t.Errorf("methods lines %v, expected %v", methods, expected)
+	}
+}
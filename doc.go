@@ -0,0 +1,48 @@
+// Package cobertura converts the coverage profiles of go test to Cobertura
+// XML reports, as the gocover-cobertura command does.
+//
+// Convert and ConvertWithOptions read a profile and write the report:
+//
+//	err := cobertura.Convert(profile, report, &cobertura.Ignore{GeneratedFiles: true})
+//
+// ConvertContext and ParseProfilesContext stop once their context is done,
+// to cancel long conversions or apply deadlines.  ConvertStream writes the
+// packages as they are converted, for repositories too large to hold their
+// whole coverage in memory.
+//
+// ParseProfiles and Coverage.ParseProfile build the report step by step,
+// the Coverage, Package, Class, Method and Line types being the Cobertura
+// document, which encoding/xml marshals.  MergeProfiles combines the parsed
+// profiles of the shards of a test run before their conversion.
+//
+// A Matcher selects the files left out.  Ignore is the one of the command
+// flags, which MatchAny combines with others, such as a MatcherFunc.
+// Options holds the optional settings, such as the logger, the file system
+// the sources are read from, or the naming of the classes and files of the
+// command flags.  Conversions with different options may run concurrently.
+//
+// Summary returns the covered and valid counts and the rates of a report
+// per package, file and function as plain structs, for badges, checks and
+// dashboards.  Evaluate checks a report against Rules, the minimums and
+// the baseline of -fail-under, -thresholds and -baseline, and returns the
+// violations.
+//
+// Reports from other tools can be built with NewCoverage, AddPackage,
+// AddClass, AddMethod and AddLine, with Line.SetBranches for the branch
+// points, then Coverage.Recompute sets the rates and totals from the lines:
+//
+//	cov := cobertura.NewCoverage("/src")
+//	class := cov.AddPackage("example.com/p").AddClass("T", "p/t.go")
+//	class.AddLine(class.AddMethod("Run", "()"), 12, 3)
+//	cov.Recompute()
+//
+// Formatter writes the report in an output format.  RegisterFormatter adds
+// formats, selectable by name with LookupFormatter and the -format flag.
+//
+// The errors of a kind wrap ErrBadModeLine, ErrPackageNotFound or
+// ErrSourceMissing, or are a ThresholdError, for errors.Is and errors.As.
+//
+// Run is the gocover-cobertura command itself, reading its flags from the
+// command line, and RunArgs runs it with other arguments.  Their flags are
+// parsed apart from the ones of the flag package.
+package cobertura
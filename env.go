@@ -0,0 +1,37 @@
+package cobertura
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// envPrefix prefixes the environment variables setting the flags.
+const envPrefix = "GOCOVER_COBERTURA_"
+
+// envName returns the environment variable setting the named flag, such as
+// GOCOVER_COBERTURA_IGNORE_DIRS for -ignore-dirs.
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// setFlagsFromEnv sets the flags of fs which were not given on the command
+// line from their environment variable, looked up with lookup.
+func setFlagsFromEnv(fs *flag.FlagSet, lookup func(string) (string, bool)) error {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || given[f.Name] {
+			return
+		}
+		name := envName(f.Name)
+		if value, ok := lookup(name); ok {
+			if setErr := fs.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("bad %s: %w", name, setErr)
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,52 @@
+package cobertura
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestSetFlagsFromEnv(t *testing.T) {
+	t.Parallel()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	ignoreDirs := fs.String("ignore-dirs", "", "")
+	tags := fs.String("tags", "", "")
+	verbose := fs.Bool("v", false, "")
+	var from stringList
+	fs.Var(&from, "from", "")
+	if err := fs.Parse([]string{"-tags", "integration"}); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"GOCOVER_COBERTURA_IGNORE_DIRS": "/mocks$",
+		"GOCOVER_COBERTURA_TAGS":        "unit",
+		"GOCOVER_COBERTURA_V":           "true",
+		"GOCOVER_COBERTURA_FROM":        "unit.out,integration.out",
+	}
+	err := setFlagsFromEnv(fs, func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *ignoreDirs != "/mocks$" || !*verbose || from.String() != "unit.out,integration.out" {
+		t.Errorf("flags not set from the environment: %q %v %q", *ignoreDirs, *verbose, from.String())
+	}
+	if *tags != "integration" {
+		t.Errorf("the command line did not take precedence: %q", *tags)
+	}
+
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("v", false, "")
+	err = setFlagsFromEnv(fs, func(name string) (string, bool) {
+		return "maybe", name == "GOCOVER_COBERTURA_V"
+	})
+	if err == nil {
+		t.Error("expected an error for a bad boolean")
+	}
+}
@@ -0,0 +1,103 @@
+package cobertura
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrBadModeLine is the kind of the errors of profiles not starting with
+	// a "mode: " line.
+	ErrBadModeLine = errors.New("bad mode line")
+
+	// ErrPackageNotFound is the kind of the errors of profiles whose package
+	// is not found in a module.
+	ErrPackageNotFound = errors.New("package not found")
+
+	// ErrSourceMissing is the kind of the errors of profiles whose source
+	// file is not among the files of their package or cannot be read.
+	ErrSourceMissing = errors.New("source file missing")
+)
+
+// ThresholdKind is the rule a ThresholdError violates.
+type ThresholdKind int
+
+const (
+	// ThresholdMin is the minimum of the total, as -fail-under.
+	ThresholdMin ThresholdKind = iota
+	// ThresholdPackage is the minimum of a package, as -thresholds.
+	ThresholdPackage
+	// ThresholdBaseline is the drop from the baseline, as -baseline.
+	ThresholdBaseline
+)
+
+// ThresholdError is the error of a line coverage below its threshold, and a
+// violation returned by Evaluate.
+type ThresholdError struct {
+	Kind ThresholdKind
+	// Package is the package below its threshold, "" for the total.
+	Package string
+	// Pattern is the -thresholds pattern of the package.
+	Pattern string
+	// Actual and Required are line coverage percentages, Required being
+	// the Baseline one less the tolerance for a drop from the baseline.
+	Actual, Required, Baseline float64
+}
+
+func (e *ThresholdError) Error() string {
+	switch {
+	case e.Kind == ThresholdBaseline:
+		name := e.Package
+		if name == "" {
+			name = "total"
+		}
+		return fmt.Sprintf("%s: %.2f%% -> %.2f%% (%.2f)", name, e.Baseline, e.Actual, e.Actual-e.Baseline)
+	case e.Package == "":
+		return fmt.Sprintf("line coverage %.2f%% is below the threshold of %.2f%%", e.Actual, e.Required)
+	default:
+		return fmt.Sprintf("%s: %.2f%% < %.2f%% (%s)", e.Package, e.Actual, e.Required, e.Pattern)
+	}
+}
+
+// kindError is an error of a kind, such as ErrSourceMissing, with its own
+// message, and the reason and suggestion of its diagnostics when more
+// precise than those of the kind.
+type kindError struct {
+	kind       error
+	msg        string
+	reason     string
+	suggestion string
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// listError is an error listing several errors in its message, which
+// errors.Is and errors.As find.
+type listError struct {
+	msg  string
+	errs []error
+}
+
+// newListError returns a listError of the header followed by the messages of
+// errs, each after the separator.
+func newListError(header, separator string, errs []error) error {
+	msg := header
+	for _, err := range errs {
+		msg += separator + err.Error()
+	}
+	return &listError{msg: msg, errs: errs}
+}
+
+func (e *listError) Error() string {
+	return e.msg
+}
+
+func (e *listError) Unwrap() []error {
+	return e.errs
+}
@@ -0,0 +1,34 @@
+package cobertura
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	t.Parallel()
+
+	_, err := ParseProfiles(strings.NewReader("no mode line\n"), nil)
+	if !errors.Is(err, ErrBadModeLine) || err.Error() != "bad mode line: no mode line" {
+		t.Errorf("error %v, expected ErrBadModeLine", err)
+	}
+	if code := ExitCode(err); code != exitParse {
+		t.Errorf("bad mode line: exit code %d, expected %d", code, exitParse)
+	}
+
+	err = (&Coverage{}).ParseProfile(&Profile{FileName: "does-not-exist"}, nil, nil)
+	if !errors.Is(err, ErrPackageNotFound) || err.Error() != "package required when using go modules" {
+		t.Errorf("error %v, expected ErrPackageNotFound", err)
+	}
+
+	profile := "mode: set\ngithub.com/franchb/gocover-cobertura/testdata/missing.go:1.1,2.2 1 0\n"
+	_, err = convert(context.Background(), strings.NewReader(profile), nil, &Options{Strict: true}, []string{"testdata"})
+	if !errors.Is(err, ErrSourceMissing) {
+		t.Errorf("error %v, expected ErrSourceMissing", err)
+	}
+	if code := ExitCode(errors.Unwrap(err)); code != exitPackages {
+		t.Errorf("missing source: exit code %d, expected %d", code, exitPackages)
+	}
+}
@@ -0,0 +1,75 @@
+package cobertura
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Exit codes of the failure classes, for CI wrappers to tell a coverage
+// below the thresholds from a broken conversion.
+const (
+	exitFailure   = 1 // any other failure, as writing the report
+	exitUsage     = 2 // bad flags, as the flag package
+	exitInput     = 3 // unreadable profile or report
+	exitParse     = 4 // malformed or empty profile or report
+	exitPackages  = 5 // packages or sources failing to load
+	exitThreshold = 6 // coverage below -fail-under or -thresholds
+)
+
+// exitCodeError is an error with the exit code of its failure class.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode returns err with the exit code, unless err already has one
+// or is nil.
+func withExitCode(code int, err error) error {
+	var coded *exitCodeError
+	if err == nil || errors.As(err, &coded) {
+		return err
+	}
+	return &exitCodeError{code: code, err: err}
+}
+
+// profileExitCode returns the exit code of an error of parseProfiles:
+// exitParse for a malformed profile, exitInput for a failure to read it.
+func profileExitCode(err error) int {
+	var parseErr *profileParseError
+	if errors.As(err, &parseErr) {
+		return exitParse
+	}
+	return exitInput
+}
+
+// usageErrorf formats an error of bad flags.
+func usageErrorf(format string, args ...any) error {
+	return withExitCode(exitUsage, fmt.Errorf(format, args...))
+}
+
+// ExitCode returns the exit code of err, given by the command or else by
+// the kind of err.
+func ExitCode(err error) int {
+	var coded *exitCodeError
+	var threshold *ThresholdError
+	switch {
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, ErrBadModeLine):
+		return exitParse
+	case errors.Is(err, ErrPackageNotFound), errors.Is(err, ErrSourceMissing):
+		return exitPackages
+	case errors.As(err, &threshold):
+		return exitThreshold
+	default:
+		return exitFailure
+	}
+}
@@ -0,0 +1,97 @@
+package cobertura
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("boom")
+	for _, test := range []struct {
+		err      error
+		expected int
+	}{
+		{cause, exitFailure},
+		{withExitCode(exitInput, cause), exitInput},
+		{fmt.Errorf("wrapped: %w", withExitCode(exitPackages, cause)), exitPackages},
+		{withExitCode(exitParse, fmt.Errorf("wrapped: %w", withExitCode(exitPackages, cause))), exitPackages},
+		{usageErrorf("bad '-format' %q", "xml"), exitUsage},
+		{withExitCode(exitThreshold, errors.Join(cause, cause)), exitThreshold},
+	} {
+		if actual := ExitCode(test.err); actual != test.expected {
+			t.Errorf("ExitCode(%v) = %d, expected %d", test.err, actual, test.expected)
+		}
+	}
+
+	if err := withExitCode(exitThreshold, nil); err != nil {
+		t.Errorf("withExitCode(nil) = %v", err)
+	}
+	if err := withExitCode(exitInput, cause); !errors.Is(err, cause) || err.Error() != "boom" {
+		t.Errorf("withExitCode changed the error: %v", err)
+	}
+}
+
+func TestConvertExitCodes(t *testing.T) {
+	t.Parallel()
+
+	for name, in := range map[string]io.Reader{
+		"bad profile":  strings.NewReader("no mode line\n"),
+		"inconsistent": strings.NewReader("mode: set\nexample.com/a.go:1.1,2.2 1 1\nexample.com/a.go:1.1,2.2 2 1\n"),
+		"long line":    strings.NewReader("mode: set\n" + strings.Repeat("x", 100) + "\n"),
+	} {
+		_, err := convert(context.Background(), in, &Ignore{}, &Options{MaxProfileLine: 64}, nil)
+		if code := ExitCode(err); code != exitParse {
+			t.Errorf("%s: exit code %d, expected %d (%v)", name, code, exitParse, err)
+		}
+	}
+
+	_, err := convert(context.Background(), iotest.ErrReader(fs.ErrPermission), &Ignore{}, nil, nil)
+	if code := ExitCode(err); code != exitInput || !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("unreadable profile: exit code %d, expected %d (%v)", code, exitInput, err)
+	}
+	if err := Check(iotest.ErrReader(fs.ErrPermission), io.Discard, &Ignore{}); ExitCode(err) != exitInput {
+		t.Errorf("unreadable profile checked: exit code %d, expected %d (%v)", ExitCode(err), exitInput, err)
+	}
+
+	profile := "mode: set\ngithub.com/franchb/gocover-cobertura/testdata/func2.go:8.34,9.16 1 1\n"
+	_, err = convert(context.Background(), strings.NewReader(profile), &Ignore{}, nil, nil)
+	if code := ExitCode(err); code != exitPackages {
+		t.Errorf("package excluded by build tags: exit code %d, expected %d (%v)", code, exitPackages, err)
+	}
+}
+
+func TestRunArgsUsageErrors(t *testing.T) {
+	t.Parallel()
+
+	// the flags of each run are parsed apart, so runs do not collide
+	for _, args := range [][]string{
+		{"-class-naming", "type"},
+		{"-statements", "-merge", "other.xml"},
+		{"explain"},
+		{"explain", "a.go:1", "b.go:2"},
+		{"suggest-ignores", "a", "b"},
+		{"annotate-diff", "a.patch", "b.patch"},
+	} {
+		if code := ExitCode(RunArgs(args)); code != exitUsage {
+			t.Errorf("%v: exit code %d, expected %d", args, code, exitUsage)
+		}
+	}
+}
+
+func TestConvertUnknownOptions(t *testing.T) {
+	t.Parallel()
+
+	profile := "mode: set\ngithub.com/franchb/gocover-cobertura/testdata/func2.go:8.34,9.16 1 1\n"
+	_, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{ClassNaming: "type"}, []string{"testdata"})
+	if code := ExitCode(err); code != exitUsage || !strings.Contains(err.Error(), "Options.ClassNaming") {
+		t.Errorf("unknown class naming: exit code %d, expected %d (%v)", code, exitUsage, err)
+	}
+}
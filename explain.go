@@ -0,0 +1,112 @@
+package cobertura
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Explain prints the profile blocks covering the target position, given as
+// file.go:line, along with the class and method the converter attributed
+// the line to.  It is a debugging aid for lines reported as uncovered.
+func Explain(in io.Reader, out io.Writer, ignore Matcher, target string, buildTags ...string) error {
+	return explain(in, out, ignore, target, nil, buildTags)
+}
+
+// explain is Explain, converting with opts.
+func explain(in io.Reader, out io.Writer, ignore Matcher, target string, opts *Options, buildTags []string) error {
+	fileName, line, err := parseTarget(target)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := parseProfiles(context.Background(), in, ignore, opts)
+	if err != nil {
+		return err
+	}
+
+	coverage, err := convertProfiles(context.Background(), profiles, ignore, buildTags, opts)
+	if err != nil {
+		return err
+	}
+	defer coverage.close()
+
+	found := false
+	for _, profile := range profiles {
+		if !pathMatches(profile.FileName, fileName) {
+			continue
+		}
+		for _, block := range profile.Blocks {
+			if line < block.StartLine || line > block.EndLine {
+				continue
+			}
+			if !found {
+				_, _ = fmt.Fprintf(out, "profile blocks covering %s:\n", target)
+				found = true
+			}
+			_, _ = fmt.Fprintf(out, "  %s:%d.%d,%d.%d statements=%d count=%d\n",
+				profile.FileName, block.StartLine, block.StartCol, block.EndLine, block.EndCol,
+				block.NumStmt, block.Count)
+		}
+	}
+	if !found {
+		_, _ = fmt.Fprintf(out, "no profile block covers %s\n", target)
+	}
+
+	attributed := false
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			if !pathMatches(class.Filename, fileName) {
+				continue
+			}
+			for _, method := range class.Methods {
+				lines, err := method.loadLines()
+				if err != nil {
+					return err
+				}
+				for _, l := range lines {
+					if l.Number != line {
+						continue
+					}
+					if !attributed {
+						_, _ = fmt.Fprintln(out, "attributed to:")
+						attributed = true
+					}
+					_, _ = fmt.Fprintf(out, "  package %s, class %s, method %s: hits=%d\n",
+						pkg.Name, class.Name, method.Name, l.Hits)
+				}
+			}
+		}
+	}
+	if !attributed {
+		_, _ = fmt.Fprintf(out, "%s is not attributed to any method\n", target)
+	}
+
+	return nil
+}
+
+func parseTarget(target string) (string, int, error) {
+	index := strings.LastIndex(target, ":")
+	if index <= 0 {
+		return "", 0, fmt.Errorf("bad target %q, expected file.go:line", target)
+	}
+	line, err := strconv.Atoi(target[index+1:])
+	if err != nil || line <= 0 {
+		return "", 0, fmt.Errorf("bad line number in target %q", target)
+	}
+	return filepath.ToSlash(target[:index]), line, nil
+}
+
+// pathMatches reports whether one of the slash separated paths is a suffix
+// of the other, on a path element boundary.
+func pathMatches(a, b string) bool {
+	a = filepath.ToSlash(a)
+	b = filepath.ToSlash(b)
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	return a == b || strings.HasSuffix(a, "/"+b)
+}
@@ -0,0 +1,47 @@
+package cobertura
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	var out strings.Builder
+	if err := Explain(in, &out, &Ignore{}, "testdata/func2.go:9", "testdata"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"github.com/franchb/gocover-cobertura/testdata/func2.go:8.34,9.16 statements=1 count=1",
+		"github.com/franchb/gocover-cobertura/testdata/func2.go:9.16,11.3 statements=1 count=1",
+		"class Type1, method Func2a: hits=1",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("explain output does not contain %q:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	t.Parallel()
+
+	for _, target := range []string{"func2.go", "func2.go:", "func2.go:x", ":3", "func2.go:0"} {
+		if _, _, err := parseTarget(target); err == nil {
+			t.Errorf("parseTarget(%q) should fail", target)
+		}
+	}
+
+	fileName, line, err := parseTarget("testdata/func2.go:12")
+	if err != nil || fileName != "testdata/func2.go" || line != 12 {
+		t.Errorf("parseTarget returned %s, %d, %v", fileName, line, err)
+	}
+}
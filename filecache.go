@@ -0,0 +1,198 @@
+package cobertura
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileCacheVersion is changed with the layout of the cache entries or the
+// way files are converted, so that the entries of other versions are
+// missed.
+const fileCacheVersion = 2
+
+// fileCache holds the classes of the files converted by earlier
+// conversions, keyed by everything they are built from, so that unchanged
+// files are neither parsed nor walked again.  A nil fileCache caches
+// nothing.
+type fileCache struct {
+	dir      string
+	logger   *slog.Logger
+	warnOnce sync.Once
+}
+
+func newFileCache(dir string, logger *slog.Logger) *fileCache {
+	if dir == "" {
+		return nil
+	}
+	return &fileCache{dir: dir, logger: logger}
+}
+
+// fileCacheEntry is a cache file: the classes of a converted file.
+type fileCacheEntry struct {
+	Version int
+	Classes []cachedClass
+}
+
+// cachedClass holds a Class, whose lines are the ones of its methods and
+// whose rates are computed from them.
+type cachedClass struct {
+	Name     string
+	Filename string
+	Methods  []cachedMethod
+}
+
+// cachedMethod holds a Method, whose rates are computed from its lines.
+type cachedMethod struct {
+	Name       string
+	Signature  string
+	Line       int
+	Lines      []cachedLine
+	Statements *[2]int64 `json:",omitempty"` // covered and valid
+}
+
+// cachedLine holds a Line, whose branch attributes are set from its
+// outcomes.
+type cachedLine struct {
+	Number   int
+	Hits     int64
+	Branches *[2]int `json:",omitempty"` // covered and valid
+}
+
+// fileCacheKey returns the key of the classes of the file of the profile,
+// of source data, converted with classFileName and pkgName, or false if
+// they cannot be cached: only the behaviour of Ignore, or of no matcher,
+// is known to the key.
+func fileCacheKey(profile *Profile, data []byte, classFileName, pkgName string, ignore Matcher, opts *Options) (string, bool) {
+	funcs := ""
+	switch ignore := ignore.(type) {
+	case nil:
+	case *Ignore:
+		if ignore != nil && ignore.Funcs != nil {
+			funcs = ignore.Funcs.String()
+		}
+	default:
+		return "", false
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "version %d\nfile %s\npackage %s\nfuncs %q\n", fileCacheVersion, classFileName, pkgName, funcs)
+	fmt.Fprintf(hash, "options %t %s %s %t %t %t %t\n", opts.byFiles(), opts.genericReceivers(), opts.classNaming(),
+		opts.methodSignatures(), opts.qualifiedMethods(), opts.statements(), opts.initClass())
+	fmt.Fprintf(hash, "mode %s\n", profile.Mode)
+	var buf []byte
+	for _, block := range profile.Blocks {
+		for _, number := range []int{block.StartLine, block.StartCol, block.EndLine, block.EndCol, block.NumStmt, block.Count} {
+			buf = binary.AppendVarint(buf, int64(number))
+		}
+	}
+	_, _ = hash.Write(buf)
+	fmt.Fprintf(hash, "\nsource %x\n", sha256.Sum256(data))
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+// get returns the cached classes of the key, or nil if there are none.
+func (c *fileCache) get(key string) []*Class {
+	if c == nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return nil
+	}
+	var entry fileCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != fileCacheVersion {
+		return nil
+	}
+	classes := make([]*Class, 0, len(entry.Classes))
+	for _, cached := range entry.Classes {
+		class := &Class{Name: cached.Name, Filename: cached.Filename, Methods: []*Method{}, Lines: []*Line{}}
+		for _, cachedMethod := range cached.Methods {
+			method := &Method{Name: cachedMethod.Name, Signature: cachedMethod.Signature, line: cachedMethod.Line, Lines: []*Line{}}
+			for _, cachedLine := range cachedMethod.Lines {
+				line := &Line{Number: cachedLine.Number, Hits: cachedLine.Hits}
+				if cachedLine.Branches != nil {
+					line.SetBranches(cachedLine.Branches[0], cachedLine.Branches[1])
+				}
+				method.Lines = append(method.Lines, line)
+			}
+			if cachedMethod.Statements != nil {
+				method.statements = &statementCount{covered: cachedMethod.Statements[0], valid: cachedMethod.Statements[1]}
+			}
+			method.LineRate = method.HitRate()
+			method.BranchRate = branchRate(method.Lines.NumBranchesCovered(), method.Lines.NumBranches())
+			class.Methods = append(class.Methods, method)
+			class.Lines = append(class.Lines, method.Lines...)
+		}
+		class.LineRate = class.HitRate()
+		class.BranchRate = branchRate(class.NumBranchesCovered(), class.NumBranches())
+		classes = append(classes, class)
+	}
+	return classes
+}
+
+// put caches the classes of the key, warning once if they cannot be
+// written.
+func (c *fileCache) put(key string, classes []*Class) {
+	if c == nil {
+		return
+	}
+	entry := fileCacheEntry{Version: fileCacheVersion, Classes: make([]cachedClass, 0, len(classes))}
+	for _, class := range classes {
+		cached := cachedClass{Name: class.Name, Filename: class.Filename}
+		for _, method := range class.Methods {
+			cachedMethod := cachedMethod{Name: method.Name, Signature: method.Signature, Line: method.line}
+			for _, line := range method.Lines {
+				cachedLine := cachedLine{Number: line.Number, Hits: line.Hits}
+				if covered, valid := line.branchCounts(); valid > 0 {
+					cachedLine.Branches = &[2]int{covered, valid}
+				}
+				cachedMethod.Lines = append(cachedMethod.Lines, cachedLine)
+			}
+			if method.statements != nil {
+				cachedMethod.Statements = &[2]int64{method.statements.covered, method.statements.valid}
+			}
+			cached.Methods = append(cached.Methods, cachedMethod)
+		}
+		entry.Classes = append(entry.Classes, cached)
+	}
+	data, err := json.Marshal(&entry)
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(c.dir, key+".json"), data)
+	}
+	if err != nil {
+		c.warnOnce.Do(func() {
+			c.logger.Warn("cannot write the file cache", "dir", c.dir, "error", err)
+		})
+	}
+}
+
+// writeFileAtomic writes the file through a temporary file renamed over
+// it, so that concurrent conversions never read a partial file, creating
+// its directory if needed.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*.json")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
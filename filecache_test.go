@@ -0,0 +1,100 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestConvertFileCache(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	convertCached := func(ignore *Ignore) ([]byte, string) {
+		t.Helper()
+		in, err := os.Open("testdata/testdata_set.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer in.Close()
+
+		var logs bytes.Buffer
+		opts := &Options{FileCache: cacheDir, Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+		coverage, err := convert(context.Background(), in, ignore, opts, []string{"testdata"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer coverage.close()
+		coverage.Timestamp = 0
+
+		var out bytes.Buffer
+		if err := coverage.writeXML(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out.Bytes(), logs.String()
+	}
+
+	converted, logs := convertCached(&Ignore{})
+	if strings.Contains(logs, "reusing cached file") {
+		t.Errorf("cached files reused on the first conversion:\n%s", logs)
+	}
+	cached, logs := convertCached(&Ignore{})
+	if !strings.Contains(logs, `msg="reusing cached file"`) || strings.Contains(logs, "cannot write") {
+		t.Errorf("no cached file reused on the second conversion:\n%s", logs)
+	}
+	if !bytes.Equal(cached, converted) {
+		t.Errorf("report from the cached files:\n%s\nexpected:\n%s", cached, converted)
+	}
+
+	if _, logs := convertCached(&Ignore{Funcs: regexp.MustCompile(`^Func1$`)}); strings.Contains(logs, "reusing cached file") {
+		t.Errorf("cached files reused with other ignored functions:\n%s", logs)
+	}
+}
+
+func TestFileCacheKey(t *testing.T) {
+	t.Parallel()
+
+	profile := &Profile{FileName: "example.com/p/p.go", Mode: "set", Blocks: []ProfileBlock{
+		{StartLine: 3, StartCol: 13, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 1},
+	}}
+	other := &Profile{FileName: "example.com/p/p.go", Mode: "set", Blocks: []ProfileBlock{
+		{StartLine: 3, StartCol: 13, EndLine: 5, EndCol: 2, NumStmt: 1, Count: 0},
+	}}
+	source := []byte("package p\n")
+
+	key, ok := fileCacheKey(profile, source, "p.go", "example.com/p", &Ignore{}, nil)
+	if !ok {
+		t.Fatal("no key with Ignore")
+	}
+	for name, changed := range map[string]func() (string, bool){
+		"source": func() (string, bool) {
+			return fileCacheKey(profile, []byte("package q\n"), "p.go", "example.com/p", &Ignore{}, nil)
+		},
+		"blocks":   func() (string, bool) { return fileCacheKey(other, source, "p.go", "example.com/p", &Ignore{}, nil) },
+		"filename": func() (string, bool) { return fileCacheKey(profile, source, "p/p.go", "example.com/p", &Ignore{}, nil) },
+		"package":  func() (string, bool) { return fileCacheKey(profile, source, "p.go", "p", &Ignore{}, nil) },
+		"options": func() (string, bool) {
+			return fileCacheKey(profile, source, "p.go", "example.com/p", &Ignore{}, &Options{ByFiles: true})
+		},
+		"ignored funcs": func() (string, bool) {
+			return fileCacheKey(profile, source, "p.go", "example.com/p", &Ignore{Funcs: regexp.MustCompile("F")}, nil)
+		},
+	} {
+		if changedKey, ok := changed(); !ok || changedKey == key {
+			t.Errorf("same key %s, or none, with another %s", changedKey, name)
+		}
+	}
+	if sameKey, _ := fileCacheKey(profile, source, "p.go", "example.com/p", nil, nil); sameKey != key {
+		t.Errorf("key %s without matcher, expected the one of an empty Ignore %s", sameKey, key)
+	}
+
+	for _, matcher := range []Matcher{MatchAny(&Ignore{}), MatcherFunc(func(string, []byte) bool { return false })} {
+		if _, ok := fileCacheKey(profile, source, "p.go", "example.com/p", matcher, nil); ok {
+			t.Errorf("key with the matcher %T, whose behaviour is unknown", matcher)
+		}
+	}
+}
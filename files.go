@@ -0,0 +1,49 @@
+package cobertura
+
+import (
+	"sort"
+)
+
+// fileCoverage holds the coverage of a single source file, merged across the
+// classes declared in it.
+type fileCoverage struct {
+	Package  string
+	Filename string
+	Methods  []*Method
+	Lines    Lines // sorted by line number
+}
+
+// files returns the coverage of every source file, in report order.
+func (cov *Coverage) files() ([]*fileCoverage, error) {
+	files := []*fileCoverage{}
+	for _, pkg := range cov.Packages {
+		byName := map[string]*fileCoverage{}
+		seen := map[string]map[int]bool{}
+		for _, class := range pkg.Classes {
+			file := byName[class.Filename]
+			if file == nil {
+				file = &fileCoverage{Package: pkg.Name, Filename: class.Filename, Lines: Lines{}}
+				byName[class.Filename] = file
+				seen[class.Filename] = map[int]bool{}
+				files = append(files, file)
+			}
+			file.Methods = append(file.Methods, class.Methods...)
+
+			lines, err := class.loadLines()
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range lines {
+				if seen[class.Filename][line.Number] {
+					continue
+				}
+				seen[class.Filename][line.Number] = true
+				file.Lines = append(file.Lines, line)
+			}
+		}
+	}
+	for _, file := range files {
+		sort.SliceStable(file.Lines, func(i, j int) bool { return file.Lines[i].Number < file.Lines[j].Number })
+	}
+	return files, nil
+}
@@ -0,0 +1,72 @@
+package cobertura
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// Formatter writes a coverage report in an output format.
+type Formatter interface {
+	Write(out io.Writer, cov *Coverage) error
+}
+
+// FormatterFunc is a function writing a coverage report, as a Formatter.
+type FormatterFunc func(out io.Writer, cov *Coverage) error
+
+// Write calls f(out, cov).
+func (f FormatterFunc) Write(out io.Writer, cov *Coverage) error {
+	return f(out, cov)
+}
+
+// formatters are the output formats by name, selectable with -format.
+var formatters = struct {
+	sync.RWMutex
+	byName map[string]Formatter
+}{byName: map[string]Formatter{
+	"cobertura":  FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writeXML(out) }),
+	"clover":     FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writeClover(out) }),
+	"sonarqube":  FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writeSonarQube(out) }),
+	"markdown":   FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writeMarkdown(out) }),
+	"coveralls":  FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writeCoveralls(out) }),
+	"teamcity":   FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writeTeamCity(out) }),
+	"func":       FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writeFunc(out) }),
+	"csv":        FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writeCSV(out) }),
+	"prometheus": FormatterFunc(func(out io.Writer, cov *Coverage) error { return cov.writePrometheus(out) }),
+	"summary": FormatterFunc(func(out io.Writer, cov *Coverage) error {
+		return cov.writeSummary(out, colorOutput(out))
+	}),
+}}
+
+// RegisterFormatter registers the formatter of an output format, selectable
+// by name with -format and LookupFormatter, replacing the formatter already
+// registered under the name, built-in ones included.  It panics if the name
+// is empty or the formatter nil.
+func RegisterFormatter(name string, formatter Formatter) {
+	if name == "" || formatter == nil {
+		panic("cobertura: RegisterFormatter needs a name and a formatter")
+	}
+	formatters.Lock()
+	defer formatters.Unlock()
+	formatters.byName[name] = formatter
+}
+
+// LookupFormatter returns the formatter registered under the name.
+func LookupFormatter(name string) (Formatter, bool) {
+	formatters.RLock()
+	defer formatters.RUnlock()
+	formatter, ok := formatters.byName[name]
+	return formatter, ok
+}
+
+// Formatters returns the sorted names of the registered output formats.
+func Formatters() []string {
+	formatters.RLock()
+	defer formatters.RUnlock()
+	names := make([]string, 0, len(formatters.byName))
+	for name := range formatters.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
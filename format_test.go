@@ -0,0 +1,40 @@
+package cobertura
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRegisterFormatter(t *testing.T) {
+	t.Parallel()
+
+	RegisterFormatter("test-lines", FormatterFunc(func(out io.Writer, cov *Coverage) error {
+		_, err := io.WriteString(out, strings.Repeat("x", int(cov.NumLines())))
+		return err
+	}))
+
+	formatter, ok := LookupFormatter("test-lines")
+	if !ok {
+		t.Fatal("registered formatter not found")
+	}
+	cov := NewCoverage()
+	class := cov.AddPackage("p").AddClass("T", "p/t.go")
+	class.AddLine(class.AddMethod("F", ""), 1, 1)
+	var out bytes.Buffer
+	if err := formatter.Write(&out, cov); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "x" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	names := strings.Join(Formatters(), " ")
+	if !strings.Contains(names, "cobertura") || !strings.Contains(names, "test-lines") {
+		t.Errorf("formatters %s, expected the built-in and registered ones", names)
+	}
+	if _, ok := LookupFormatter("unknown"); ok {
+		t.Error("unknown formatter found")
+	}
+}
@@ -0,0 +1,63 @@
+package cobertura
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// readSource reads the source file at the absolute host path from fsys, or
+// from the host file system if fsys is nil, sanitized by sanitizeSource.
+func readSource(fsys fs.FS, path string) ([]byte, error) {
+	var data []byte
+	var err error
+	if fsys == nil {
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = fs.ReadFile(fsys, fsPath(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sanitizeSource(data), nil
+}
+
+// sanitizeSource returns the source with the bytes of invalid UTF-8, as
+// Latin-1 comments, replaced by '?', so that the parser accepts it and the
+// names and lines taken from it are valid in the reports.  The offsets of
+// the source, and so the columns of the profile, are kept.
+func sanitizeSource(data []byte) []byte {
+	if utf8.Valid(data) {
+		return data
+	}
+	clean := make([]byte, 0, len(data))
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			clean = append(clean, '?')
+		} else {
+			clean = append(clean, data[:size]...)
+		}
+		data = data[size:]
+	}
+	return clean
+}
+
+// fsPath returns the io/fs path of an absolute host path: its slash
+// separated form without the volume name and the leading slash.
+func fsPath(path string) string {
+	path = filepath.ToSlash(strings.TrimPrefix(path, filepath.VolumeName(path)))
+	return strings.TrimPrefix(path, "/")
+}
+
+// read returns the content of the source file.
+func (source sourceFile) read() ([]byte, error) {
+	src, err := readSource(source.fsys, source.path)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s: %w", source.path, err)
+	}
+	return src, nil
+}
@@ -0,0 +1,123 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"unicode/utf8"
+)
+
+func TestFSPath(t *testing.T) {
+	t.Parallel()
+
+	if actual := fsPath(filepath.FromSlash("/home/ci/src/mod/main.go")); actual != "home/ci/src/mod/main.go" {
+		t.Errorf("fsPath %q, expected home/ci/src/mod/main.go", actual)
+	}
+}
+
+func TestConvertFS(t *testing.T) {
+	t.Parallel()
+
+	dir, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := fstest.MapFS{}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		snapshot[fsPath(name)] = &fstest.MapFile{Data: data}
+	}
+
+	convertFS := func(fsys fstest.MapFS) error {
+		in, err := os.Open("testdata/testdata_set.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer in.Close()
+		coverage, err := convert(context.Background(), in, &Ignore{}, &Options{FS: fsys}, []string{"testdata"})
+		if err == nil {
+			coverage.close()
+		}
+		return err
+	}
+	if err := convertFS(snapshot); err != nil {
+		t.Errorf("conversion from a snapshot of the sources: %v", err)
+	}
+	if err := convertFS(fstest.MapFS{}); err == nil {
+		t.Error("expected an error for sources missing from the file system")
+	}
+}
+
+func TestSanitizeSource(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		source, expected string
+	}{
+		{"package p\n", "package p\n"},
+		{"\uFEFFpackage p\n", "\uFEFFpackage p\n"},
+		{"package p // caf\xe9\n", "package p // caf?\n"},
+		{"\uFEFFpackage p // d\xe9j\xe0 vu, \u00e9t\u00e9\n", "\uFEFFpackage p // d?j? vu, \u00e9t\u00e9\n"},
+	} {
+		actual := sanitizeSource([]byte(test.source))
+		if string(actual) != test.expected {
+			t.Errorf("sanitizeSource(%q) = %q, expected %q", test.source, actual, test.expected)
+		}
+	}
+}
+
+func TestConvertEncodings(t *testing.T) {
+	t.Parallel()
+
+	path, err := filepath.Abs("testdata/func4.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the lines of the fixtures are those of func4.go
+	latin1 := append([]byte(byteOrderMark), bytes.Replace(data, []byte("package testdata\n"), []byte("package testdata // r\xe9sum\xe9\n"), 1)...)
+	generated := append([]byte(byteOrderMark), bytes.Replace(data, []byte("//go:build testdata\n"), []byte("// Code generated by hand. DO NOT EDIT.\n"), 1)...)
+
+	convertSource := func(source []byte) *Coverage {
+		t.Helper()
+		profile := "mode: set\ngithub.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 1\n"
+		fsys := fstest.MapFS{fsPath(path): &fstest.MapFile{Data: source}}
+		coverage, err := convert(context.Background(), strings.NewReader(profile), &Ignore{GeneratedFiles: true}, &Options{FS: fsys}, []string{"testdata"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(coverage.close)
+		return coverage
+	}
+
+	expected := convertSource(data)
+	coverage := convertSource(latin1)
+	if coverage.LinesValid != expected.LinesValid || coverage.LinesValid == 0 {
+		t.Errorf("%d lines with a byte order mark and a Latin-1 comment, expected %d", coverage.LinesValid, expected.LinesValid)
+	}
+	var buf bytes.Buffer
+	if err := coverage.writeXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !utf8.Valid(buf.Bytes()) || !strings.Contains(buf.String(), `name="Func4"`) {
+		t.Errorf("bad report of the Latin-1 source:\n%s", buf.String())
+	}
+
+	if coverage := convertSource(generated); coverage.LinesValid != 0 {
+		t.Errorf("%d lines of a generated file starting with a byte order mark, expected it to be ignored", coverage.LinesValid)
+	}
+}
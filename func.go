@@ -0,0 +1,33 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// writeFunc writes the line coverage of every function followed by the
+// total, laid out like the output of go tool cover -func.
+func (cov *Coverage) writeFunc(out io.Writer) error {
+	tabber := tabwriter.NewWriter(out, 1, 8, 1, '\t', 0)
+	for _, pkg := range cov.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				_, _ = fmt.Fprintf(tabber, "%s:%d:\t%s\t%.1f%%\n",
+					class.Filename, method.line, method.Name,
+					funcPercent(method.NumLinesWithHits(), method.NumLines()))
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(tabber, "total:\t(lines)\t%.1f%%\n", funcPercent(cov.LinesCovered, cov.LinesValid))
+	return tabber.Flush()
+}
+
+// funcPercent returns covered/valid as a percentage, 0 when there is nothing
+// to cover, as go tool cover does.
+func funcPercent(covered, valid int64) float64 {
+	if valid == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(valid)
+}
@@ -0,0 +1,64 @@
+package cobertura
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteFunc(t *testing.T) {
+	t.Parallel()
+
+	coverage := Coverage{
+		LinesCovered: 2,
+		LinesValid:   3,
+		Packages: []*Package{{Classes: []*Class{
+			{Filename: "foo/foo.go", Methods: []*Method{
+				{Name: "Foo", line: 3, Lines: Lines{{Number: 4, Hits: 1}, {Number: 5, Hits: 1}}},
+				{Name: "Empty", line: 8, Lines: Lines{}},
+			}},
+			{Filename: "foo/bar.go", Methods: []*Method{
+				{Name: "Bar", line: 12, Lines: Lines{{Number: 13, Hits: 0}}},
+			}},
+		}}},
+	}
+
+	var out strings.Builder
+	if err := coverage.writeFunc(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `foo/foo.go:3:	Foo	100.0%
+foo/foo.go:8:	Empty	0.0%
+foo/bar.go:12:	Bar	0.0%
+total:		(lines)	66.7%
+`
+	if out.String() != expected {
+		t.Errorf("unexpected function summary:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestWriteFuncDeclarationLines(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	coverage, err := convert(context.Background(), in, &Ignore{GeneratedFiles: true}, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	var out strings.Builder
+	if err := coverage.writeFunc(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "testdata/func2.go:8:\tFunc2a\t\t100.0%\n") {
+		t.Errorf("missing the declaration line of Func2a:\n%s", out.String())
+	}
+}
@@ -0,0 +1,36 @@
+package cobertura
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gunzipReader reads its input decompressed if it is gzip compressed, which
+// is detected from its first bytes on the first read.
+type gunzipReader struct {
+	in  io.Reader
+	out io.Reader
+}
+
+func newGunzipReader(in io.Reader) io.Reader {
+	return &gunzipReader{in: in}
+}
+
+func (r *gunzipReader) Read(p []byte) (int, error) {
+	if r.out == nil {
+		buffered := bufio.NewReader(r.in)
+		r.out = buffered
+		if magic, _ := buffered.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+			gz, err := gzip.NewReader(buffered)
+			if err != nil {
+				return 0, err
+			}
+			r.out = gz
+		}
+	}
+	return r.out.Read(p)
+}
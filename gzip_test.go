@@ -0,0 +1,45 @@
+package cobertura
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGunzipReader(t *testing.T) {
+	t.Parallel()
+
+	const profile = "mode: set\nexample.com/a.go:1.1,2.2 1 1\n"
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write([]byte(profile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, in := range map[string]io.Reader{
+		"plain":      strings.NewReader(profile),
+		"compressed": &compressed,
+	} {
+		data, err := io.ReadAll(newGunzipReader(in))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if string(data) != profile {
+			t.Errorf("%s: unexpected content %q", name, data)
+		}
+	}
+}
+
+func TestGunzipReaderCorrupted(t *testing.T) {
+	t.Parallel()
+
+	if _, err := io.ReadAll(newGunzipReader(bytes.NewReader([]byte{0x1f, 0x8b, 0}))); err == nil {
+		t.Error("expected an error for a corrupted gzip stream")
+	}
+}
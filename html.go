@@ -0,0 +1,184 @@
+package cobertura
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+const htmlStyle = `
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.8em; text-align: left; }
+td.rate { text-align: right; }
+pre { background: black; color: rgb(80, 80, 80); padding: 1em; }
+.cov0 { color: rgb(192, 0, 0); }
+.cov1 { color: rgb(128, 128, 128); }
+.cov2 { color: rgb(116, 140, 131); }
+.cov3 { color: rgb(104, 152, 134); }
+.cov4 { color: rgb(92, 164, 137); }
+.cov5 { color: rgb(80, 176, 140); }
+.cov6 { color: rgb(68, 188, 143); }
+.cov7 { color: rgb(56, 200, 146); }
+.cov8 { color: rgb(44, 212, 149); }
+.cov9 { color: rgb(32, 224, 152); }
+.cov10 { color: rgb(20, 236, 155); }
+`
+
+var htmlIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage report</title>
+<style>` + htmlStyle + `</style>
+</head>
+<body>
+<h1>Coverage report</h1>
+<p>Total: {{.Rate}} ({{.Covered}}/{{.Valid}} lines)</p>
+{{range .Packages}}
+<h2>{{.Name}}: {{.Rate}}</h2>
+<table>
+<tr><th>File</th><th>Lines covered</th><th>Lines valid</th><th>Coverage</th></tr>
+{{range .Files}}<tr><td>{{if .Page}}<a href="{{.Page}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</td><td class="rate">{{.Covered}}</td><td class="rate">{{.Valid}}</td><td class="rate">{{.Rate}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+var htmlFileTemplate = template.Must(template.New("file").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>` + htmlStyle + `</style>
+</head>
+<body>
+<p><a href="index.html">index</a></p>
+<h1>{{.Name}}: {{.Rate}}</h1>
+<pre>{{.Source}}</pre>
+</body>
+</html>
+`))
+
+type htmlSummary struct {
+	Name    string
+	Page    string
+	Covered int64
+	Valid   int64
+	Rate    string
+}
+
+type htmlPackage struct {
+	htmlSummary
+	Files []*htmlSummary
+}
+
+type htmlIndex struct {
+	htmlSummary
+	Packages []*htmlPackage
+}
+
+type htmlFile struct {
+	htmlSummary
+	Source template.HTML
+}
+
+func newHTMLSummary(name string, covered, valid int64) htmlSummary {
+	return htmlSummary{Name: name, Covered: covered, Valid: valid, Rate: percent(covered, valid)}
+}
+
+// writeHTML writes an HTML report to dir: an index page with per-package and
+// per-file coverage tables, and a colorized source page per file.
+func (cov *Coverage) writeHTML(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	files, err := cov.files()
+	if err != nil {
+		return err
+	}
+
+	index := htmlIndex{htmlSummary: newHTMLSummary("", cov.LinesCovered, cov.LinesValid)}
+	var pkg *htmlPackage
+	for number, file := range files {
+		if pkg == nil || pkg.Name != file.Package {
+			pkg = &htmlPackage{htmlSummary: htmlSummary{Name: file.Package}}
+			index.Packages = append(index.Packages, pkg)
+		}
+
+		summary := newHTMLSummary(file.Filename, file.Lines.NumLinesWithHits(), file.Lines.NumLines())
+		if source, ok := cov.sourceFiles[file.Filename]; ok {
+			summary.Page = fmt.Sprintf("file%d.html", number)
+			if err := writeHTMLFile(filepath.Join(dir, summary.Page), summary, source); err != nil {
+				return err
+			}
+		}
+		pkg.Files = append(pkg.Files, &summary)
+		pkg.Covered += summary.Covered
+		pkg.Valid += summary.Valid
+	}
+	for _, pkg := range index.Packages {
+		pkg.htmlSummary = newHTMLSummary(pkg.Name, pkg.Covered, pkg.Valid)
+	}
+
+	return writeHTMLTemplate(filepath.Join(dir, "index.html"), htmlIndexTemplate, index)
+}
+
+func writeHTMLFile(path string, summary htmlSummary, source sourceFile) error {
+	src, err := source.read()
+	if err != nil {
+		return err
+	}
+
+	return writeHTMLTemplate(path, htmlFileTemplate, htmlFile{
+		htmlSummary: summary,
+		Source:      colorizeSource(src, source.profile.Boundaries(src)),
+	})
+}
+
+// colorizeSource returns the HTML escaped source, with profile blocks wrapped
+// in spans colored by their normalized hit count.  Boundaries closing a block
+// which was never opened, as happens with stale profiles, are skipped.
+func colorizeSource(src []byte, boundaries []Boundary) template.HTML {
+	var buf bytes.Buffer
+	last, open := 0, 0
+	for _, b := range boundaries {
+		template.HTMLEscape(&buf, src[last:b.Offset])
+		last = b.Offset
+		if !b.Start {
+			if open > 0 {
+				buf.WriteString("</span>")
+				open--
+			}
+			continue
+		}
+		open++
+		n := 0
+		if b.Count > 0 {
+			n = int(math.Floor(b.Norm*9)) + 1
+		}
+		_, _ = fmt.Fprintf(&buf, `<span class="cov%d" title="%d">`, n, b.Count)
+	}
+	template.HTMLEscape(&buf, src[last:])
+	for ; open > 0; open-- {
+		buf.WriteString("</span>")
+	}
+	return template.HTML(buf.String()) //nolint:gosec // the source is escaped above
+}
+
+func writeHTMLTemplate(path string, tmpl *template.Template, data any) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(file, data); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,81 @@
+package cobertura
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteHTML(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	coverage, err := convert(context.Background(), in, &Ignore{GeneratedFiles: true}, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	dir := filepath.Join(t.TempDir(), "html")
+	if err := coverage.writeHTML(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"<p>Total: 25.0% (4/16 lines)</p>",
+		"<h2>github.com/franchb/gocover-cobertura/testdata: 25.0%</h2>",
+		`<a href="file1.html">testdata/func2.go</a></td><td class="rate">4</td><td class="rate">8</td><td class="rate">50.0%</td>`,
+	} {
+		if !strings.Contains(string(index), expected) {
+			t.Errorf("index.html does not contain %q:\n%s", expected, index)
+		}
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "file1.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"<h1>testdata/func2.go: 50.0%</h1>",
+		`func (r Type1) Func2a(arg1 *int) <span class="cov8" title="1">{`,
+		"\tif *arg1 != 0 </span><span class=\"cov8\" title=\"1\">{\n\t\t*arg1 = 1\n\t}</span>\n}",
+	} {
+		if !strings.Contains(string(page), expected) {
+			t.Errorf("file1.html does not contain %q:\n%s", expected, page)
+		}
+	}
+}
+
+func TestColorizeSource(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("a < b && c")
+	actual := colorizeSource(src, []Boundary{
+		{Offset: 2, Start: true, Count: 3, Norm: 1},
+		{Offset: 6, Start: false},
+	})
+	expected := `a <span class="cov10" title="3">&lt; b </span>&amp;&amp; c`
+	if string(actual) != expected {
+		t.Errorf("colorizeSource returned %s but should be %s", actual, expected)
+	}
+
+	actual = colorizeSource(src, []Boundary{
+		{Offset: 1, Start: false},
+		{Offset: 2, Start: true, Count: 0},
+	})
+	expected = `a <span class="cov0" title="0">&lt; b &amp;&amp; c</span>`
+	if string(actual) != expected {
+		t.Errorf("colorizeSource returned %s but should be %s", actual, expected)
+	}
+}
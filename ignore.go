@@ -1,21 +1,58 @@
-package main
+package cobertura
 
 import (
+	"bytes"
+	"fmt"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"sync"
 )
 
 // As golint-ci referencing https://golang.org/s/generatedcode, be laxer.
 var genCodeRe = regexp.MustCompile(`(?im)^//.*(?:code generated|do not edit|autogenerated file)`)
 
+// Ignore selects the files and functions left out of a conversion.  The zero
+// value ignores nothing.
 type Ignore struct {
 	Dirs           *regexp.Regexp
 	Files          *regexp.Regexp
 	GeneratedFiles bool
-	cache          map[string]bool
+	// MatchDirs and MatchFiles restrict the report to the files in the
+	// directories, or with the names, they match.
+	MatchDirs  *regexp.Regexp
+	MatchFiles *regexp.Regexp
+	// GeneratedMarkers are matched against the head of the files, and
+	// GeneratedNames, as *.pb.go, against their base names, in addition to
+	// the built-in detection of the generated files.
+	GeneratedMarkers []*regexp.Regexp
+	GeneratedNames   []string
+	// Names are matched against the base names of the files to ignore,
+	// whatever their content, as the patterns of -ignore-preset.
+	Names []string
+	// TestFiles ignores the _test.go files, whose coverage gets in the
+	// profiles with some -coverpkg patterns.
+	TestFiles bool
+	// Vendor ignores the files under any vendor directory.
+	Vendor bool
+	// Deps ignores the packages out of the main modules, dependencies and
+	// standard library, whose coverage gets in the profiles with
+	// -coverpkg=all.
+	Deps bool
+	// Funcs matches the names of the functions to ignore, qualified by
+	// their receiver type for methods, as Type.Method.
+	Funcs *regexp.Regexp
+	cache map[string]bool
+	mu    sync.Mutex // guards cache, as files are matched concurrently
 }
 
+// Match reports whether the file is ignored, by its name or, with
+// GeneratedFiles, its content.  The results known from the name alone are
+// cached.
 func (i *Ignore) Match(fileName string, data []byte) (ret bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	if i.cache == nil {
 		i.cache = map[string]bool{}
 	} else if match, exists := i.cache[fileName]; exists {
@@ -25,18 +62,21 @@ func (i *Ignore) Match(fileName string, data []byte) (ret bool) {
 	dir := filepath.Dir(fileName)
 
 	if i.dirMatch(dir) ||
-		(i.Files != nil && i.Files.MatchString(fileName)) {
+		(i.Files != nil && i.Files.MatchString(fileName)) ||
+		(i.TestFiles && isTestFile(fileName)) ||
+		i.matchVendor(fileName) ||
+		matchName(i.Names, fileName) {
 		ret = true
 	} else if i.GeneratedFiles {
-		if data == nil {
-			return false // no cache if no content provided
-		}
+		if i.generatedName(fileName) {
+			ret = true
+		} else {
+			if data == nil {
+				return false // no cache if no content provided
+			}
 
-		const maxLineSize = 256
-		if len(data) > maxLineSize {
-			data = data[:maxLineSize]
+			ret = i.generatedContent(fileHead(data))
 		}
-		ret = genCodeRe.Match(data)
 	}
 
 	i.cache[fileName] = ret
@@ -44,13 +84,128 @@ func (i *Ignore) Match(fileName string, data []byte) (ret bool) {
 	return ret
 }
 
+// generatedName reports whether the base name of fileName matches one of
+// the generated file name patterns.
+func (i *Ignore) generatedName(fileName string) bool {
+	return matchName(i.GeneratedNames, fileName)
+}
+
+// matchName reports whether the base name of fileName matches one of the
+// patterns.
+func matchName(patterns []string, fileName string) bool {
+	base := path.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// byteOrderMark is the UTF-8 byte order mark some editors start files with.
+const byteOrderMark = "\uFEFF"
+
+// fileHead returns the head of a file searched for generated code markers,
+// without the byte order mark which would hide a marker on its first line.
+func fileHead(data []byte) []byte {
+	const maxLineSize = 256
+	data = bytes.TrimPrefix(data, []byte(byteOrderMark))
+	if len(data) > maxLineSize {
+		return data[:maxLineSize]
+	}
+	return data
+}
+
+// generatedContent reports whether the head of a file holds a generated
+// code marker.
+func (i *Ignore) generatedContent(head []byte) bool {
+	if genCodeRe.Match(head) {
+		return true
+	}
+	for _, marker := range i.GeneratedMarkers {
+		if marker.Match(head) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFunc reports whether the function, named as Name or Type.Name, is
+// ignored.  The unqualified name of methods is matched too.
+func (i *Ignore) MatchFunc(name string) bool {
+	if i.Funcs == nil {
+		return false
+	}
+	if i.Funcs.MatchString(name) {
+		return true
+	}
+	_, method, ok := strings.Cut(name, ".")
+	return ok && i.Funcs.MatchString(method)
+}
+
+// reason returns why fileName is ignored, for logging: the flag of the
+// matching regexp or the generated code marker.
+func (i *Ignore) reason(fileName string) string {
+	switch {
+	case i.dirMatch(filepath.Dir(fileName)):
+		return "-ignore-dirs"
+	case i.Files != nil && i.Files.MatchString(fileName):
+		return "-ignore-files"
+	case i.TestFiles && isTestFile(fileName):
+		return "-ignore-test-files"
+	case i.matchVendor(fileName):
+		return "-ignore-vendor"
+	case matchName(i.Names, fileName):
+		return "-ignore-preset"
+	default:
+		return "generated file"
+	}
+}
+
+func isTestFile(fileName string) bool {
+	return strings.HasSuffix(fileName, "_test.go")
+}
+
+// matchVendor reports whether vendored files are ignored and path, a file
+// or import path with either separator, is under a vendor directory.
+func (i *Ignore) matchVendor(path string) bool {
+	if !i.Vendor {
+		return false
+	}
+	_, vendored := devendorPath(filepath.Dir(strings.ReplaceAll(path, "\\", "/")))
+	return vendored
+}
+
+func (i *Ignore) ignoresDeps() bool {
+	return i.Deps
+}
+
+// excluded returns the flag of the include-only filter fileName, a full
+// file name as found in the profiles, fails to match, or "" if it is
+// included.
+func (i *Ignore) excluded(fileName string) string {
+	switch {
+	case i.MatchDirs != nil && !dirMatch(i.MatchDirs, filepath.Dir(fileName)):
+		return "-match-dirs"
+	case i.MatchFiles != nil && !i.MatchFiles.MatchString(fileName):
+		return "-match-files"
+	default:
+		return ""
+	}
+}
+
 func (i *Ignore) dirMatch(dir string) bool {
-	if i.Dirs == nil {
+	return dirMatch(i.Dirs, dir)
+}
+
+// dirMatch reports whether re matches dir or one of its parents.
+func dirMatch(re *regexp.Regexp, dir string) bool {
+	if re == nil {
 		return false
 	}
 
 	for {
-		if i.Dirs.MatchString(dir) {
+		if re.MatchString(dir) {
 			return true
 		}
 		dir, _ = filepath.Split(dir)
@@ -60,3 +215,44 @@ func (i *Ignore) dirMatch(dir string) bool {
 		dir = dir[:len(dir)-1] // without last separator
 	}
 }
+
+// regexpList is a flag of regexps, which may be repeated.
+type regexpList []*regexp.Regexp
+
+func (l *regexpList) String() string {
+	patterns := make([]string, len(*l))
+	for index, re := range *l {
+		patterns[index] = re.String()
+	}
+	return strings.Join(patterns, " ")
+}
+
+func (l *regexpList) Set(value string) error {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, re)
+	return nil
+}
+
+// globList is a flag of file name patterns, as *.pb.go, which may be
+// repeated or given a comma separated list.
+type globList []string
+
+func (l *globList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *globList) Set(value string) error {
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+		*l = append(*l, pattern)
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-package main
+package cobertura
 
 import (
 	"regexp"
@@ -102,3 +102,167 @@ func TestIgnore(t *testing.T) {
 		gen.cache = nil
 	}
 }
+
+func TestIgnoreReason(t *testing.T) {
+	t.Parallel()
+
+	ignore := Ignore{
+		Dirs:           regexp.MustCompile(`/mocks$`),
+		Files:          regexp.MustCompile(`_string\.go$`),
+		GeneratedFiles: true,
+		TestFiles:      true,
+	}
+	for fileName, expected := range map[string]string{
+		"foo/mocks/foo.go":   "-ignore-dirs",
+		"foo/kind_string.go": "-ignore-files",
+		"foo/foo_test.go":    "-ignore-test-files",
+		"foo/foo.pb.go":      "generated file",
+	} {
+		if actual := ignore.reason(fileName); actual != expected {
+			t.Errorf("reason(%q) = %q, expected %q", fileName, actual, expected)
+		}
+	}
+}
+
+func TestIgnoreTestFiles(t *testing.T) {
+	t.Parallel()
+
+	ignore := Ignore{TestFiles: true}
+	for fileName, expected := range map[string]bool{
+		"foo/foo_test.go":  true,
+		"foo/test.go":      false,
+		"foo/foo_test.go2": false,
+		"foo_test/foo.go":  false,
+	} {
+		if actual := ignore.Match(fileName, nil); actual != expected {
+			t.Errorf("Match(%q) = %v, expected %v", fileName, actual, expected)
+		}
+	}
+}
+
+func TestIgnoreVendor(t *testing.T) {
+	t.Parallel()
+
+	ignore := Ignore{Vendor: true}
+	for fileName, expected := range map[string]bool{
+		"vendor/github.com/pkg/errors/errors.go":          true,
+		"example.com/app/vendor/github.com/pkg/errors.go": true,
+		`example.com\app\vendor\golang.org\x\tools.go`:    true,
+		"example.com/app/vendors/foo.go":                  false,
+		"example.com/vendor.go":                           false,
+	} {
+		if actual := ignore.Match(fileName, nil); actual != expected {
+			t.Errorf("Match(%q) = %v, expected %v", fileName, actual, expected)
+		}
+	}
+	if (&Ignore{}).Match("vendor/github.com/pkg/errors/errors.go", nil) {
+		t.Error("vendored file ignored without -ignore-vendor")
+	}
+}
+
+func TestIgnoreGeneratedMarkersAndNames(t *testing.T) {
+	t.Parallel()
+
+	ignore := Ignore{
+		GeneratedFiles:   true,
+		GeneratedMarkers: []*regexp.Regexp{regexp.MustCompile(`(?m)^// Generated by mockery`)},
+		GeneratedNames:   []string{"*.pb.go", "zz_generated*"},
+	}
+	for _, test := range []struct {
+		fileName string
+		data     string
+		expected bool
+	}{
+		{"foo/foo.pb.go", "package foo", true},
+		{"foo/zz_generated.deepcopy.go", "", true},
+		{`foo\zz_generated.go`, "", true},
+		{"foo/mock.go", "package foo\n// Generated by mockery v2", true},
+		{"foo/gen.go", "// Code generated by stringer; DO NOT EDIT.", true},
+		{"foo/foo.go", "package foo", false},
+		{"foo.pb.go/foo.go", "package foo", false},
+	} {
+		var data []byte
+		if test.data != "" {
+			data = []byte(test.data)
+		}
+		if actual := ignore.Match(test.fileName, data); actual != test.expected {
+			t.Errorf("Match(%q) = %v, expected %v", test.fileName, actual, test.expected)
+		}
+	}
+
+	names := Ignore{GeneratedNames: []string{"*.pb.go"}}
+	if names.Match("foo/foo.pb.go", nil) {
+		t.Error("generated file name ignored without -ignore-gen-files")
+	}
+}
+
+func TestGlobListSet(t *testing.T) {
+	t.Parallel()
+
+	var patterns globList
+	if err := patterns.Set("*.pb.go, *_gen.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := patterns.Set("zz_generated*"); err != nil {
+		t.Fatal(err)
+	}
+	if actual := patterns.String(); actual != "*.pb.go,*_gen.go,zz_generated*" {
+		t.Errorf("patterns %s", actual)
+	}
+	if err := patterns.Set("[z-"); err == nil {
+		t.Error("no error for a bad pattern")
+	}
+}
+
+func TestIgnorePreset(t *testing.T) {
+	t.Parallel()
+
+	ignore := &Ignore{}
+	if err := (*presetList)(&ignore.Names).Set("protobuf, mocks,wire"); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		fileName string
+		expected bool
+	}{
+		{"api/v1/service.pb.go", true},
+		{"api/v1/service.pb.gw.go", true},
+		{"store/store_mock.go", true},
+		{"store/mock_store.go", true},
+		{"cmd/wire_gen.go", true},
+		{"kind_string.go", false},
+		{"store/store.go", false},
+	} {
+		if actual := ignore.Match(test.fileName, nil); actual != test.expected {
+			t.Errorf("%s: ignored %t, expected %t", test.fileName, actual, test.expected)
+		}
+	}
+	if reason := ignore.reason("cmd/wire_gen.go"); reason != "-ignore-preset" {
+		t.Errorf("reason %q, expected -ignore-preset", reason)
+	}
+	if err := (*presetList)(&ignore.Names).Set("thrift"); err == nil {
+		t.Error("no error for an unknown preset")
+	}
+}
+
+func TestIgnoreExcluded(t *testing.T) {
+	t.Parallel()
+
+	ignore := Ignore{
+		MatchDirs:  regexp.MustCompile(`^example\.com/mono/billing$`),
+		MatchFiles: regexp.MustCompile(`\.go$`),
+	}
+	for fileName, expected := range map[string]string{
+		"example.com/mono/billing/invoice.go":     "",
+		"example.com/mono/billing/api/handler.go": "",
+		"example.com/mono/billing/invoice.tmpl":   "-match-files",
+		"example.com/mono/shipping/parcel.go":     "-match-dirs",
+	} {
+		if actual := ignore.excluded(fileName); actual != expected {
+			t.Errorf("excluded(%q) = %q, expected %q", fileName, actual, expected)
+		}
+	}
+	if reason := (&Ignore{}).excluded("example.com/mono/shipping/parcel.go"); reason != "" {
+		t.Errorf("excluded without filters: %q", reason)
+	}
+}
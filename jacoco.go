@@ -0,0 +1,176 @@
+package cobertura
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+type jacocoGroup struct {
+	Groups   []*jacocoGroup   `xml:"group"`
+	Packages []*jacocoPackage `xml:"package"`
+}
+
+type jacocoPackage struct {
+	Name        string              `xml:"name,attr"`
+	Classes     []*jacocoClass      `xml:"class"`
+	SourceFiles []*jacocoSourceFile `xml:"sourcefile"`
+}
+
+type jacocoClass struct {
+	Name           string          `xml:"name,attr"`
+	SourceFileName string          `xml:"sourcefilename,attr"`
+	Methods        []*jacocoMethod `xml:"method"`
+}
+
+type jacocoMethod struct {
+	Name string `xml:"name,attr"`
+	Desc string `xml:"desc,attr"`
+	Line int    `xml:"line,attr"`
+}
+
+type jacocoSourceFile struct {
+	Name  string        `xml:"name,attr"`
+	Lines []*jacocoLine `xml:"line"`
+}
+
+type jacocoLine struct {
+	Number  int `xml:"nr,attr"`
+	Missed  int `xml:"mi,attr"`
+	Covered int `xml:"ci,attr"`
+}
+
+// isJaCoCo reports whether the XML document of in is a JaCoCo report, whose
+// root element is <report>, without consuming it.
+func isJaCoCo(in *bufio.Reader) bool {
+	head, _ := in.Peek(4096)
+	decoder := xml.NewDecoder(bytes.NewReader(head))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "report"
+		}
+	}
+}
+
+// readJaCoCo reads the JaCoCo XML reports of in, which may hold several of
+// them one after the other, as Cobertura coverage.  Packages and classes are
+// named with dots, and the lines of a source file are assigned to the
+// closest method declared before them.  As JaCoCo does not count
+// executions, covered lines have a single hit.
+func readJaCoCo(in io.Reader) (*Coverage, error) {
+	decoder := xml.NewDecoder(in)
+	var coverage *Coverage
+	for {
+		var report jacocoGroup
+		err := decoder.Decode(&report)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read JaCoCo report: %w", err)
+		}
+
+		doc := &Coverage{}
+		report.addPackages(doc)
+		if coverage == nil {
+			coverage = doc
+			coverage.Recompute()
+		} else if err := coverage.merge(doc); err != nil {
+			return nil, err
+		}
+	}
+	if coverage == nil {
+		return nil, fmt.Errorf("read JaCoCo report: no <report> element")
+	}
+	return coverage, nil
+}
+
+// addPackages adds the packages of the group and of its nested groups.
+func (group *jacocoGroup) addPackages(coverage *Coverage) {
+	for _, jacocoPkg := range group.Packages {
+		coverage.Packages = append(coverage.Packages, jacocoPkg.toPackage())
+	}
+	for _, child := range group.Groups {
+		child.addPackages(coverage)
+	}
+}
+
+// convertJaCoCo reads a JaCoCo report, for the jacoco input format.
+func convertJaCoCo(_ context.Context, in io.Reader, ignore Matcher, opts *Options, _ []string) (*Coverage, error) {
+	coverage, err := readJaCoCo(in)
+	if err != nil {
+		return nil, err
+	}
+	return coverage.finishRead(ignore, opts)
+}
+
+func (jacocoPkg *jacocoPackage) toPackage() *Package {
+	pkg := &Package{Name: strings.ReplaceAll(jacocoPkg.Name, "/", ".")}
+
+	type methodStart struct {
+		line   int
+		class  *Class
+		method *Method
+	}
+	starts := map[string][]methodStart{} // by source file name
+	for _, jacocoClass := range jacocoPkg.Classes {
+		fileName := jacocoClass.SourceFileName
+		if fileName == "" {
+			// reports of JaCoCo before 0.7.8 lack the source file name
+			fileName, _, _ = strings.Cut(path.Base(jacocoClass.Name), "$")
+			fileName += ".java"
+		}
+		class := &Class{
+			Name:     strings.ReplaceAll(jacocoClass.Name, "/", "."),
+			Filename: path.Join(jacocoPkg.Name, fileName),
+			Methods:  []*Method{},
+			Lines:    Lines{},
+		}
+		pkg.Classes = append(pkg.Classes, class)
+
+		for _, jacocoMethod := range jacocoClass.Methods {
+			method := &Method{Name: jacocoMethod.Name, Signature: jacocoMethod.Desc, line: jacocoMethod.Line, Lines: Lines{}}
+			class.Methods = append(class.Methods, method)
+			if jacocoMethod.Line > 0 {
+				starts[fileName] = append(starts[fileName], methodStart{jacocoMethod.Line, class, method})
+			}
+		}
+	}
+
+	for _, sourceFile := range jacocoPkg.SourceFiles {
+		fileStarts := starts[sourceFile.Name]
+		sort.SliceStable(fileStarts, func(i, j int) bool { return fileStarts[i].line < fileStarts[j].line })
+		if len(fileStarts) == 0 {
+			continue
+		}
+
+		lines := sourceFile.Lines
+		sort.SliceStable(lines, func(i, j int) bool { return lines[i].Number < lines[j].Number })
+		for _, jacocoLine := range lines {
+			index := sort.Search(len(fileStarts), func(i int) bool { return fileStarts[i].line > jacocoLine.Number }) - 1
+			if index < 0 {
+				index = 0 // before the first method, such as field declarations
+			}
+			start := fileStarts[index]
+
+			line := &Line{Number: jacocoLine.Number}
+			if jacocoLine.Covered > 0 {
+				line.Hits = 1
+			}
+			start.method.Lines = append(start.method.Lines, line)
+			start.class.Lines = append(start.class.Lines, line)
+		}
+	}
+	return pkg
+}
@@ -0,0 +1,92 @@
+package cobertura
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+)
+
+const jacocoReport = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<!DOCTYPE report PUBLIC "-//JACOCO//DTD Report 1.1//EN" "report.dtd">
+<report name="example">
+  <sessioninfo id="host-1" start="1" dump="2"/>
+  <group name="module">
+    <package name="com/example">
+      <class name="com/example/Foo" sourcefilename="Foo.java">
+        <method name="&lt;init&gt;" desc="()V" line="3">
+          <counter type="LINE" missed="0" covered="1"/>
+        </method>
+        <method name="run" desc="(I)I" line="5">
+          <counter type="LINE" missed="1" covered="2"/>
+        </method>
+      </class>
+      <class name="com/example/Foo$Inner" sourcefilename="Foo.java">
+        <method name="get" desc="()V" line="10"/>
+      </class>
+      <sourcefile name="Foo.java">
+        <line nr="1" mi="0" ci="2" mb="0" cb="0"/>
+        <line nr="3" mi="0" ci="3" mb="0" cb="0"/>
+        <line nr="6" mi="0" ci="4" mb="0" cb="0"/>
+        <line nr="7" mi="2" ci="0" mb="0" cb="0"/>
+        <line nr="5" mi="0" ci="1" mb="1" cb="1"/>
+        <line nr="11" mi="3" ci="0" mb="0" cb="0"/>
+      </sourcefile>
+    </package>
+  </group>
+</report>
+`
+
+func TestReadJaCoCo(t *testing.T) {
+	t.Parallel()
+
+	in := bufio.NewReader(strings.NewReader(jacocoReport))
+	if !isJaCoCo(in) {
+		t.Fatal("the report was not detected as JaCoCo")
+	}
+	if isJaCoCo(bufio.NewReader(strings.NewReader(`<?xml version="1.0"?><coverage/>`))) {
+		t.Error("a Cobertura report was detected as JaCoCo")
+	}
+
+	coverage, err := readJaCoCo(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if coverage.LinesCovered != 4 || coverage.LinesValid != 6 {
+		t.Errorf("unexpected totals %d/%d", coverage.LinesCovered, coverage.LinesValid)
+	}
+	if len(coverage.Packages) != 1 || coverage.Packages[0].Name != "com.example" {
+		t.Fatalf("unexpected packages %+v", coverage.Packages)
+	}
+
+	var actual []string
+	for _, class := range coverage.Packages[0].Classes {
+		actual = append(actual, class.Name+"@"+class.Filename)
+		for _, method := range class.Methods {
+			actual = append(actual, method.Name+method.Signature)
+			for _, line := range method.Lines {
+				actual = append(actual, strings.Repeat("+", int(line.Hits))+"-")
+			}
+		}
+	}
+	expected := "com.example.Foo@com/example/Foo.java <init>()V +- +- run(I)I +- +- - com.example.Foo$Inner@com/example/Foo.java get()V -"
+	if strings.Join(actual, " ") != expected {
+		t.Errorf("unexpected classes:\n%s\nexpected:\n%s", strings.Join(actual, " "), expected)
+	}
+}
+
+func TestConvertJaCoCoMergesReports(t *testing.T) {
+	t.Parallel()
+
+	coverage, err := convertJaCoCo(context.Background(), strings.NewReader(jacocoReport+"\n"+jacocoReport), &Ignore{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coverage.LinesCovered != 4 || coverage.LinesValid != 6 || len(coverage.Packages[0].Classes) != 2 {
+		t.Errorf("unexpected merged coverage %d/%d", coverage.LinesCovered, coverage.LinesValid)
+	}
+	if hits := coverage.Packages[0].Classes[0].Lines[0].Hits; hits != 2 {
+		t.Errorf("unexpected merged hits %d", hits)
+	}
+}
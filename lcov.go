@@ -0,0 +1,182 @@
+package cobertura
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lcovFile holds the records of a source file in an LCOV tracefile.
+type lcovFile struct {
+	name      string
+	functions map[string]int // start line by name
+	hits      map[int]int64  // by line
+}
+
+// parseLCOV reads an LCOV tracefile, merging the records of the same source
+// file.  Only the function and line records are used.
+func parseLCOV(in io.Reader) ([]*lcovFile, error) {
+	var files []*lcovFile
+	byName := map[string]*lcovFile{}
+	var current *lcovFile
+
+	scanner := bufio.NewScanner(in)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, _ := strings.Cut(line, ":")
+		switch key {
+		case "SF":
+			current = byName[value]
+			if current == nil {
+				current = &lcovFile{name: value, functions: map[string]int{}, hits: map[int]int64{}}
+				byName[value] = current
+				files = append(files, current)
+			}
+			continue
+		case "end_of_record":
+			current = nil
+			continue
+		case "FN", "DA":
+		default:
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: %s record outside of a source file", lineNo, key)
+		}
+		fields := strings.Split(value, ",")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: bad %s record: %s", lineNo, key, line)
+		}
+		number, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad %s record: %s", lineNo, key, line)
+		}
+		if key == "FN" {
+			// FN:<line>,<name> or FN:<line>,<end line>,<name>
+			current.functions[fields[len(fields)-1]] = number
+			continue
+		}
+		hits, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad %s record: %s", lineNo, key, line)
+		}
+		current.hits[number] += hits
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan LCOV tracefile: %w", err)
+	}
+	return files, nil
+}
+
+// convertLCOV builds the coverage report from an LCOV tracefile.  Source
+// files below the current directory are reported relative to it, packages
+// are the directories of the source files and classes are the files, with
+// a method per function.
+func convertLCOV(_ context.Context, in io.Reader, ignore Matcher, opts *Options, _ []string) (*Coverage, error) {
+	files, err := parseLCOV(in)
+	if err != nil {
+		return nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := &Coverage{Sources: []*Source{{Path: cwd}}, Timestamp: time.Now().UnixNano() / int64(time.Millisecond)}
+	coverage.timestampMillis = opts.timestampMillis()
+	packages := map[string]*Package{}
+	for _, file := range files {
+		fileName := file.name
+		if rel, err := filepath.Rel(cwd, fileName); err == nil && filepath.IsAbs(fileName) && !strings.HasPrefix(rel, "..") {
+			fileName = rel
+		}
+		fileName = filepath.ToSlash(fileName)
+		if matchFile(ignore, fileName, nil) || excludedBy(ignore, fileName) != "" {
+			continue
+		}
+
+		pkgName := filepath.ToSlash(filepath.Dir(fileName))
+		pkg := packages[pkgName]
+		if pkg == nil {
+			pkg = &Package{Name: pkgName}
+			packages[pkgName] = pkg
+			coverage.Packages = append(coverage.Packages, pkg)
+		}
+		pkg.Classes = append(pkg.Classes, file.class(fileName))
+	}
+
+	sort.Slice(coverage.Packages, func(i, j int) bool { return coverage.Packages[i].Name < coverage.Packages[j].Name })
+	for _, pkg := range coverage.Packages {
+		pkg.LineRate = pkg.HitRate()
+	}
+	coverage.LinesValid = coverage.NumLines()
+	coverage.LinesCovered = coverage.NumLinesWithHits()
+	coverage.LineRate = coverage.HitRate()
+
+	if opts.failOnEmpty() && coverage.LinesValid == 0 {
+		return nil, errEmptyCoverage
+	}
+	return coverage, nil
+}
+
+// class returns the file as a class, the lines of a function being the ones
+// from its start up to the start of the next function.  Lines before the
+// first function are reported under a "-" method.
+func (file *lcovFile) class(fileName string) *Class {
+	class := &Class{
+		Name:     strings.ReplaceAll(fileName, "/", "."),
+		Filename: fileName,
+		Methods:  []*Method{},
+		Lines:    Lines{},
+	}
+
+	numbers := make([]int, 0, len(file.hits))
+	for number := range file.hits {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	methods := make([]*Method, 0, len(file.functions))
+	for name, line := range file.functions {
+		methods = append(methods, &Method{Name: name, line: line, Lines: Lines{}})
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		if methods[i].line != methods[j].line {
+			return methods[i].line < methods[j].line
+		}
+		return methods[i].Name < methods[j].Name
+	})
+
+	var method *Method
+	next := 0
+	for _, number := range numbers {
+		for next < len(methods) && methods[next].line <= number {
+			method = methods[next]
+			class.Methods = append(class.Methods, method)
+			next++
+		}
+		if method == nil {
+			method = &Method{Name: "-", line: number, Lines: Lines{}}
+			class.Methods = append(class.Methods, method)
+		}
+		line := &Line{Number: number, Hits: file.hits[number]}
+		method.Lines = append(method.Lines, line)
+		class.Lines = append(class.Lines, line)
+	}
+	class.Methods = append(class.Methods, methods[next:]...)
+
+	for _, method := range class.Methods {
+		method.LineRate = method.Lines.HitRate()
+	}
+	class.LineRate = class.Lines.HitRate()
+	return class
+}
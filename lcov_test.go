@@ -0,0 +1,79 @@
+package cobertura
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestConvertLCOV(t *testing.T) {
+	t.Parallel()
+
+	in := strings.NewReader(`TN:
+SF:src/util/util.c
+FN:3,add
+FN:8,sub
+FNDA:2,add
+FNDA:0,sub
+DA:1,1
+DA:4,2
+DA:5,2
+DA:9,0
+DA:10,0
+LF:5
+LH:3
+end_of_record
+SF:src/main.c
+FN:1,2,main
+DA:2,1
+end_of_record
+SF:src/util/util.c
+DA:9,3
+end_of_record
+`)
+	coverage, err := convertLCOV(context.Background(), in, &Ignore{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if coverage.LinesCovered != 5 || coverage.LinesValid != 6 {
+		t.Errorf("unexpected totals %d/%d", coverage.LinesCovered, coverage.LinesValid)
+	}
+	if len(coverage.Packages) != 2 || coverage.Packages[0].Name != "src" || coverage.Packages[1].Name != "src/util" {
+		t.Fatalf("unexpected packages %+v", coverage.Packages)
+	}
+
+	class := coverage.Packages[1].Classes[0]
+	if class.Name != "src.util.util.c" || class.Filename != "src/util/util.c" || len(class.Lines) != 5 {
+		t.Errorf("unexpected class %+v", class)
+	}
+	var methods []string
+	for _, method := range class.Methods {
+		methods = append(methods, method.Name)
+		for _, line := range method.Lines {
+			methods = append(methods, strings.Repeat("+", int(line.Hits)))
+		}
+	}
+	if actual := strings.Join(methods, " "); actual != "- + add ++ ++ sub +++ " {
+		t.Errorf("unexpected methods %q", actual)
+	}
+
+	if method := coverage.Packages[0].Classes[0].Methods[0]; method.Name != "main" || method.NumLinesWithHits() != 1 {
+		t.Errorf("unexpected method %+v", method)
+	}
+}
+
+func TestConvertLCOVErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"DA:1,1\n",
+		"SF:a.c\nDA:x,1\n",
+		"SF:a.c\nDA:1\n",
+		"SF:a.c\nDA:1,y\n",
+	} {
+		if _, err := convertLCOV(context.Background(), strings.NewReader(input), &Ignore{}, nil, nil); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+)
+
+// writeMarkdown writes a Markdown table of the line coverage per package and
+// in total, suitable for pull request descriptions and job summaries.
+func (cov *Coverage) writeMarkdown(out io.Writer) error {
+	_, _ = fmt.Fprintln(out, "| Package | Line rate | Covered | Valid |")
+	_, _ = fmt.Fprintln(out, "| --- | ---: | ---: | ---: |")
+	for _, pkg := range cov.Packages {
+		_, _ = fmt.Fprintf(out, "| %s | %s | %d | %d |\n",
+			markdownEscape(pkg.Name), percent(pkg.NumLinesWithHits(), pkg.NumLines()),
+			pkg.NumLinesWithHits(), pkg.NumLines())
+	}
+	_, err := fmt.Fprintf(out, "| **Total** | **%s** | **%d** | **%d** |\n",
+		percent(cov.LinesCovered, cov.LinesValid), cov.LinesCovered, cov.LinesValid)
+	return err
+}
+
+// percent formats covered/valid as a percentage, or "-" when there is
+// nothing to cover.
+func percent(covered, valid int64) string {
+	if valid == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(covered)/float64(valid))
+}
+
+func markdownEscape(s string) string {
+	escaped := make([]byte, 0, len(s))
+	for index := 0; index < len(s); index++ {
+		switch s[index] {
+		case '|', '*', '_', '`', '\\':
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, s[index])
+	}
+	return string(escaped)
+}
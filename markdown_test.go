@@ -0,0 +1,40 @@
+package cobertura
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	t.Parallel()
+
+	coverage := Coverage{
+		LinesCovered: 3,
+		LinesValid:   4,
+		Packages: []*Package{
+			{Name: "example.com/foo_bar", Classes: []*Class{{Methods: []*Method{
+				{Lines: Lines{{Number: 1, Hits: 1}, {Number: 2, Hits: 1}, {Number: 3, Hits: 0}}},
+			}}}},
+			{Name: "example.com/baz", Classes: []*Class{{Methods: []*Method{
+				{Lines: Lines{{Number: 1, Hits: 2}}},
+			}}}},
+			{Name: "example.com/empty"},
+		},
+	}
+
+	var out strings.Builder
+	if err := coverage.writeMarkdown(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `| Package | Line rate | Covered | Valid |
+| --- | ---: | ---: | ---: |
+| example.com/foo\_bar | 66.7% | 2 | 3 |
+| example.com/baz | 100.0% | 1 | 1 |
+| example.com/empty | - | 0 | 0 |
+| **Total** | **75.0%** | **3** | **4** |
+`
+	if out.String() != expected {
+		t.Errorf("unexpected markdown:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
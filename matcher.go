@@ -0,0 +1,163 @@
+package cobertura
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Matcher selects the files left out of a conversion, given their name, as
+// found in the profile and without the module path once its package is
+// known, and their content, nil until it is read.  Matchers deciding on the
+// content must report false for a nil content, as they are asked again once
+// it is read.  Match is called concurrently.  Ignore is the Matcher of the
+// command flags, and the Match functions build others, to be combined with
+// MatchAny.
+type Matcher interface {
+	Match(fileName string, data []byte) bool
+}
+
+// FuncMatcher is a Matcher also selecting the functions left out, named as
+// Name, or Type.Name for methods.
+type FuncMatcher interface {
+	Matcher
+	MatchFunc(name string) bool
+}
+
+// MatcherFunc is a function selecting the files left out, as a Matcher.
+type MatcherFunc func(fileName string, data []byte) bool
+
+// Match calls f(fileName, data).
+func (f MatcherFunc) Match(fileName string, data []byte) bool {
+	return f(fileName, data)
+}
+
+// MatchRegexp returns a Matcher of the file names re matches.
+func MatchRegexp(re *regexp.Regexp) Matcher {
+	return MatcherFunc(func(fileName string, _ []byte) bool {
+		return re.MatchString(fileName)
+	})
+}
+
+// MatchGlob returns a Matcher of the file names, or of their base names,
+// matching one of the path.Match patterns, as internal/mocks/* or *.pb.go.
+func MatchGlob(patterns ...string) Matcher {
+	return MatcherFunc(func(fileName string, _ []byte) bool {
+		fileName = strings.ReplaceAll(fileName, "\\", "/")
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, fileName); matched {
+				return true
+			}
+			if matched, _ := path.Match(pattern, path.Base(fileName)); matched {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// MatchGenerated returns a Matcher of the generated files, holding a
+// generated code marker in their head, as -ignore-gen-files.
+func MatchGenerated() Matcher {
+	return MatcherFunc(func(fileName string, data []byte) bool {
+		return data != nil && (&Ignore{GeneratedFiles: true}).generatedContent(fileHead(data))
+	})
+}
+
+// MatchAny returns a Matcher of the files, and of the functions, matched by
+// any of the matchers.
+func MatchAny(matchers ...Matcher) FuncMatcher {
+	return anyMatcher(matchers)
+}
+
+type anyMatcher []Matcher
+
+func (m anyMatcher) Match(fileName string, data []byte) bool {
+	for _, matcher := range m {
+		if matcher.Match(fileName, data) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m anyMatcher) MatchFunc(name string) bool {
+	for _, matcher := range m {
+		if matchFunc(matcher, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m anyMatcher) reason(fileName string) string {
+	for _, matcher := range m {
+		if matcher.Match(fileName, nil) {
+			return ignoreReason(matcher, fileName)
+		}
+	}
+	return ignoreReason(nil, fileName)
+}
+
+func (m anyMatcher) excluded(fileName string) string {
+	for _, matcher := range m {
+		if reason := excludedBy(matcher, fileName); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+func (m anyMatcher) matchVendor(path string) bool {
+	for _, matcher := range m {
+		if matchVendor(matcher, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m anyMatcher) ignoresDeps() bool {
+	for _, matcher := range m {
+		if ignoresDeps(matcher) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFile reports whether m, which may be nil, matches the file.
+func matchFile(m Matcher, fileName string, data []byte) bool {
+	return m != nil && m.Match(fileName, data)
+}
+
+// matchFunc reports whether m, which may be nil, is a FuncMatcher matching
+// the function.
+func matchFunc(m Matcher, name string) bool {
+	funcMatcher, ok := m.(FuncMatcher)
+	return ok && funcMatcher.MatchFunc(name)
+}
+
+// ignoreReason returns why m ignores fileName, for logging.
+func ignoreReason(m Matcher, fileName string) string {
+	if reasoner, ok := m.(interface{ reason(string) string }); ok {
+		return reasoner.reason(fileName)
+	}
+	return "matcher"
+}
+
+// excludedBy returns the include-only filter of m fileName fails to match,
+// or "" if it is included or m has no such filter.
+func excludedBy(m Matcher, fileName string) string {
+	if excluder, ok := m.(interface{ excluded(string) string }); ok {
+		return excluder.excluded(fileName)
+	}
+	return ""
+}
+
+// matchVendor reports whether m ignores the vendored files and path is under
+// a vendor directory.
+func matchVendor(m Matcher, path string) bool {
+	vendorMatcher, ok := m.(interface{ matchVendor(string) bool })
+	return ok && vendorMatcher.matchVendor(path)
+}
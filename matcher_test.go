@@ -0,0 +1,81 @@
+package cobertura
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	t.Parallel()
+
+	generated := []byte("// Code generated by mockgen. DO NOT EDIT.\n\npackage mocks\n")
+	for _, test := range []struct {
+		name     string
+		matcher  Matcher
+		fileName string
+		data     []byte
+		expected bool
+	}{
+		{"regexp", MatchRegexp(regexp.MustCompile(`_mock\.go$`)), "p/db_mock.go", nil, true},
+		{"regexp no match", MatchRegexp(regexp.MustCompile(`_mock\.go$`)), "p/db.go", nil, false},
+		{"glob base name", MatchGlob("*.pb.go"), "api/v1/user.pb.go", nil, true},
+		{"glob path", MatchGlob("internal/mocks/*"), "internal/mocks/db.go", nil, true},
+		{"glob windows path", MatchGlob("internal/mocks/*"), `internal\mocks\db.go`, nil, true},
+		{"glob no match", MatchGlob("*.pb.go", "mocks/*"), "api/v1/user.go", nil, false},
+		{"generated", MatchGenerated(), "mocks/db.go", generated, true},
+		{"generated unread", MatchGenerated(), "mocks/db.go", nil, false},
+		{"generated handwritten", MatchGenerated(), "p/db.go", []byte("package p\n"), false},
+		{"func", MatcherFunc(func(fileName string, _ []byte) bool {
+			return !strings.HasPrefix(fileName, "billing/")
+		}), "shipping/parcel.go", nil, true},
+		{"any", MatchAny(MatchGlob("*.pb.go"), &Ignore{TestFiles: true}), "p/db_test.go", nil, true},
+		{"any none", MatchAny(MatchGlob("*.pb.go"), &Ignore{TestFiles: true}), "p/db.go", nil, false},
+		{"any empty", MatchAny(), "p/db.go", nil, false},
+	} {
+		if actual := test.matcher.Match(test.fileName, test.data); actual != test.expected {
+			t.Errorf("%s: Match(%q) = %v, expected %v", test.name, test.fileName, actual, test.expected)
+		}
+	}
+}
+
+func TestMatchAnyForwards(t *testing.T) {
+	t.Parallel()
+
+	matcher := MatchAny(MatchGlob("*.pb.go"), &Ignore{
+		Funcs:      regexp.MustCompile(`^String$`),
+		MatchFiles: regexp.MustCompile(`^billing/`),
+		Vendor:     true,
+	})
+	if !matcher.MatchFunc("Status.String") || matcher.MatchFunc("Run") {
+		t.Error("functions not matched by the Ignore of the matchers")
+	}
+	if reason := excludedBy(matcher, "shipping/parcel.go"); reason != "-match-files" {
+		t.Errorf("excluded by %q, expected -match-files", reason)
+	}
+	if !matchVendor(matcher, "/src/vendor/example.com/x/x.go") {
+		t.Error("vendored file not matched")
+	}
+	if reason := ignoreReason(MatchAny(MatchGlob("*.pb.go"), &Ignore{TestFiles: true}), "p/db_test.go"); reason != "-ignore-test-files" {
+		t.Errorf("ignored for %q, expected -ignore-test-files", reason)
+	}
+}
+
+func TestConvertMatcher(t *testing.T) {
+	t.Parallel()
+
+	coverage := convertTestdata(t, MatchAny(
+		MatcherFunc(func(fileName string, _ []byte) bool { return strings.HasSuffix(fileName, "func2.go") }),
+		MatchGenerated(),
+	), nil)
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			if strings.HasSuffix(class.Filename, "func2.go") {
+				t.Errorf("class %s of ignored file %s", class.Name, class.Filename)
+			}
+			if strings.HasSuffix(class.Filename, "func3.go") {
+				t.Errorf("class %s of generated file %s", class.Name, class.Filename)
+			}
+		}
+	}
+}
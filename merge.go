@@ -0,0 +1,380 @@
+package cobertura
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// stringList is a flag which may be repeated or given a comma separated list.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// expandGlobs replaces the glob patterns of names, such as coverage/*.out,
+// by the files they match.  URLs are kept as is.
+func expandGlobs(names []string) ([]string, error) {
+	expanded := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") ||
+			!strings.ContainsAny(name, "*?[") {
+			expanded = append(expanded, name)
+			continue
+		}
+		matches, err := filepath.Glob(name)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", name, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no file matches %q", name)
+		}
+		expanded = append(expanded, matches...)
+	}
+	return expanded, nil
+}
+
+// mergeProfileReaders returns a reader of a single profile holding the blocks
+// of every input profile, which ParseProfiles merges according to the mode:
+// counts are summed in count and atomic modes and or-ed in set mode.  When
+// the modes differ, the profile is in count mode, set profiles counting a
+// single hit per covered block.  The lines of the inputs are bounded by
+// maxLine bytes, as the ones of a single profile.
+func mergeProfileReaders(inputs []io.Reader, maxLine int) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(copyMergedProfiles(pw, inputs, maxLine))
+	}()
+	return pr
+}
+
+func copyMergedProfiles(out io.Writer, inputs []io.Reader, maxLine int) error {
+	readers := make([]*bufio.Reader, len(inputs))
+	mode := ""
+	for index, in := range inputs {
+		readers[index] = bufio.NewReader(in)
+		line, err := readLine(readers[index])
+		if err != nil {
+			return fmt.Errorf("profile %d: %w", index+1, err)
+		}
+		inputMode, ok := strings.CutPrefix(line, "mode: ")
+		if !ok || inputMode == "" {
+			return fmt.Errorf("profile %d: %w: %s", index+1, ErrBadModeLine, line)
+		}
+		if mode == "" {
+			mode = inputMode
+		} else if mode != inputMode {
+			mode = "count"
+		}
+	}
+
+	writer := bufio.NewWriter(out)
+	_, _ = fmt.Fprintf(writer, "mode: %s\n", mode)
+	for index, reader := range readers {
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, min(bufio.MaxScanTokenSize, maxLine)), maxLine)
+		lines := 2 // after the mode line
+		for ; scanner.Scan(); lines++ {
+			_, _ = fmt.Fprintln(writer, scanner.Text())
+		}
+		if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("profile %d: line %d is longer than %d bytes, raise -max-profile-line: %w", index+1, lines, maxLine, err)
+		} else if err != nil {
+			return fmt.Errorf("profile %d: %w", index+1, err)
+		}
+	}
+	return writer.Flush()
+}
+
+// MergeMode is how MergeProfiles combines the counts of the same block.
+type MergeMode int
+
+const (
+	// MergeByMode sums the counts, or ors them in set mode, like the
+	// profiles of the packages of a single go test run.
+	MergeByMode MergeMode = iota
+	// MergeSum sums the counts, the profiles being in count mode.
+	MergeSum
+	// MergeMax keeps the highest count, for shards running the same tests.
+	MergeMax
+)
+
+// MergeProfiles merges the profiles of a and b, such as the ones of the
+// shards of a test run, into a profile per file sorted by file name, the
+// counts of the blocks at the same location being combined by mode.  When
+// the modes of the profiles differ, the merged ones are in count mode, set
+// profiles counting a single hit per covered block.  The input profiles are
+// left unchanged.
+func MergeProfiles(a, b []*Profile, mode MergeMode) ([]*Profile, error) {
+	files := make(map[string]*Profile, len(a))
+	for _, profile := range append(a[:len(a):len(a)], b...) {
+		merged := files[profile.FileName]
+		if merged == nil {
+			files[profile.FileName] = &Profile{
+				FileName: profile.FileName,
+				Mode:     profile.Mode,
+				Blocks:   append([]ProfileBlock(nil), profile.Blocks...),
+			}
+			continue
+		}
+		if merged.Mode != profile.Mode {
+			merged.Mode = "count"
+		}
+		merged.Blocks = append(merged.Blocks, profile.Blocks...)
+	}
+
+	var err error
+	switch mode {
+	case MergeByMode:
+		for _, profile := range files {
+			if err = mergeSameLocationSamples(map[string]*Profile{profile.FileName: profile}, profile.Mode); err != nil {
+				break
+			}
+		}
+	case MergeSum:
+		for _, profile := range files {
+			if profile.Mode == "set" {
+				profile.Mode = "count"
+			}
+		}
+		err = mergeBlocks(files, func(a, b int) int { return a + b })
+	case MergeMax:
+		err = mergeBlocks(files, func(a, b int) int { return max(a, b) })
+	default:
+		return nil, fmt.Errorf("unknown merge mode %d", mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return generateSortedProfilesSlice(files), nil
+}
+
+// readLine reads a line of reader, without its end of line.
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readCobertura reads the Cobertura documents of in, which may hold several
+// of them one after the other, and merges them.
+func readCobertura(in io.Reader) (*Coverage, error) {
+	decoder := xml.NewDecoder(in)
+	var coverage *Coverage
+	for {
+		var doc Coverage
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read Cobertura report: %w", err)
+		}
+		if coverage == nil {
+			coverage = &doc
+			continue
+		}
+		if err := coverage.merge(&doc); err != nil {
+			return nil, err
+		}
+	}
+	if coverage == nil {
+		return nil, fmt.Errorf("read Cobertura report: no <coverage> element")
+	}
+	return coverage, nil
+}
+
+// convertCobertura reads and merges existing Cobertura reports, for the
+// cobertura input format.
+func convertCobertura(_ context.Context, in io.Reader, ignore Matcher, opts *Options, _ []string) (*Coverage, error) {
+	coverage, err := readCobertura(in)
+	if err != nil {
+		return nil, err
+	}
+	return coverage.finishRead(ignore, opts)
+}
+
+// finishRead drops the ignored classes of a coverage read from a report and
+// computes its rates.
+func (cov *Coverage) finishRead(ignore Matcher, opts *Options) (*Coverage, error) {
+	for _, pkg := range cov.Packages {
+		classes := pkg.Classes[:0]
+		for _, class := range pkg.Classes {
+			if !matchFile(ignore, class.Filename, nil) && excludedBy(ignore, class.Filename) == "" {
+				classes = append(classes, class)
+			}
+		}
+		pkg.Classes = classes
+	}
+	cov.Recompute()
+	cov.timestampMillis = opts.timestampMillis()
+
+	if opts.failOnEmpty() && cov.LinesValid == 0 {
+		return nil, errEmptyCoverage
+	}
+	return cov, nil
+}
+
+// merge adds the coverage of other, a Cobertura report read from a file, to
+// the coverage.  Packages, classes and methods are matched by name, and the
+// hits of the same lines are summed.
+func (cov *Coverage) merge(other *Coverage) error {
+	for _, source := range other.Sources {
+		cov.Sources = appendIfUnique(cov.Sources, source.Path)
+	}
+
+	packages := make(map[string]*Package, len(cov.Packages))
+	for _, pkg := range cov.Packages {
+		packages[pkg.Name] = pkg
+	}
+	for _, otherPkg := range other.Packages {
+		pkg := packages[otherPkg.Name]
+		if pkg == nil {
+			pkg = &Package{Name: otherPkg.Name}
+			packages[pkg.Name] = pkg
+			cov.Packages = append(cov.Packages, pkg)
+		}
+		if err := pkg.merge(otherPkg); err != nil {
+			return err
+		}
+	}
+
+	cov.Recompute()
+	return nil
+}
+
+func (pkg *Package) merge(other *Package) error {
+	type classKey struct{ name, filename string }
+	classes := make(map[classKey]*Class, len(pkg.Classes))
+	for _, class := range pkg.Classes {
+		classes[classKey{class.Name, class.Filename}] = class
+	}
+	for _, otherClass := range other.Classes {
+		key := classKey{otherClass.Name, otherClass.Filename}
+		class := classes[key]
+		if class == nil {
+			class = &Class{Name: otherClass.Name, Filename: otherClass.Filename, Methods: []*Method{}, Lines: Lines{}}
+			classes[key] = class
+			pkg.Classes = append(pkg.Classes, class)
+		} else if err := class.unspill(); err != nil {
+			return err
+		}
+
+		type methodKey struct{ name, signature string }
+		methods := make(map[methodKey]*Method, len(class.Methods))
+		for _, method := range class.Methods {
+			methods[methodKey{method.Name, method.Signature}] = method
+		}
+		for _, otherMethod := range otherClass.Methods {
+			key := methodKey{otherMethod.Name, otherMethod.Signature}
+			method := methods[key]
+			if method == nil {
+				method = &Method{Name: otherMethod.Name, Signature: otherMethod.Signature, Lines: Lines{}}
+				methods[key] = method
+				class.Methods = append(class.Methods, method)
+			}
+			method.Lines = mergeLines(method.Lines, otherMethod.Lines)
+		}
+		class.Lines = mergeLines(class.Lines, otherClass.Lines)
+	}
+	return nil
+}
+
+// mergeLines returns the lines of both, sorted by number, with the hits of
+// the same line summed.  The lines are copied, as the lines of a class are
+// shared with its methods.
+func mergeLines(lines, other Lines) Lines {
+	merged := make(Lines, 0, len(lines)+len(other))
+	byNumber := make(map[int]*Line, len(lines))
+	for _, line := range lines {
+		if byNumber[line.Number] == nil {
+			line := *line
+			byNumber[line.Number] = &line
+			merged = append(merged, &line)
+		}
+	}
+	for _, otherLine := range other {
+		if line := byNumber[otherLine.Number]; line != nil {
+			line.Hits += otherLine.Hits
+			line.mergeBranches(otherLine)
+			continue
+		}
+		line := *otherLine
+		byNumber[line.Number] = &line
+		merged = append(merged, &line)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Number < merged[j].Number })
+	return merged
+}
+
+// Recompute recomputes the rates of the methods, classes and packages and the
+// totals and rates of the coverage, after changes to their lines.
+func (cov *Coverage) Recompute() {
+	for _, pkg := range cov.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				if method.spilled == nil {
+					method.LineRate = method.HitRate()
+					method.BranchRate = branchRate(method.Lines.NumBranchesCovered(), method.Lines.NumBranches())
+				}
+			}
+			if class.spilled == nil {
+				class.LineRate = class.HitRate()
+				class.BranchRate = branchRate(class.NumBranchesCovered(), class.NumBranches())
+			}
+		}
+		pkg.LineRate = pkg.HitRate()
+		pkg.BranchRate = branchRate(pkg.NumBranchesCovered(), pkg.NumBranches())
+	}
+	cov.LinesValid = cov.NumLines()
+	cov.LinesCovered = cov.NumLinesWithHits()
+	cov.LineRate = cov.HitRate()
+	cov.BranchesValid = cov.NumBranches()
+	cov.BranchesCovered = cov.NumBranchesCovered()
+	cov.BranchRate = branchRate(cov.BranchesCovered, cov.BranchesValid)
+}
+
+// mergeReportFile merges the Cobertura or JaCoCo report of the named file or
+// URL into the coverage.
+func mergeReportFile(coverage *Coverage, opener *inputOpener, name string) error {
+	other, err := readReportFile(opener, name)
+	if err != nil {
+		return err
+	}
+	return coverage.merge(other)
+}
+
+// readReportFile reads the Cobertura or JaCoCo report of the named file or
+// URL.
+func readReportFile(opener *inputOpener, name string) (*Coverage, error) {
+	file, err := opener.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	in := bufio.NewReader(newGunzipReader(file))
+	read := readCobertura
+	if isJaCoCo(in) {
+		read = readJaCoCo
+	}
+	return read(in)
+}
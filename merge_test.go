@@ -0,0 +1,336 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStringList(t *testing.T) {
+	t.Parallel()
+
+	var list stringList
+	for _, value := range []string{"a.out", "b.out, c.out", ""} {
+		if err := list.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if list.String() != "a.out,b.out,c.out" {
+		t.Errorf("unexpected list %q", list.String())
+	}
+}
+
+func TestMergeProfileReaders(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		inputs   []string
+		expected []ProfileBlock
+	}{
+		{
+			name: "count",
+			inputs: []string{
+				"mode: count\nexample.com/a.go:1.1,2.2 1 3\nexample.com/a.go:3.1,4.2 1 0",
+				"mode: atomic\nexample.com/a.go:1.1,2.2 1 2\nexample.com/a.go:3.1,4.2 1 1\n",
+			},
+			expected: []ProfileBlock{
+				{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 5},
+				{StartLine: 3, StartCol: 1, EndLine: 4, EndCol: 2, NumStmt: 1, Count: 1},
+			},
+		},
+		{
+			name: "set",
+			inputs: []string{
+				"mode: set\nexample.com/a.go:1.1,2.2 1 1\nexample.com/a.go:3.1,4.2 1 0\n",
+				"mode: set\nexample.com/a.go:1.1,2.2 1 1\n",
+			},
+			expected: []ProfileBlock{
+				{StartLine: 1, StartCol: 1, EndLine: 2, EndCol: 2, NumStmt: 1, Count: 1},
+				{StartLine: 3, StartCol: 1, EndLine: 4, EndCol: 2, NumStmt: 1, Count: 0},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var inputs []io.Reader
+			for _, input := range tc.inputs {
+				inputs = append(inputs, strings.NewReader(input))
+			}
+			profiles, err := ParseProfiles(mergeProfileReaders(inputs, defaultMaxProfileLine), &Ignore{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(profiles) != 1 || len(profiles[0].Blocks) != len(tc.expected) {
+				t.Fatalf("unexpected profiles %+v", profiles)
+			}
+			for i, block := range profiles[0].Blocks {
+				if block != tc.expected[i] {
+					t.Errorf("block %d: got %+v, expected %+v", i, block, tc.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMergeProfileReadersMixedModes(t *testing.T) {
+	t.Parallel()
+
+	inputs := []io.Reader{
+		strings.NewReader("mode: set\nexample.com/a.go:1.1,2.2 1 1\nexample.com/a.go:3.1,4.2 1 0\n"),
+		strings.NewReader("mode: count\nexample.com/a.go:1.1,2.2 1 4\nexample.com/a.go:3.1,4.2 1 2\n"),
+		strings.NewReader("mode: set\r\nexample.com/a.go:1.1,2.2 1 1\r\n"),
+	}
+	profiles, err := ParseProfiles(mergeProfileReaders(inputs, defaultMaxProfileLine), &Ignore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].Mode != "count" {
+		t.Fatalf("unexpected profiles %+v", profiles)
+	}
+	// set blocks are promoted to a single hit and summed
+	if counts := []int{profiles[0].Blocks[0].Count, profiles[0].Blocks[1].Count}; counts[0] != 6 || counts[1] != 2 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+}
+
+func TestMergeProfileReadersBadMode(t *testing.T) {
+	t.Parallel()
+
+	inputs := []io.Reader{
+		strings.NewReader("mode: set\nexample.com/a.go:1.1,2.2 1 1\n"),
+		strings.NewReader(""),
+	}
+	if _, err := ParseProfiles(mergeProfileReaders(inputs, defaultMaxProfileLine), &Ignore{}); err == nil {
+		t.Error("expected an error for a profile without a mode line")
+	}
+}
+
+func TestReadCoberturaMergesDocuments(t *testing.T) {
+	t.Parallel()
+
+	first := Coverage{
+		Sources: []*Source{{Path: "/src/go"}},
+		Packages: []*Package{{Name: "example.com/foo", Classes: []*Class{
+			{Name: "Foo", Filename: "foo/foo.go", Methods: []*Method{
+				{Name: "Get", Lines: Lines{{Number: 3, Hits: 1}, {Number: 4, Hits: 0}}},
+			}, Lines: Lines{{Number: 3, Hits: 1}, {Number: 4, Hits: 0}}},
+		}}},
+	}
+	second := Coverage{
+		Sources: []*Source{{Path: "/src/java"}},
+		Packages: []*Package{
+			{Name: "example.com/foo", Classes: []*Class{
+				{Name: "Foo", Filename: "foo/foo.go", Methods: []*Method{
+					{Name: "Get", Lines: Lines{{Number: 4, Hits: 2}}},
+					{Name: "Set", Lines: Lines{{Number: 8, Hits: 0}}},
+				}, Lines: Lines{{Number: 4, Hits: 2}, {Number: 8, Hits: 0}}},
+			}},
+			{Name: "com.example", Classes: []*Class{
+				{Name: "Bar", Filename: "com/example/Bar.java", Methods: []*Method{
+					{Name: "run", Lines: Lines{{Number: 1, Hits: 5}}},
+				}, Lines: Lines{{Number: 1, Hits: 5}}},
+			}},
+		},
+	}
+
+	var in strings.Builder
+	for _, doc := range []*Coverage{&first, &second} {
+		if err := doc.writeXML(&in); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	coverage, err := readCobertura(strings.NewReader(in.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(coverage.Sources) != 2 || len(coverage.Packages) != 2 {
+		t.Fatalf("unexpected sources or packages %+v", coverage)
+	}
+	if coverage.LinesCovered != 3 || coverage.LinesValid != 4 {
+		t.Errorf("unexpected totals %d/%d", coverage.LinesCovered, coverage.LinesValid)
+	}
+	class := coverage.Packages[0].Classes[0]
+	if len(class.Methods) != 2 || len(class.Lines) != 3 || class.Lines[1].Hits != 2 {
+		t.Errorf("unexpected merged class %+v", class)
+	}
+	if get := class.Methods[0]; get.LineRate != 1 {
+		t.Errorf("unexpected merged method %+v", get)
+	}
+}
+
+func TestMergeConvertedCoverage(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	coverage, err := convert(context.Background(), in, &Ignore{GeneratedFiles: true}, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	var report bytes.Buffer
+	if err := coverage.writeXML(&report); err != nil {
+		t.Fatal(err)
+	}
+	covered, valid := coverage.LinesCovered, coverage.LinesValid
+
+	other, err := readCobertura(&report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := coverage.merge(other); err != nil {
+		t.Fatal(err)
+	}
+
+	// merging a report with itself doubles the hits but covers the same lines
+	if coverage.LinesCovered != covered || coverage.LinesValid != valid {
+		t.Errorf("unexpected totals %d/%d, expected %d/%d", coverage.LinesCovered, coverage.LinesValid, covered, valid)
+	}
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				for index, line := range method.Lines {
+					if line.Hits%2 != 0 {
+						t.Errorf("%s.%s line %d: odd hits %d", class.Name, method.Name, index, line.Hits)
+					}
+				}
+			}
+			for _, line := range class.Lines {
+				if line.Hits%2 != 0 {
+					t.Errorf("%s line %d: odd hits %d", class.Name, line.Number, line.Hits)
+				}
+			}
+		}
+	}
+}
+
+func TestReadCoberturaEmpty(t *testing.T) {
+	t.Parallel()
+
+	if _, err := readCobertura(strings.NewReader("")); err == nil {
+		t.Error("expected an error for an empty input")
+	}
+}
+
+func TestExpandGlobs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for _, name := range []string{"shard1.out", "shard2.out", "other.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, err := expandGlobs([]string{
+		filepath.Join(dir, "*.out"), "unit.out", "https://example.com/a*.out",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		filepath.Join(dir, "shard1.out"), filepath.Join(dir, "shard2.out"), "unit.out", "https://example.com/a*.out",
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("unexpected names %q, expected %q", names, expected)
+	}
+
+	if _, err := expandGlobs([]string{filepath.Join(dir, "*.xml")}); err == nil {
+		t.Error("expected an error for a pattern without matches")
+	}
+}
+
+func TestMergeProfiles(t *testing.T) {
+	t.Parallel()
+
+	block := func(line, count int) ProfileBlock {
+		return ProfileBlock{StartLine: line, StartCol: 1, EndLine: line, EndCol: 10, NumStmt: 1, Count: count}
+	}
+	a := []*Profile{
+		{FileName: "p/a.go", Mode: "count", Blocks: []ProfileBlock{block(3, 2), block(1, 0)}},
+		{FileName: "p/s.go", Mode: "set", Blocks: []ProfileBlock{block(1, 1)}},
+	}
+	b := []*Profile{
+		{FileName: "p/a.go", Mode: "count", Blocks: []ProfileBlock{block(1, 4), block(3, 1)}},
+		{FileName: "p/b.go", Mode: "count", Blocks: []ProfileBlock{block(2, 1)}},
+		{FileName: "p/s.go", Mode: "set", Blocks: []ProfileBlock{block(1, 1)}},
+	}
+
+	for _, test := range []struct {
+		name     string
+		mode     MergeMode
+		counts   string
+		setModes string
+	}{
+		{"by mode", MergeByMode, "p/a.go:4,3 p/b.go:1 p/s.go:1", "set"},
+		{"sum", MergeSum, "p/a.go:4,3 p/b.go:1 p/s.go:2", "count"},
+		{"max", MergeMax, "p/a.go:4,2 p/b.go:1 p/s.go:1", "set"},
+	} {
+		merged, err := MergeProfiles(a, b, test.mode)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var files []string
+		for _, profile := range merged {
+			var counts []string
+			for _, block := range profile.Blocks {
+				counts = append(counts, strconv.Itoa(block.Count))
+			}
+			files = append(files, profile.FileName+":"+strings.Join(counts, ","))
+		}
+		if actual := strings.Join(files, " "); actual != test.counts {
+			t.Errorf("%s: merged %s, expected %s", test.name, actual, test.counts)
+		}
+		if mode := merged[2].Mode; mode != test.setModes {
+			t.Errorf("%s: set profile merged in %s mode, expected %s", test.name, mode, test.setModes)
+		}
+	}
+	if a[0].Blocks[0].Count != 2 || len(a[0].Blocks) != 2 {
+		t.Errorf("input profile changed: %+v", a[0])
+	}
+
+	b[0].Blocks[1].NumStmt = 2
+	if _, err := MergeProfiles(a, b, MergeSum); err == nil {
+		t.Error("expected an error for blocks with different statement counts")
+	}
+	if _, err := MergeProfiles(a, b, MergeMode(-1)); err == nil {
+		t.Error("expected an error for an unknown merge mode")
+	}
+}
+
+func TestMergeProfileReadersLongLine(t *testing.T) {
+	t.Parallel()
+
+	long := "example.com/" + strings.Repeat("x", 100_000) + ".go:1.1,2.2 1 1\n"
+	inputs := func() []io.Reader {
+		return []io.Reader{
+			strings.NewReader("mode: set\nexample.com/a.go:1.1,2.2 1 1\n"),
+			strings.NewReader("mode: set\nexample.com/a.go:1.1,2.2 1 0\n" + long),
+		}
+	}
+	profiles, err := ParseProfiles(mergeProfileReaders(inputs(), defaultMaxProfileLine), &Ignore{})
+	if err != nil || len(profiles) != 2 {
+		t.Fatalf("profiles %v, error %v, expected the long line beyond the default scanner token size", profiles, err)
+	}
+
+	_, err = ParseProfiles(mergeProfileReaders(inputs(), 1000), &Ignore{})
+	if err == nil || !strings.Contains(err.Error(), "profile 2: line 3 is longer than 1000 bytes") {
+		t.Errorf("error %v, expected the long line to be reported", err)
+	}
+}
@@ -0,0 +1,298 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Options holds the optional settings of a conversion.
+type Options struct {
+	// Logger receives structured events: the ignored files and why, the
+	// packages skipped or failing to load, the malformed profile lines and
+	// the skipped files as warnings, and the time spent per phase at the
+	// debug level.  Nothing is logged when it is nil.
+	Logger *slog.Logger
+
+	// MaxMemory bounds, in bytes, the line data held in memory.  Once
+	// reached, the lines are spilled to a temporary file and read back
+	// while encoding.  Zero means no bound.
+	MaxMemory int64
+
+	// Progress, when not nil, is called after each package is converted
+	// with the number of packages converted so far and their total.
+	Progress func(done, total int)
+
+	// Strict fails the conversion, listing all of them, if the source of
+	// any profile is missing or unparsable, before converting anything.
+	Strict bool
+
+	// KeepGoing skips, with a warning, the files whose source is missing or
+	// unparsable instead of failing the conversion.
+	KeepGoing bool
+
+	// Partial, when files fail without KeepGoing, returns the coverage of
+	// the other files along with the error listing the failed ones, which
+	// ConvertContext writes before returning that error.
+	Partial bool
+
+	// Parallel is the number of files parsed concurrently.  Zero means
+	// GOMAXPROCS.
+	Parallel int
+
+	// FS, when not nil, is the file system the sources are read from, such
+	// as a snapshot of the source tree or an in-memory fstest.MapFS, instead
+	// of the host one.  The sources are opened at their absolute paths on
+	// the host, as listed by go list, in slash form without the leading
+	// slash and volume name, as home/ci/src/mod/main.go.
+	FS fs.FS
+
+	// PackageCache, when not empty, is the directory where the packages
+	// loaded for the profiles are cached across conversions.  The entries
+	// are keyed by the packages, the build tags, the Go environment and the
+	// go.mod, go.sum and go.work files, and are dropped once files are
+	// added to or removed from the directories of their packages.
+	PackageCache string
+
+	// FileCache, when not empty, is the directory where the classes of the
+	// converted files are cached across conversions, so that the files
+	// whose source, profile blocks and conversion flags are unchanged are
+	// neither parsed nor walked again.  Only the conversions with an Ignore
+	// matcher, or none, are cached, as the key cannot hold the behaviour of
+	// other matchers.
+	FileCache string
+
+	// ByFiles reports a class per file, named after its path, rather than
+	// per receiver type.
+	ByFiles bool
+
+	// ClassNaming names the classes of the functions: "receiver", the
+	// default, "file", "file-basename", "package+file" or "receiver+file".
+	ClassNaming string
+
+	// GenericReceivers names the classes of generic receivers "canonical",
+	// the default, as Cache[K,V], or "strip", as Cache.
+	GenericReceivers string
+
+	// MethodSignatures sets the signature of methods to their parameter and
+	// result types.
+	MethodSignatures bool
+
+	// QualifiedMethods names methods Type.Method, qualified by their
+	// receiver type.
+	QualifiedMethods bool
+
+	// InitClass puts the init functions in a class named <init> rather than
+	// with the other functions.
+	InitClass bool
+
+	// Statements counts statements instead of lines in the line rates and
+	// totals, as go tool cover -func.
+	Statements bool
+
+	// PackageNaming names the packages by their "import-path", the default,
+	// or by the "directory" of their class filenames.
+	PackageNaming string
+
+	// FilenameStyle makes the class filenames, and the sources they are
+	// relative to, "module-relative", the default, "repo-relative" or
+	// "absolute".
+	FilenameStyle string
+
+	// ModuleFilenames prefixes the module-relative class filenames with the
+	// module path.
+	ModuleFilenames bool
+
+	// Devendor reports the vendored files under their upstream import
+	// paths.
+	Devendor bool
+
+	// TrimModulePrefix strips the module path from the package names and
+	// class filenames.
+	TrimModulePrefix bool
+
+	// FailOnEmpty fails the conversion if the profile has no blocks or
+	// every entry was ignored.
+	FailOnEmpty bool
+
+	// TimestampMillis writes the timestamp of the Cobertura report in
+	// milliseconds, as the earlier versions, rather than in seconds.
+	TimestampMillis bool
+
+	// MaxProfileLine bounds, in bytes, the length of the lines of the
+	// profiles.  Zero means 1 MiB.
+	MaxProfileLine int
+
+	// timings records the time spent per phase for -timings.
+	timings *phaseTimings
+}
+
+// check returns an error if a naming or style of the options is unknown.
+func (opts *Options) check() error {
+	if opts == nil {
+		return nil
+	}
+	for _, setting := range []struct {
+		field, value string
+		known        []string
+	}{
+		{"ClassNaming", opts.ClassNaming, []string{classNamingReceiver, classNamingFile, classNamingFileBasename, classNamingPackageFile, classNamingReceiverFile}},
+		{"GenericReceivers", opts.GenericReceivers, []string{genericReceiversCanonical, genericReceiversStrip}},
+		{"PackageNaming", opts.PackageNaming, []string{packageNamingImportPath, packageNamingDirectory}},
+		{"FilenameStyle", opts.FilenameStyle, []string{filenameStyleModule, filenameStyleRepo, filenameStyleAbsolute}},
+	} {
+		if setting.value != "" && !slices.Contains(setting.known, setting.value) {
+			return fmt.Errorf("unknown Options.%s %q, expected one of %s", setting.field, setting.value, strings.Join(setting.known, ", "))
+		}
+	}
+	return nil
+}
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func (opts *Options) maxMemory() int64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.MaxMemory
+}
+
+func (opts *Options) progress() func(done, total int) {
+	if opts == nil || opts.Progress == nil {
+		return func(int, int) {}
+	}
+	return opts.Progress
+}
+
+func (opts *Options) strict() bool {
+	return opts != nil && opts.Strict
+}
+
+func (opts *Options) keepGoing() bool {
+	return opts != nil && opts.KeepGoing
+}
+
+func (opts *Options) partial() bool {
+	return opts != nil && opts.Partial
+}
+
+func (opts *Options) parallel() int {
+	if opts == nil || opts.Parallel <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return opts.Parallel
+}
+
+func (opts *Options) sourceFS() fs.FS {
+	if opts == nil {
+		return nil
+	}
+	return opts.FS
+}
+
+func (opts *Options) packageCache() string {
+	if opts == nil {
+		return ""
+	}
+	return opts.PackageCache
+}
+
+func (opts *Options) fileCache() string {
+	if opts == nil {
+		return ""
+	}
+	return opts.FileCache
+}
+
+func (opts *Options) phaseTimings() *phaseTimings {
+	if opts == nil {
+		return nil
+	}
+	return opts.timings
+}
+
+func (opts *Options) byFiles() bool {
+	return opts != nil && opts.ByFiles
+}
+
+func (opts *Options) classNaming() string {
+	if opts == nil || opts.ClassNaming == "" {
+		return classNamingReceiver
+	}
+	return opts.ClassNaming
+}
+
+func (opts *Options) genericReceivers() string {
+	if opts == nil || opts.GenericReceivers == "" {
+		return genericReceiversCanonical
+	}
+	return opts.GenericReceivers
+}
+
+func (opts *Options) methodSignatures() bool {
+	return opts != nil && opts.MethodSignatures
+}
+
+func (opts *Options) qualifiedMethods() bool {
+	return opts != nil && opts.QualifiedMethods
+}
+
+func (opts *Options) initClass() bool {
+	return opts != nil && opts.InitClass
+}
+
+func (opts *Options) statements() bool {
+	return opts != nil && opts.Statements
+}
+
+func (opts *Options) packageNaming() string {
+	if opts == nil || opts.PackageNaming == "" {
+		return packageNamingImportPath
+	}
+	return opts.PackageNaming
+}
+
+func (opts *Options) filenameStyle() string {
+	if opts == nil || opts.FilenameStyle == "" {
+		return filenameStyleModule
+	}
+	return opts.FilenameStyle
+}
+
+func (opts *Options) moduleFilenames() bool {
+	return opts != nil && opts.ModuleFilenames
+}
+
+func (opts *Options) devendor() bool {
+	return opts != nil && opts.Devendor
+}
+
+func (opts *Options) trimModulePrefix() bool {
+	return opts != nil && opts.TrimModulePrefix
+}
+
+func (opts *Options) failOnEmpty() bool {
+	return opts != nil && opts.FailOnEmpty
+}
+
+func (opts *Options) timestampMillis() bool {
+	return opts != nil && opts.TimestampMillis
+}
+
+func (opts *Options) maxProfileLine() int {
+	if opts == nil || opts.MaxProfileLine <= 0 {
+		return defaultMaxProfileLine
+	}
+	return opts.MaxProfileLine
+}
+
+func (opts *Options) logger() *slog.Logger {
+	if opts == nil || opts.Logger == nil {
+		return discardLogger
+	}
+	return opts.Logger
+}
@@ -0,0 +1,72 @@
+package cobertura
+
+// fileResult is the outcome of parsing the file of a profile.
+type fileResult struct {
+	file *parsedFile
+	err  error
+}
+
+// fileResults delivers, in the order of the profiles, the files parsed by a
+// pool of workers, so that the coverage is built the same way whatever the
+// number of workers.
+type fileResults struct {
+	results []chan fileResult
+	index   int
+	ahead   chan struct{} // bounds the files parsed but not delivered yet
+	stopped chan struct{}
+}
+
+// parseFiles starts parsing the files of the profiles with parse, on
+// parallel workers.  The caller must call stop once done with the results.
+func parseFiles(profiles []*Profile, parallel int, parse func(*Profile) (*parsedFile, error)) *fileResults {
+	if parallel < 1 {
+		parallel = 1
+	}
+	r := &fileResults{
+		results: make([]chan fileResult, len(profiles)),
+		ahead:   make(chan struct{}, 2*parallel),
+		stopped: make(chan struct{}),
+	}
+	for index := range r.results {
+		r.results[index] = make(chan fileResult, 1)
+	}
+
+	jobs := make(chan int)
+	for worker := 0; worker < parallel; worker++ {
+		go func() {
+			for index := range jobs {
+				file, err := parse(profiles[index])
+				r.results[index] <- fileResult{file: file, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for index := range profiles {
+			select {
+			case r.ahead <- struct{}{}:
+			case <-r.stopped:
+				return
+			}
+			select {
+			case jobs <- index:
+			case <-r.stopped:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// next waits for the file of the next profile.
+func (r *fileResults) next() (*parsedFile, error) {
+	result := <-r.results[r.index]
+	r.index++
+	<-r.ahead
+	return result.file, result.err
+}
+
+// stop stops parsing the files not started yet.
+func (r *fileResults) stop() {
+	close(r.stopped)
+}
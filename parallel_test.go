@@ -0,0 +1,83 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseFilesOrder(t *testing.T) {
+	t.Parallel()
+
+	profiles := make([]*Profile, 20)
+	indexes := make(map[*Profile]int, len(profiles))
+	for index := range profiles {
+		profiles[index] = &Profile{FileName: fmt.Sprintf("f%d.go", index)}
+		indexes[profiles[index]] = index
+	}
+	parsed := parseFiles(profiles, 4, func(profile *Profile) (*parsedFile, error) {
+		// finish the first files last
+		time.Sleep(time.Duration(len(profiles)-indexes[profile]) * time.Millisecond)
+		return &parsedFile{profile: profile}, nil
+	})
+	defer parsed.stop()
+
+	for _, profile := range profiles {
+		file, err := parsed.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.profile != profile {
+			t.Fatalf("file of %s, expected %s", file.profile.FileName, profile.FileName)
+		}
+	}
+}
+
+func TestParseFilesStop(t *testing.T) {
+	t.Parallel()
+
+	profiles := make([]*Profile, 100)
+	for index := range profiles {
+		profiles[index] = &Profile{}
+	}
+	parsed := parseFiles(profiles, 2, func(profile *Profile) (*parsedFile, error) {
+		return nil, nil
+	})
+	if _, err := parsed.next(); err != nil {
+		t.Fatal(err)
+	}
+	parsed.stop()
+}
+
+func TestConvertParallel(t *testing.T) {
+	t.Parallel()
+
+	convertWith := func(parallel int) []byte {
+		in, err := os.Open("testdata/testdata_set.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer in.Close()
+
+		coverage, err := convert(context.Background(), in, &Ignore{}, &Options{Parallel: parallel}, []string{"testdata"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer coverage.close()
+		coverage.Timestamp = 0
+
+		var out bytes.Buffer
+		if err := coverage.writeXML(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out.Bytes()
+	}
+
+	sequential := convertWith(1)
+	if parallel := convertWith(8); !bytes.Equal(parallel, sequential) {
+		t.Errorf("parallel report differs:\n%s\nexpected:\n%s", parallel, sequential)
+	}
+}
@@ -0,0 +1,76 @@
+package cobertura
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pathMapping rewrites the paths starting with from to start with to.
+type pathMapping struct {
+	from, to string
+}
+
+// pathMapList is a flag of "from=to" path mappings, which may be repeated.
+type pathMapList []pathMapping
+
+func (l *pathMapList) String() string {
+	mappings := make([]string, len(*l))
+	for index, mapping := range *l {
+		mappings[index] = mapping.from + "=" + mapping.to
+	}
+	return strings.Join(mappings, " ")
+}
+
+func (l *pathMapList) Set(value string) error {
+	from, to, ok := strings.Cut(value, "=")
+	if !ok || from == "" {
+		return fmt.Errorf("bad path mapping %q, expected from=to", value)
+	}
+	*l = append(*l, pathMapping{from: from, to: to})
+	return nil
+}
+
+// mapPath returns p rewritten by the first mapping whose from is p or one
+// of its parent directories, or p itself if none matches.
+func mapPath(p string, mappings []pathMapping) string {
+	for _, mapping := range mappings {
+		from := strings.TrimSuffix(mapping.from, "/")
+		if p == from {
+			return mapping.to
+		}
+		if rest, ok := strings.CutPrefix(p, from+"/"); ok {
+			if mapping.to == "" {
+				return rest
+			}
+			return strings.TrimSuffix(mapping.to, "/") + "/" + rest
+		}
+	}
+	return p
+}
+
+// remapPaths rewrites the source paths and the class filenames of the
+// coverage by the mappings, then replaces the sources by root if not empty,
+// so that a report built in a container matches the paths of the machine
+// rendering it.
+func (cov *Coverage) remapPaths(root string, mappings []pathMapping) {
+	if len(mappings) > 0 {
+		for _, source := range cov.Sources {
+			source.Path = mapPath(source.Path, mappings)
+		}
+		for _, pkg := range cov.Packages {
+			for _, class := range pkg.Classes {
+				class.Filename = mapPath(class.Filename, mappings)
+			}
+		}
+		if cov.sourceFiles != nil {
+			sourceFiles := make(map[string]sourceFile, len(cov.sourceFiles))
+			for fileName, file := range cov.sourceFiles {
+				sourceFiles[mapPath(fileName, mappings)] = file
+			}
+			cov.sourceFiles = sourceFiles
+		}
+	}
+	if root != "" {
+		cov.Sources = []*Source{{Path: root}}
+	}
+}
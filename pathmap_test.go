@@ -0,0 +1,74 @@
+package cobertura
+
+import (
+	"testing"
+)
+
+func TestMapPath(t *testing.T) {
+	t.Parallel()
+
+	mappings := []pathMapping{
+		{from: "/go/src/app/", to: "/workspace/app"},
+		{from: "/go/src", to: "/workspace/src"},
+		{from: "internal", to: ""},
+	}
+	for p, expected := range map[string]string{
+		"/go/src/app":            "/workspace/app",
+		"/go/src/app/main.go":    "/workspace/app/main.go",
+		"/go/src/lib/lib.go":     "/workspace/src/lib/lib.go",
+		"/go/src/application.go": "/workspace/src/application.go",
+		"/go/srcs/main.go":       "/go/srcs/main.go",
+		"internal/foo/foo.go":    "foo/foo.go",
+		"cmd/main.go":            "cmd/main.go",
+	} {
+		if actual := mapPath(p, mappings); actual != expected {
+			t.Errorf("mapPath(%q) = %q, expected %q", p, actual, expected)
+		}
+	}
+}
+
+func TestPathMapListSet(t *testing.T) {
+	t.Parallel()
+
+	var mappings pathMapList
+	if err := mappings.Set("/go/src=/workspace"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mappings.Set("a==b"); err != nil {
+		t.Fatal(err)
+	}
+	if actual := mappings.String(); actual != "/go/src=/workspace a==b" {
+		t.Errorf("mappings %s", actual)
+	}
+	for _, value := range []string{"/go/src", "=/workspace"} {
+		if err := mappings.Set(value); err == nil {
+			t.Errorf("no error for %q", value)
+		}
+	}
+}
+
+func TestRemapPaths(t *testing.T) {
+	t.Parallel()
+
+	class := &Class{Name: "-", Filename: "/go/src/app/main.go"}
+	coverage := &Coverage{
+		Sources:     []*Source{{Path: "/go/src/app"}},
+		Packages:    []*Package{{Name: "app", Classes: []*Class{class}}},
+		sourceFiles: map[string]sourceFile{"/go/src/app/main.go": {path: "/go/src/app/main.go"}},
+	}
+	coverage.remapPaths("", []pathMapping{{from: "/go/src/app", to: "/workspace/app"}})
+	if coverage.Sources[0].Path != "/workspace/app" {
+		t.Errorf("source %s", coverage.Sources[0].Path)
+	}
+	if class.Filename != "/workspace/app/main.go" {
+		t.Errorf("class filename %s", class.Filename)
+	}
+	if file, ok := coverage.sourceFiles["/workspace/app/main.go"]; !ok || file.path != "/go/src/app/main.go" {
+		t.Errorf("source files %v", coverage.sourceFiles)
+	}
+
+	coverage.remapPaths("/jenkins/app", nil)
+	if len(coverage.Sources) != 1 || coverage.Sources[0].Path != "/jenkins/app" {
+		t.Errorf("sources %v", coverage.Sources)
+	}
+}
@@ -0,0 +1,39 @@
+package cobertura
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isAbsPath reports whether p is an absolute path of any platform, as found
+// in the profiles written on Windows: with a drive letter, as C:\src\app, or
+// a UNC path, as \\server\share\app.
+func isAbsPath(p string) bool {
+	if filepath.IsAbs(p) || strings.HasPrefix(p, `\\`) || strings.HasPrefix(p, "//") {
+		return true
+	}
+	return len(p) >= 3 && isDriveLetter(p[0]) && p[1] == ':' && (p[2] == '\\' || p[2] == '/')
+}
+
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// samePath reports whether the paths name the same file, whatever their
+// separators and, for Windows paths, their case.
+func samePath(a, b string) bool {
+	a, b = strings.ReplaceAll(a, `\`, "/"), strings.ReplaceAll(b, `\`, "/")
+	windows := isWindowsPath(a) || isWindowsPath(b)
+	a, b = path.Clean(a), path.Clean(b)
+	if windows {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// isWindowsPath reports whether the slash separated path has a drive letter
+// or is a UNC path.
+func isWindowsPath(p string) bool {
+	return strings.HasPrefix(p, "//") || (len(p) >= 2 && isDriveLetter(p[0]) && p[1] == ':')
+}
@@ -0,0 +1,77 @@
+package cobertura
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestIsAbsPath(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		path     string
+		expected bool
+	}{
+		{"/home/app", true},
+		{`C:\src\app`, true},
+		{"c:/src/app", true},
+		{`\\server\share\app`, true},
+		{"example.com/app", false},
+		{"C:app", false},
+	} {
+		if actual := isAbsPath(test.path); actual != test.expected {
+			t.Errorf("isAbsPath(%s) = %t, expected %t", test.path, actual, test.expected)
+		}
+	}
+}
+
+func TestSamePath(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		a, b     string
+		expected bool
+	}{
+		{`C:\src\app`, "c:/src/app/", true},
+		{`\\Server\share\app`, "//server/share/app", true},
+		{"/home/app", "/home/app/", true},
+		{"/home/App", "/home/app", false},
+		{`C:\src\app`, `C:\src\other`, false},
+	} {
+		if actual := samePath(test.a, test.b); actual != test.expected {
+			t.Errorf("samePath(%s, %s) = %t, expected %t", test.a, test.b, actual, test.expected)
+		}
+	}
+}
+
+func TestLookupPackageByDir(t *testing.T) {
+	t.Parallel()
+
+	app := &packages.Package{ID: "example.com/app", GoFiles: []string{`C:\src\app\main.go`}}
+	pkgMap := map[string]*packages.Package{app.ID: app}
+	if pkg := lookupPackage(pkgMap, getPackageName(`c:\src\app\main.go`)); pkg != app {
+		t.Errorf("package %v, expected example.com/app by its directory", pkg)
+	}
+	if pkg := lookupPackage(pkgMap, `C:\src\other`); pkg != nil {
+		t.Errorf("package %v of another directory, expected none", pkg)
+	}
+}
+
+func TestFindAbsFilePathCase(t *testing.T) {
+	t.Parallel()
+
+	pkg := &packages.Package{GoFiles: []string{"/src/app/Main.go", "/src/app/util.go", "/src/app/Util.go"}}
+	for _, test := range []struct {
+		profileName, expected string
+	}{
+		{"example.com/app/main.go", "/src/app/Main.go"},
+		{"example.com/app/Util.go", "/src/app/Util.go"},
+		{"example.com/app/UTIL.go", ""},
+		{`C:\src\app\MAIN.go`, "/src/app/Main.go"},
+	} {
+		if actual := findAbsFilePath(pkg, test.profileName, nil); actual != test.expected {
+			t.Errorf("%s: path %q, expected %q", test.profileName, actual, test.expected)
+		}
+	}
+}
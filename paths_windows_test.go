@@ -0,0 +1,37 @@
+//go:build windows
+
+package cobertura
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestWindowsPaths(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		classFileName, absPath, expected string
+	}{
+		{"internal/a.go", `C:\src\app\internal\a.go`, `C:\src\app`},
+		{"internal/a.go", `c:\src\app\Internal\a.go`, `c:\src\app`},
+		{"a.go", `\\server\share\app\a.go`, `\\server\share\app`},
+		{"C:/src/app/a.go", `C:\src\app\a.go`, "/"},
+	} {
+		if actual := fileSource(test.classFileName, test.absPath); actual != test.expected {
+			t.Errorf("%s in %s: source %q, expected %q", test.classFileName, test.absPath, actual, test.expected)
+		}
+	}
+
+	module := &packages.Module{Path: "example.com/app", Dir: `C:\src\app`}
+	if actual := moduleFileName("a.go", `c:\src\app\internal\a.go`, module); actual != "internal/a.go" {
+		t.Errorf("file name %s, expected internal/a.go", actual)
+	}
+	if actual := fsPath(`\\server\share\app\a.go`); actual != "app/a.go" {
+		t.Errorf("io/fs path %s, expected app/a.go", actual)
+	}
+	if actual := fsPath(`C:\src\app\a.go`); actual != "src/app/a.go" {
+		t.Errorf("io/fs path %s, expected src/app/a.go", actual)
+	}
+}
@@ -0,0 +1,216 @@
+package cobertura
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// packageCacheVersion is changed with the layout of the cache entries, so
+// that the entries of other versions are missed.
+const packageCacheVersion = 1
+
+// packageCacheEntry is a cache file: the packages listed by go list and
+// the modification times of their directories when they were listed.
+type packageCacheEntry struct {
+	Version  int
+	Dirs     map[string]int64
+	Packages []*cachedPackage
+}
+
+// cachedPackage holds the fields of a package loaded by listPackages.
+type cachedPackage struct {
+	ID           string
+	Name         string
+	PkgPath      string
+	GoFiles      []string
+	OtherFiles   []string
+	IgnoredFiles []string
+	Module       *packages.Module
+}
+
+// loadPackages lists the packages, from the cache in cacheDir when it
+// holds them and their directories have not changed since, adding them to
+// it otherwise.  The cache is skipped if cacheDir is empty.
+func loadPackages(ctx context.Context, pkgNames, buildTags []string, cacheDir string, logger *slog.Logger) ([]*packages.Package, error) {
+	if cacheDir == "" {
+		return listPackages(ctx, pkgNames, buildTags)
+	}
+	key, err := packageCacheKey(pkgNames, buildTags)
+	if err != nil {
+		logger.Warn("cannot use the package cache", "error", err)
+		return listPackages(ctx, pkgNames, buildTags)
+	}
+	path := filepath.Join(cacheDir, key+".json")
+	if pkgs := readPackageCache(path); pkgs != nil {
+		logger.Debug("package cache hit", "file", path)
+		return pkgs, nil
+	}
+	logger.Debug("package cache miss", "file", path)
+
+	pkgs, err := listPackages(ctx, pkgNames, buildTags)
+	if err != nil {
+		return pkgs, err
+	}
+	for _, pkg := range pkgs {
+		if pkg == nil || len(pkg.Errors) > 0 {
+			// the errors may be transient, as modules failing to download
+			return pkgs, nil
+		}
+	}
+	if err := writePackageCache(path, pkgs); err != nil {
+		logger.Warn("cannot write the package cache", "file", path, "error", err)
+	}
+	return pkgs, nil
+}
+
+// packageCacheKey returns the cache key of the packages: a hash of their
+// names, the build tags, the working directory, the Go environment and the
+// go.mod, go.sum and go.work files, which decide the packages go list
+// finds.
+func packageCacheKey(pkgNames, buildTags []string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	names := append([]string(nil), pkgNames...)
+	sort.Strings(names)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "version %d\nwd %s\ntags %s\n", packageCacheVersion, wd, strings.Join(buildTags, ","))
+	fmt.Fprintf(hash, "goroot %s\ngoos %s\ngoarch %s\n", build.Default.GOROOT, build.Default.GOOS, build.Default.GOARCH)
+	for _, env := range []string{"GOFLAGS", "GOWORK", "GO111MODULE", "GOPATH"} {
+		fmt.Fprintf(hash, "%s %s\n", env, os.Getenv(env))
+	}
+	for index, name := range names {
+		if index == 0 || name != names[index-1] {
+			fmt.Fprintf(hash, "package %s\n", name)
+		}
+	}
+	for _, file := range moduleFiles(wd) {
+		data, err := os.ReadFile(file)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		fmt.Fprintf(hash, "file %s %x\n", file, sha256.Sum256(data))
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// moduleFiles returns the go.mod and go.sum files of the module holding
+// dir, and the go.work and go.work.sum files of its workspace, found in
+// the closest directories holding them.
+func moduleFiles(dir string) []string {
+	var files []string
+	for _, names := range [][]string{{"go.mod", "go.sum"}, {"go.work", "go.work.sum"}} {
+		for current := dir; ; {
+			if _, err := os.Stat(filepath.Join(current, names[0])); err == nil {
+				files = append(files, filepath.Join(current, names[0]), filepath.Join(current, names[1]))
+				break
+			}
+			parent := filepath.Dir(current)
+			if parent == current {
+				break
+			}
+			current = parent
+		}
+	}
+	return files
+}
+
+// packageDirs returns the modification times of the directories of the
+// files of the packages, which change as files are added, removed or
+// renamed.
+func packageDirs(pkgs []*cachedPackage) (map[string]int64, error) {
+	dirs := map[string]int64{}
+	for _, pkg := range pkgs {
+		for _, files := range [][]string{pkg.GoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+			for _, file := range files {
+				dir := filepath.Dir(file)
+				if _, ok := dirs[dir]; ok {
+					continue
+				}
+				info, err := os.Stat(dir)
+				if err != nil {
+					return nil, err
+				}
+				dirs[dir] = info.ModTime().UnixNano()
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// readPackageCache returns the packages of the cache file, or nil if it
+// cannot be read or the directories of the packages changed since.
+func readPackageCache(path string) []*packages.Package {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry packageCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != packageCacheVersion {
+		return nil
+	}
+	dirs, err := packageDirs(entry.Packages)
+	if err != nil || len(dirs) != len(entry.Dirs) {
+		return nil
+	}
+	for dir, modTime := range dirs {
+		if entry.Dirs[dir] != modTime {
+			return nil
+		}
+	}
+	pkgs := make([]*packages.Package, len(entry.Packages))
+	for index, cached := range entry.Packages {
+		pkgs[index] = &packages.Package{
+			ID:           cached.ID,
+			Name:         cached.Name,
+			PkgPath:      cached.PkgPath,
+			GoFiles:      cached.GoFiles,
+			OtherFiles:   cached.OtherFiles,
+			IgnoredFiles: cached.IgnoredFiles,
+			Module:       cached.Module,
+		}
+	}
+	return pkgs
+}
+
+// writePackageCache writes the packages to the cache file.
+func writePackageCache(path string, pkgs []*packages.Package) error {
+	entry := packageCacheEntry{Version: packageCacheVersion}
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		entry.Packages = append(entry.Packages, &cachedPackage{
+			ID:           pkg.ID,
+			Name:         pkg.Name,
+			PkgPath:      pkg.PkgPath,
+			GoFiles:      pkg.GoFiles,
+			OtherFiles:   pkg.OtherFiles,
+			IgnoredFiles: pkg.IgnoredFiles,
+			Module:       pkg.Module,
+		})
+	}
+	var err error
+	if entry.Dirs, err = packageDirs(entry.Packages); err != nil {
+		return err
+	}
+	data, err := json.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
@@ -0,0 +1,84 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestConvertPackageCache(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	convertCached := func() ([]byte, string) {
+		t.Helper()
+		in, err := os.Open("testdata/testdata_set.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer in.Close()
+
+		var logs bytes.Buffer
+		opts := &Options{PackageCache: cacheDir, Logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+		coverage, err := convert(context.Background(), in, &Ignore{}, opts, []string{"testdata"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer coverage.close()
+		coverage.Timestamp = 0
+
+		var out bytes.Buffer
+		if err := coverage.writeXML(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out.Bytes(), logs.String()
+	}
+
+	listed, logs := convertCached()
+	if !strings.Contains(logs, `msg="package cache miss"`) {
+		t.Errorf("no cache miss on the first conversion:\n%s", logs)
+	}
+	cached, logs := convertCached()
+	if !strings.Contains(logs, `msg="package cache hit"`) {
+		t.Errorf("no cache hit on the second conversion:\n%s", logs)
+	}
+	if !bytes.Equal(cached, listed) {
+		t.Errorf("report from the cached packages:\n%s\nexpected:\n%s", cached, listed)
+	}
+}
+
+func TestReadPackageCache(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	pkgs := []*packages.Package{{
+		ID:      "example.com/p",
+		GoFiles: []string{filepath.Join(dir, "p.go")},
+		Module:  &packages.Module{Path: "example.com/p", Dir: dir, Main: true},
+	}}
+	path := filepath.Join(t.TempDir(), "cache", "entry.json")
+	if err := writePackageCache(path, pkgs); err != nil {
+		t.Fatal(err)
+	}
+
+	read := readPackageCache(path)
+	if len(read) != 1 || read[0].ID != "example.com/p" || read[0].GoFiles[0] != pkgs[0].GoFiles[0] || !read[0].Module.Main {
+		t.Fatalf("cached packages %v, expected %v", read, pkgs)
+	}
+
+	// as when a file is added to the package
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(dir, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if read := readPackageCache(path); read != nil {
+		t.Errorf("cached packages %v, expected none once their directory changed", read)
+	}
+}
@@ -0,0 +1,132 @@
+package cobertura
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+)
+
+// commitEnvVars are the environment variables CI systems use to expose the
+// commit being built, in order of preference.
+var commitEnvVars = []string{"GIT_COMMIT", "GITHUB_SHA", "CI_COMMIT_SHA", "BUILD_SOURCEVERSION", "BUILD_VCS_NUMBER"}
+
+// runPostCommand runs command once the report has been written.  The command
+// is split into arguments by splitCommand and run without a shell, after
+// replacing the {output}, {total} and {commit} placeholders in each
+// argument.  The commit is only looked up for a command using it.
+func runPostCommand(command, output string, coverage *Coverage) error {
+	vars := map[string]string{
+		"{output}": output,
+		"{total}":  totalPercent(coverage.LineRate),
+	}
+	if strings.Contains(command, "{commit}") {
+		vars["{commit}"] = currentCommit()
+	}
+	args, err := expandPostCommand(command, vars)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("empty command")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // running a user command is the point
+	// stdout may hold the report already
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// totalPercent formats the line rate as a percentage for {total}, 0 for the
+// reports without lines, whose rate is NaN.
+func totalPercent(rate float32) string {
+	if math.IsNaN(float64(rate)) {
+		rate = 0
+	}
+	return fmt.Sprintf("%.2f", rate*100)
+}
+
+func expandPostCommand(command string, vars map[string]string) ([]string, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	for index, arg := range args {
+		for placeholder, value := range vars {
+			arg = strings.ReplaceAll(arg, placeholder, value)
+		}
+		args[index] = arg
+	}
+	return args, nil
+}
+
+// splitCommand splits the command into arguments on white spaces, as a shell
+// without expansions: single quotes keep what they enclose as is, double
+// quotes keep white spaces, and a backslash out of single quotes keeps the
+// next character.
+func splitCommand(command string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in command %q", quote, command)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in command %q", command)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// currentCommit returns the commit being built, as exposed by the CI or
+// reported by git, or an empty string if unknown.
+func currentCommit() string {
+	for _, name := range commitEnvVars {
+		if commit := os.Getenv(name); commit != "" {
+			return commit
+		}
+	}
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
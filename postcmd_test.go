@@ -0,0 +1,54 @@
+package cobertura
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandPostCommand(t *testing.T) {
+	t.Parallel()
+
+	args, err := expandPostCommand("  upload.sh --file={output}  {total}%\t{commit} {unknown} '{output} file'", map[string]string{
+		"{output}": "coverage.xml",
+		"{total}":  "83.33",
+		"{commit}": "abc123",
+	})
+	expected := []string{"upload.sh", "--file=coverage.xml", "83.33%", "abc123", "{unknown}", "coverage.xml file"}
+	if err != nil || !reflect.DeepEqual(args, expected) {
+		t.Errorf("expandPostCommand returned %q, %v but should be %q", args, err, expected)
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	t.Parallel()
+
+	for command, expected := range map[string][]string{
+		"":                               nil,
+		"notify.sh":                      {"notify.sh"},
+		`notify.sh "build passed" 'a b'`: {"notify.sh", "build passed", "a b"},
+		`say "it's done" 'say "hi"'`:     {"say", "it's done", `say "hi"`},
+		`a\ b c\\d ''`:                   {"a b", `c\d`, ""},
+		`--msg="x y"z`:                   {"--msg=x yz"},
+	} {
+		args, err := splitCommand(command)
+		if err != nil || !reflect.DeepEqual(args, expected) {
+			t.Errorf("splitCommand(%s) = %q, %v, expected %q", command, args, err, expected)
+		}
+	}
+	for _, command := range []string{`say "hi`, `say 'hi`, `say it's\ done`, `say hi\`} {
+		if _, err := splitCommand(command); err == nil {
+			t.Errorf("splitCommand(%s) should fail", command)
+		}
+	}
+}
+
+func TestTotalPercent(t *testing.T) {
+	t.Parallel()
+
+	// the rate of a report without lines is NaN
+	for rate, expected := range map[float32]string{0.8333: "83.33", 1: "100.00", NewCoverage("/src").HitRate(): "0.00"} {
+		if actual := totalPercent(rate); actual != expected {
+			t.Errorf("totalPercent(%v) = %s, expected %s", rate, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package cobertura
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ignorePresets are the base name patterns of the files written by common
+// code generators, by -ignore-preset name.
+var ignorePresets = map[string][]string{
+	"protobuf": {"*.pb.go", "*.pb.gw.go", "*.pb.validate.go"},
+	"mocks":    {"*_mock.go", "*_mocks.go", "mock_*.go", "mocks.go"},
+	"stringer": {"*_string.go"},
+	"wire":     {"wire_gen.go"},
+	"easyjson": {"*_easyjson.go"},
+	"enumer":   {"*_enumer.go"},
+}
+
+// presetNames returns the names of the ignore presets, sorted.
+func presetNames() []string {
+	names := make([]string, 0, len(ignorePresets))
+	for name := range ignorePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// presetList is a flag of ignore preset names, which may be repeated or
+// given a comma separated list, adding the patterns of the presets.
+type presetList []string
+
+func (l *presetList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *presetList) Set(value string) error {
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		patterns, ok := ignorePresets[name]
+		if !ok {
+			return fmt.Errorf("unknown preset %q, expected one of %s", name, strings.Join(presetNames(), ", "))
+		}
+		*l = append(*l, patterns...)
+	}
+	return nil
+}
@@ -5,10 +5,12 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package main
+package cobertura
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -38,26 +40,64 @@ func (p byFileName) Len() int           { return len(p) }
 func (p byFileName) Less(i, j int) bool { return p[i].FileName < p[j].FileName }
 func (p byFileName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 
-func ParseProfiles(in io.Reader, ignore *Ignore) ([]*Profile, error) {
+// ParseProfiles parses the go test coverage profile read from in, leaving
+// out the files matched by ignore, and returns a Profile per file, sorted by
+// file name.
+func ParseProfiles(in io.Reader, ignore Matcher) ([]*Profile, error) {
+	return ParseProfilesContext(context.Background(), in, ignore)
+}
+
+// ParseProfilesContext is like ParseProfiles, stopping with the error of ctx
+// once it is done.
+func ParseProfilesContext(ctx context.Context, in io.Reader, ignore Matcher) ([]*Profile, error) {
+	return parseProfiles(ctx, in, ignore, nil)
+}
+
+// defaultMaxProfileLine is the default bound of the length of the lines of
+// the profiles, far beyond the blocks written by go test, whose file names
+// are the longest part.
+const defaultMaxProfileLine = 1 << 20
+
+func parseProfiles(ctx context.Context, in io.Reader, ignore Matcher, opts *Options) ([]*Profile, error) {
+	logger, maxProfileLine := opts.logger(), opts.maxProfileLine()
 	files := make(map[string]*Profile)
+	ignored := make(map[string]string) // reason by file name
 	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, min(bufio.MaxScanTokenSize, maxProfileLine)), maxProfileLine)
 	mode := ""
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		err := parseLine(&mode, line, files, ignore)
+	lines := 1
+	for ; scanner.Scan(); lines++ {
+		if lines%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		// profiles written or edited on Windows may end their lines with CRLF
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		err := parseLine(&mode, line, files, ignored, ignore)
+		if errors.Is(err, errMalformedLine) {
+			logger.Warn("skipping malformed profile line", "line", lines, "text", line)
+			continue
+		}
 		if err != nil {
-			return nil, err
+			return nil, &profileParseError{err}
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan profiles: %w", err)
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return nil, &profileParseError{fmt.Errorf("scan profiles: line %d is longer than %d bytes, raise -max-profile-line: %w", lines, maxProfileLine, err)}
+	} else if err != nil {
+		return nil, fmt.Errorf("scan profiles: line %d: %w", lines, err)
+	}
+
+	for filename, reason := range ignored {
+		logger.Debug("ignoring file", "file", filename, "reason", reason)
 	}
 
 	err := mergeSameLocationSamples(files, mode)
 	if err != nil {
-		return nil, err
+		return nil, &profileParseError{err}
 	}
 
 	profiles := generateSortedProfilesSlice(files)
@@ -65,24 +105,60 @@ func ParseProfiles(in io.Reader, ignore *Ignore) ([]*Profile, error) {
 	return profiles, nil
 }
 
-func parseLine(mode *string, line string, files map[string]*Profile, ignore *Ignore) error {
+// profileParseError is an error of the content of a profile, as opposed
+// to the errors reading it.
+type profileParseError struct {
+	err error
+}
+
+func (e *profileParseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *profileParseError) Unwrap() error {
+	return e.err
+}
+
+// errMalformedLine is returned by parseLine for a line which is not a profile
+// block, skipped with a warning.
+var errMalformedLine = errors.New("malformed profile line")
+
+func parseLine(mode *string, line string, files map[string]*Profile, ignored map[string]string, ignore Matcher) error {
 	if *mode == "" {
 		const prefix = "mode: "
 
 		if !strings.HasPrefix(line, prefix) || line == prefix {
-			return fmt.Errorf("bad mode line: %s", line)
+			return fmt.Errorf("%w: %s", ErrBadModeLine, line)
 		}
 		*mode = line[len(prefix):]
 		return nil
 	}
 	match := lineRe.FindStringSubmatch(line)
 	if match == nil {
-		return nil
+		if line == "" || strings.HasPrefix(line, "mode: ") {
+			// blank lines and the mode lines of concatenated profiles
+			return nil
+		}
+		return errMalformedLine
 	}
 	filename := match[1]
-	if ignore.Match(filename, nil) {
+	if matchFile(ignore, filename, nil) {
+		ignored[filename] = ignoreReason(ignore, filename)
+		return nil
+	}
+	if reason := excludedBy(ignore, filename); reason != "" {
+		ignored[filename] = reason
 		return nil
 	}
+	var numbers [6]int
+	for index := range numbers {
+		number, err := strconv.Atoi(match[index+2])
+		if err != nil {
+			// beyond the range of int
+			return errMalformedLine
+		}
+		numbers[index] = number
+	}
 	profile := files[filename]
 	if profile == nil {
 		profile = &Profile{
@@ -93,18 +169,28 @@ func parseLine(mode *string, line string, files map[string]*Profile, ignore *Ign
 	}
 
 	profile.Blocks = append(profile.Blocks, ProfileBlock{
-		StartLine: toInt(match[2]),
-		StartCol:  toInt(match[3]),
-		EndLine:   toInt(match[4]),
-		EndCol:    toInt(match[5]),
-		NumStmt:   toInt(match[6]),
-		Count:     toInt(match[7]),
+		StartLine: numbers[0],
+		StartCol:  numbers[1],
+		EndLine:   numbers[2],
+		EndCol:    numbers[3],
+		NumStmt:   numbers[4],
+		Count:     numbers[5],
 	})
 
 	return nil
 }
 
 func mergeSameLocationSamples(files map[string]*Profile, mode string) error {
+	combine := func(a, b int) int { return a + b }
+	if mode == "set" {
+		combine = func(a, b int) int { return a | b }
+	}
+	return mergeBlocks(files, combine)
+}
+
+// mergeBlocks sorts the blocks of the profiles and replaces the blocks at
+// the same location by one, combining their counts.
+func mergeBlocks(files map[string]*Profile, combine func(a, b int) int) error {
 	for _, profile := range files {
 		sort.Sort(blocksByStart(profile.Blocks))
 		blockNo := 1
@@ -117,13 +203,10 @@ func mergeSameLocationSamples(files map[string]*Profile, mode string) error {
 				currentBlock.EndLine == last.EndLine &&
 				currentBlock.EndCol == last.EndCol {
 				if currentBlock.NumStmt != last.NumStmt {
-					return fmt.Errorf("inconsistent NumStmt: changed from %d to %d", last.NumStmt, currentBlock.NumStmt)
-				}
-				if mode == "set" {
-					profile.Blocks[blockNo-1].Count |= currentBlock.Count
-				} else {
-					profile.Blocks[blockNo-1].Count += currentBlock.Count
+					return fmt.Errorf("inconsistent NumStmt of %s:%d.%d,%d.%d: changed from %d to %d", profile.FileName,
+						last.StartLine, last.StartCol, last.EndLine, last.EndCol, last.NumStmt, currentBlock.NumStmt)
 				}
+				profile.Blocks[blockNo-1].Count = combine(last.Count, currentBlock.Count)
 
 				continue
 			}
@@ -157,14 +240,6 @@ func (b blocksByStart) Less(i, j int) bool {
 
 var lineRe = regexp.MustCompile(`^(.+):([0-9]+).([0-9]+),([0-9]+).([0-9]+) ([0-9]+) ([0-9]+)$`)
 
-func toInt(s string) int {
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		panic(err)
-	}
-	return i
-}
-
 // Boundary represents the position in a source file of the beginning or end of a
 // block as reported by the coverage profile. In HTML mode, it will correspond to
 // the opening or closing of a <span> tag and will be used to colorize the source.
@@ -0,0 +1,143 @@
+package cobertura
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// hiddenFlags are left out of the usage, as they are meant for reporting
+// the performance problems of the converter itself.
+var hiddenFlags = []string{"cpuprofile", "memprofile", "trace", "timings"}
+
+// printUsage prints the usage of the flags, without the hidden ones.
+func printUsage(flags *flag.FlagSet) {
+	out := flags.Output()
+	_, _ = fmt.Fprintf(out, "Usage of %s:\n", flags.Name())
+	visible := flag.NewFlagSet(flags.Name(), flag.ContinueOnError)
+	visible.SetOutput(out)
+	flags.VisitAll(func(f *flag.Flag) {
+		if !slices.Contains(hiddenFlags, f.Name) {
+			visible.Var(f.Value, f.Name, f.Usage)
+			visible.Lookup(f.Name).DefValue = f.DefValue
+		}
+	})
+	visible.PrintDefaults()
+}
+
+// startProfiling starts the CPU profile and the execution trace written to
+// the files, when not empty, and returns the function stopping them and
+// writing the heap profile to memProfile, when not empty.
+func startProfiling(cpuProfile, memProfile, traceFile string) (func() error, error) {
+	var stops []func() error
+	stop := func() error {
+		var err error
+		for _, stop := range stops {
+			if stopErr := stop(); err == nil {
+				err = stopErr
+			}
+		}
+		return err
+	}
+
+	if cpuProfile != "" {
+		file, err := os.Create(cpuProfile)
+		if err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return file.Close()
+		})
+	}
+	if traceFile != "" {
+		file, err := os.Create(traceFile)
+		if err != nil {
+			_ = stop()
+			return nil, err
+		}
+		if err := trace.Start(file); err != nil {
+			_ = file.Close()
+			_ = stop()
+			return nil, err
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return file.Close()
+		})
+	}
+	if memProfile != "" {
+		stops = append(stops, func() error {
+			file, err := os.Create(memProfile)
+			if err != nil {
+				return err
+			}
+			runtime.GC() // the live heap, up to date
+			err = pprof.WriteHeapProfile(file)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		})
+	}
+	return stop, nil
+}
+
+// phaseTimings records the time spent per phase of a conversion, printed
+// by -timings.  A nil phaseTimings records nothing.
+type phaseTimings struct {
+	mu     sync.Mutex
+	start  time.Time
+	phases []phaseTiming
+}
+
+// phaseTiming is the time spent in a phase.
+type phaseTiming struct {
+	name     string
+	duration time.Duration
+}
+
+func newPhaseTimings() *phaseTimings {
+	return &phaseTimings{start: time.Now()}
+}
+
+// record adds the time spent in the phase, to its previous times if any.
+func (t *phaseTimings) record(name string, duration time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for index := range t.phases {
+		if t.phases[index].name == name {
+			t.phases[index].duration += duration
+			return
+		}
+	}
+	t.phases = append(t.phases, phaseTiming{name, duration})
+}
+
+// write writes the time spent per phase, in the order they were first
+// recorded, then the total time since the timings were created.
+func (t *phaseTimings) write(out io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tabber := tabwriter.NewWriter(out, 1, 8, 2, ' ', 0)
+	for _, phase := range t.phases {
+		_, _ = fmt.Fprintf(tabber, "%s\t%s\n", phase.name, phase.duration.Round(time.Millisecond))
+	}
+	_, _ = fmt.Fprintf(tabber, "total\t%s\n", time.Since(t.start).Round(time.Millisecond))
+	return tabber.Flush()
+}
@@ -0,0 +1,78 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPhaseTimings(t *testing.T) {
+	t.Parallel()
+
+	timings := newPhaseTimings()
+	timings.record("parse profiles", time.Second)
+	timings.record("load packages", 2*time.Second)
+	timings.record("parse profiles", time.Second)
+
+	var out bytes.Buffer
+	if err := timings.write(&out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || lines[0] != "parse profiles  2s" || lines[1] != "load packages   2s" || !strings.HasPrefix(lines[2], "total") {
+		t.Errorf("timings:\n%s", out.String())
+	}
+
+	// nil timings record nothing
+	var none *phaseTimings
+	none.record("parse profiles", time.Second)
+}
+
+func TestConvertTimings(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	opts := &Options{Strict: true, timings: newPhaseTimings()}
+	coverage, err := convert(context.Background(), in, &Ignore{}, opts, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	var names []string
+	for _, phase := range opts.timings.phases {
+		names = append(names, phase.name)
+	}
+	if actual, expected := strings.Join(names, ", "), "parse profiles, load packages, check sources, convert files"; actual != expected {
+		t.Errorf("phases %s, expected %s", actual, expected)
+	}
+}
+
+func TestStartProfilingMemory(t *testing.T) {
+	t.Parallel()
+
+	memProfile := filepath.Join(t.TempDir(), "mem.prof")
+	stop, err := startProfiling("", memProfile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stop(); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(memProfile); err != nil || info.Size() == 0 {
+		t.Errorf("no heap profile written: %v", err)
+	}
+
+	if _, err := startProfiling(filepath.Join(t.TempDir(), "missing", "cpu.prof"), "", ""); err == nil {
+		t.Error("expected an error for a CPU profile in a missing directory")
+	}
+}
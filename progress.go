@@ -0,0 +1,33 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// newProgressPrinter returns a progress callback printing the packages
+// converted to w.  On a terminal the count is updated in place, elsewhere,
+// as in CI logs, a line is printed every tenth of the packages.
+func newProgressPrinter(w io.Writer, terminal bool) func(done, total int) {
+	printed := 0
+	return func(done, total int) {
+		if terminal {
+			_, _ = fmt.Fprintf(w, "\r%d/%d packages processed", done, total)
+			if done == total {
+				_, _ = fmt.Fprintln(w)
+			}
+			return
+		}
+		if step := done * 10 / total; step > printed || done == total {
+			printed = step
+			_, _ = fmt.Fprintf(w, "%d/%d packages processed\n", done, total)
+		}
+	}
+}
+
+// isTerminal reports whether f is a terminal rather than a file or a pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,56 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestProgressPrinter(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	progress := newProgressPrinter(&out, false)
+	for done := 1; done <= 20; done++ {
+		progress(done, 20)
+	}
+	expected := ""
+	for done := 2; done <= 20; done += 2 {
+		expected += fmt.Sprintf("%d/20 packages processed\n", done)
+	}
+	if out.String() != expected {
+		t.Errorf("progress:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+
+	out.Reset()
+	progress = newProgressPrinter(&out, true)
+	progress(1, 2)
+	progress(2, 2)
+	if expected := "\r1/2 packages processed\r2/2 packages processed\n"; out.String() != expected {
+		t.Errorf("terminal progress %q, expected %q", out.String(), expected)
+	}
+}
+
+func TestConvertProgress(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	var calls [][2]int
+	opts := &Options{Progress: func(done, total int) { calls = append(calls, [2]int{done, total}) }}
+	coverage, err := convert(context.Background(), in, &Ignore{GeneratedFiles: true}, opts, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	if len(calls) != 1 || calls[0] != [2]int{1, 1} {
+		t.Errorf("progress calls %v, expected [[1 1]]", calls)
+	}
+}
@@ -0,0 +1,42 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writePrometheus writes the line coverage of every package as Prometheus
+// gauges in the text exposition format, for the node exporter textfile
+// collector.
+func (cov *Coverage) writePrometheus(out io.Writer) error {
+	for _, metric := range []struct {
+		name  string
+		help  string
+		value func(pkg *Package) string
+	}{
+		{"go_coverage_line_rate", "Fraction of the lines of the package covered by tests.", func(pkg *Package) string {
+			if pkg.NumLines() == 0 {
+				return "0"
+			}
+			return fmt.Sprint(float64(pkg.NumLinesWithHits()) / float64(pkg.NumLines()))
+		}},
+		{"go_coverage_lines_covered", "Number of lines of the package covered by tests.", func(pkg *Package) string {
+			return fmt.Sprint(pkg.NumLinesWithHits())
+		}},
+		{"go_coverage_lines_valid", "Number of coverable lines of the package.", func(pkg *Package) string {
+			return fmt.Sprint(pkg.NumLines())
+		}},
+	} {
+		_, _ = fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name)
+		for _, pkg := range cov.Packages {
+			if _, err := fmt.Fprintf(out, "%s{package=\"%s\"} %s\n",
+				metric.name, prometheusEscaper.Replace(pkg.Name), metric.value(pkg)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var prometheusEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
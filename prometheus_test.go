@@ -0,0 +1,41 @@
+package cobertura
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	t.Parallel()
+
+	coverage := Coverage{
+		Packages: []*Package{
+			{Name: "example.com/foo", Classes: []*Class{{Methods: []*Method{
+				{Lines: Lines{{Number: 1, Hits: 1}, {Number: 2, Hits: 0}}},
+			}}}},
+			{Name: `example.com/"quoted"`},
+		},
+	}
+
+	var out strings.Builder
+	if err := coverage.writePrometheus(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP go_coverage_line_rate Fraction of the lines of the package covered by tests.
+# TYPE go_coverage_line_rate gauge
+go_coverage_line_rate{package="example.com/foo"} 0.5
+go_coverage_line_rate{package="example.com/\"quoted\""} 0
+# HELP go_coverage_lines_covered Number of lines of the package covered by tests.
+# TYPE go_coverage_lines_covered gauge
+go_coverage_lines_covered{package="example.com/foo"} 1
+go_coverage_lines_covered{package="example.com/\"quoted\""} 0
+# HELP go_coverage_lines_valid Number of coverable lines of the package.
+# TYPE go_coverage_lines_valid gauge
+go_coverage_lines_valid{package="example.com/foo"} 2
+go_coverage_lines_valid{package="example.com/\"quoted\""} 0
+`
+	if out.String() != expected {
+		t.Errorf("unexpected metrics:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
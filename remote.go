@@ -0,0 +1,86 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// headerList is a flag of HTTP headers, as "Name: value", which may be
+// repeated.
+type headerList []string
+
+func (l *headerList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *headerList) Set(value string) error {
+	if name, _, ok := strings.Cut(value, ":"); !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("bad header %q, expected Name: value", value)
+	}
+	*l = append(*l, value)
+	return nil
+}
+
+// maxRedirects is the number of redirects followed by the downloads, as by
+// the default HTTP client.
+const maxRedirects = 10
+
+// inputOpener opens the inputs given as files or HTTP(S) URLs.
+type inputOpener struct {
+	client *http.Client
+	header http.Header
+}
+
+// newInputOpener returns an opener of the inputs adding the headers, whose
+// values are expanded with os.ExpandEnv, to the downloads.  The headers are
+// dropped on redirects to other hosts, not to leak their credentials.
+func newInputOpener(timeout time.Duration, headers headerList) *inputOpener {
+	header := http.Header{}
+	for _, h := range headers {
+		name, value, _ := strings.Cut(h, ":")
+		header.Add(strings.TrimSpace(name), os.ExpandEnv(strings.TrimSpace(value)))
+	}
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Host != via[0].URL.Host {
+				for key := range header {
+					req.Header.Del(key)
+				}
+			}
+			return nil
+		},
+	}
+	return &inputOpener{client: client, header: header}
+}
+
+// open opens the named file, or downloads it if name is an HTTP(S) URL.
+func (o *inputOpener) open(name string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(name, "http://") && !strings.HasPrefix(name, "https://") {
+		return os.Open(name)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range o.header {
+		req.Header[key] = values
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", req.URL.Redacted(), resp.Status)
+	}
+	return resp.Body, nil
+}
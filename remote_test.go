@@ -0,0 +1,106 @@
+package cobertura
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//nolint:paralleltest // sets an environment variable
+func TestInputOpener(t *testing.T) {
+	const profile = "mode: set\nexample.com/a.go:1.1,2.2 1 1\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Private-Token") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, profile)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("TEST_TOKEN", "secret")
+	opener := newInputOpener(time.Minute, headerList{"Private-Token: $TEST_TOKEN"})
+
+	in, err := opener.open(server.URL + "/coverage.out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(in)
+	_ = in.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != profile {
+		t.Errorf("unexpected profile %q", data)
+	}
+
+	if _, err := newInputOpener(time.Minute, nil).open(server.URL); err == nil {
+		t.Error("expected an error for an unauthorized download")
+	}
+
+	name := filepath.Join(t.TempDir(), "coverage.out")
+	if err := os.WriteFile(name, []byte(profile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	in, err = opener.open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = in.Close()
+}
+
+func TestInputOpenerRedirect(t *testing.T) {
+	t.Parallel()
+
+	var leaked bool
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Private-Token") != ""
+		_, _ = io.WriteString(w, "mode: set\n")
+	}))
+	t.Cleanup(other.Close)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Private-Token") != "secret":
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		case r.URL.Path == "/same":
+			http.Redirect(w, r, "/coverage.out", http.StatusFound)
+		case r.URL.Path == "/other":
+			http.Redirect(w, r, other.URL+"/coverage.out", http.StatusFound)
+		default:
+			_, _ = io.WriteString(w, "mode: set\n")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	opener := newInputOpener(time.Minute, headerList{"Private-Token: secret"})
+	for _, path := range []string{"/same", "/other"} {
+		in, err := opener.open(server.URL + path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		_ = in.Close()
+	}
+	if leaked {
+		t.Error("header sent on the redirect to another host")
+	}
+}
+
+func TestHeaderList(t *testing.T) {
+	t.Parallel()
+
+	var headers headerList
+	if err := headers.Set("Authorization: Bearer a, b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := headers.Set("no colon"); err == nil {
+		t.Error("expected an error for a header without a colon")
+	}
+	if headers.String() != "Authorization: Bearer a, b" {
+		t.Errorf("unexpected headers %q", headers.String())
+	}
+}
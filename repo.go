@@ -0,0 +1,49 @@
+package cobertura
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// repoRoots caches the repository roots by module directory, as the files
+// of a module are named concurrently.
+var repoRoots sync.Map
+
+// repoRoot returns the root of the repository holding dir, the closest
+// directory with a .git entry, or dir itself out of any repository.
+func repoRoot(dir string) string {
+	if root, ok := repoRoots.Load(dir); ok {
+		return root.(string)
+	}
+	root := dir
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			root = current
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+	repoRoots.Store(dir, root)
+	return root
+}
+
+// repoFileName returns the name of the file relative to the root of the
+// repository holding its module, or fileName, relative to the module, if
+// it is out of it.
+func repoFileName(fileName, absFilePath string, module *packages.Module) string {
+	if module.Dir == "" || absFilePath == "" {
+		return fileName
+	}
+	rel, ok := relPath(repoRoot(module.Dir), absFilePath)
+	if !ok {
+		return fileName
+	}
+	return filepath.ToSlash(rel)
+}
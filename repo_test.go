@@ -0,0 +1,54 @@
+package cobertura
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestRepoFileName(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	moduleDir := filepath.Join(root, "svc")
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(moduleDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	module := &packages.Module{Path: "example.com/svc", Dir: moduleDir}
+	if actual := repoRoot(moduleDir); actual != root {
+		t.Errorf("repository root %s, expected %s", actual, root)
+	}
+	file := filepath.Join(moduleDir, "internal", "x.go")
+	if actual := repoFileName("internal/x.go", file, module); actual != "svc/internal/x.go" {
+		t.Errorf("file name %s, expected svc/internal/x.go", actual)
+	}
+	outside := filepath.Join(filepath.Dir(root), "other", "x.go")
+	if actual := repoFileName("x.go", outside, module); actual != "x.go" {
+		t.Errorf("file name %s out of the repository, expected x.go", actual)
+	}
+}
+
+func TestRepoRelativeFilenames(t *testing.T) {
+	t.Parallel()
+
+	cov := convertTestdata(t, &Ignore{GeneratedFiles: true}, &Options{FilenameStyle: filenameStyleRepo})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := repoRoot(wd)
+	if len(cov.Sources) != 1 || cov.Sources[0].Path != root {
+		t.Errorf("sources %v, expected %s", cov.Sources, root)
+	}
+	for _, class := range cov.Packages[0].Classes {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(class.Filename))); err != nil {
+			t.Errorf("class %s filename %s is not relative to %s: %v", class.Name, class.Filename, root, err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package cobertura
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// signature returns the type parameters, parameter and result types of the
+// function, as "[T any](T, ...string) (bool, error)", the parameter names
+// left out.
+func signature(fn *ast.FuncType) string {
+	var b strings.Builder
+	if fn.TypeParams != nil {
+		params := make([]string, 0, len(fn.TypeParams.List))
+		for _, field := range fn.TypeParams.List {
+			names := make([]string, len(field.Names))
+			for index, name := range field.Names {
+				names[index] = name.Name
+			}
+			params = append(params, strings.Join(names, ", ")+" "+types.ExprString(field.Type))
+		}
+		b.WriteString("[" + strings.Join(params, ", ") + "]")
+	}
+	b.WriteString("(" + fieldTypes(fn.Params) + ")")
+	switch fn.Results.NumFields() {
+	case 0:
+	case 1:
+		b.WriteString(" " + fieldTypes(fn.Results))
+	default:
+		b.WriteString(" (" + fieldTypes(fn.Results) + ")")
+	}
+	return b.String()
+}
+
+// fieldTypes returns the types of the fields, once per name.
+func fieldTypes(fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	var fieldTypes []string
+	for _, field := range fields.List {
+		fieldType := types.ExprString(field.Type)
+		for count := max(len(field.Names), 1); count > 0; count-- {
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+	}
+	return strings.Join(fieldTypes, ", ")
+}
@@ -0,0 +1,28 @@
+package cobertura
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestSignature(t *testing.T) {
+	t.Parallel()
+
+	for source, expected := range map[string]string{
+		"func f()":                            "()",
+		"func f(a, b int, s ...string) error": "(int, int, ...string) error",
+		"func f(*int, map[string][]byte) (n int, err error)": "(*int, map[string][]byte) (int, error)",
+		"func f[K comparable, V any](m map[K]V) []K":         "[K comparable, V any](map[K]V) []K",
+		"func (r *T) f(fn func(int) bool) chan<- struct{}":   "(func(int) bool) chan<- struct{}",
+	} {
+		parsed, err := parser.ParseFile(token.NewFileSet(), "p.go", "package p\n"+source+" { panic(0) }", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := signature(parsed.Decls[0].(*ast.FuncDecl).Type); actual != expected {
+			t.Errorf("signature of %q = %q, expected %q", source, actual, expected)
+		}
+	}
+}
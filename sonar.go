@@ -0,0 +1,42 @@
+package cobertura
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type sonarCoverage struct {
+	XMLName xml.Name     `xml:"coverage"`
+	Version int          `xml:"version,attr"`
+	Files   []*sonarFile `xml:"file"`
+}
+
+type sonarFile struct {
+	Path  string       `xml:"path,attr"`
+	Lines []*sonarLine `xml:"lineToCover"`
+}
+
+type sonarLine struct {
+	LineNumber int  `xml:"lineNumber,attr"`
+	Covered    bool `xml:"covered,attr"`
+}
+
+// writeSonarQube writes the coverage in the SonarQube generic test coverage
+// format.
+func (cov *Coverage) writeSonarQube(out io.Writer) error {
+	files, err := cov.files()
+	if err != nil {
+		return err
+	}
+
+	doc := sonarCoverage{Version: 1, Files: make([]*sonarFile, 0, len(files))}
+	for _, file := range files {
+		sonar := &sonarFile{Path: file.Filename, Lines: make([]*sonarLine, 0, len(file.Lines))}
+		for _, line := range file.Lines {
+			sonar.Lines = append(sonar.Lines, &sonarLine{LineNumber: line.Number, Covered: line.Hits > 0})
+		}
+		doc.Files = append(doc.Files, sonar)
+	}
+
+	return encodeXML(out, doc, "")
+}
@@ -0,0 +1,59 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"os"
+	"testing"
+)
+
+func TestWriteSonarQube(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	coverage, err := convert(context.Background(), in, &Ignore{GeneratedFiles: true}, nil, []string{"testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	var out bytes.Buffer
+	if err := coverage.writeSonarQube(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc sonarCoverage
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Version != 1 {
+		t.Errorf("version is %d but should be 1", doc.Version)
+	}
+
+	var lines, covered int64
+	var func2 *sonarFile
+	for _, file := range doc.Files {
+		for _, line := range file.Lines {
+			lines++
+			if line.Covered {
+				covered++
+			}
+		}
+		if file.Path == "testdata/func2.go" {
+			func2 = file
+		}
+	}
+	if lines != coverage.LinesValid || covered != coverage.LinesCovered {
+		t.Errorf("covered lines %d/%d do not match %d/%d", covered, lines, coverage.LinesCovered, coverage.LinesValid)
+	}
+	if func2 == nil || len(func2.Lines) != 8 || !func2.Lines[0].Covered || func2.Lines[7].Covered {
+		t.Errorf("unexpected testdata/func2.go file: %+v", func2)
+	}
+}
@@ -0,0 +1,44 @@
+package cobertura
+
+import (
+	"sort"
+)
+
+// sortDeterministic sorts the sources by path, the packages by name, the
+// classes by filename and name, the methods by declaration line and name,
+// and the lines by number, so that the reports of identical coverage are
+// identical whatever the order of the inputs.  Spilled lines are kept in
+// their declaration order.
+func (cov *Coverage) sortDeterministic() {
+	sort.SliceStable(cov.Sources, func(i, j int) bool { return cov.Sources[i].Path < cov.Sources[j].Path })
+	sort.SliceStable(cov.Packages, func(i, j int) bool { return cov.Packages[i].Name < cov.Packages[j].Name })
+	for _, pkg := range cov.Packages {
+		classes := pkg.Classes
+		sort.SliceStable(classes, func(i, j int) bool {
+			if classes[i].Filename != classes[j].Filename {
+				return classes[i].Filename < classes[j].Filename
+			}
+			return classes[i].Name < classes[j].Name
+		})
+		for _, class := range classes {
+			methods := class.Methods
+			sort.SliceStable(methods, func(i, j int) bool {
+				if methods[i].line != methods[j].line {
+					return methods[i].line < methods[j].line
+				}
+				if methods[i].Name != methods[j].Name {
+					return methods[i].Name < methods[j].Name
+				}
+				return methods[i].Signature < methods[j].Signature
+			})
+			for _, method := range methods {
+				sortLines(method.Lines)
+			}
+			sortLines(class.Lines)
+		}
+	}
+}
+
+func sortLines(lines Lines) {
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Number < lines[j].Number })
+}
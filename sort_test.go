@@ -0,0 +1,49 @@
+package cobertura
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSortDeterministic(t *testing.T) {
+	t.Parallel()
+
+	coverage := &Coverage{
+		Sources: []*Source{{Path: "/b"}, {Path: "/a"}},
+		Packages: []*Package{
+			{Name: "b"},
+			{Name: "a", Classes: []*Class{
+				{Name: "T", Filename: "a/z.go"},
+				{Name: "U", Filename: "a/a.go"},
+				{Name: "T", Filename: "a/a.go", Methods: []*Method{
+					{Name: "B", line: 9, Lines: Lines{{Number: 10}, {Number: 9}}},
+					{Name: "A", line: 3},
+					{Name: "C"},
+				}, Lines: Lines{{Number: 10}, {Number: 9}, {Number: 4}}},
+			}},
+		},
+	}
+	coverage.sortDeterministic()
+
+	if coverage.Sources[0].Path != "/a" || coverage.Packages[0].Name != "a" {
+		t.Errorf("sources %s, packages %s", coverage.Sources[0].Path, coverage.Packages[0].Name)
+	}
+	var classes []string
+	for _, class := range coverage.Packages[0].Classes {
+		classes = append(classes, class.Filename+":"+class.Name)
+	}
+	if expected := "a/a.go:T a/a.go:U a/z.go:T"; strings.Join(classes, " ") != expected {
+		t.Errorf("classes %s, expected %s", strings.Join(classes, " "), expected)
+	}
+	class := coverage.Packages[0].Classes[0]
+	var methods []string
+	for _, method := range class.Methods {
+		methods = append(methods, method.Name)
+	}
+	if expected := "C A B"; strings.Join(methods, " ") != expected {
+		t.Errorf("methods %s, expected %s", strings.Join(methods, " "), expected)
+	}
+	if class.Lines[0].Number != 4 || class.Methods[2].Lines[0].Number != 9 {
+		t.Errorf("lines not sorted: %d, %d", class.Lines[0].Number, class.Methods[2].Lines[0].Number)
+	}
+}
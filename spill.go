@@ -0,0 +1,243 @@
+package cobertura
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// lineMemorySize is the approximate number of bytes held in memory per
+// recorded line: the Line itself, the pointer to it and allocation overhead.
+const lineMemorySize = 40
+
+// spillStore is a temporary file holding the lines of methods and classes
+// once the memory bound of a conversion is reached.  The lines are read back
+// one method or class at a time while encoding.
+type spillStore struct {
+	file *os.File
+	size int64
+}
+
+// spilledLines replaces the lines of a spilled method or class.
+type spilledLines struct {
+	store              *spillStore
+	offset             int64
+	length             int
+	numLines           int64
+	numLinesWithHits   int64
+	numBranches        int64
+	numBranchesCovered int64
+}
+
+func newSpillStore() (*spillStore, error) {
+	file, err := os.CreateTemp("", "gocover-cobertura-*.spill")
+	if err != nil {
+		return nil, fmt.Errorf("create spill file: %w", err)
+	}
+	return &spillStore{file: file}, nil
+}
+
+func (s *spillStore) Close() error {
+	name := s.file.Name()
+	err := s.file.Close()
+	if removeErr := os.Remove(name); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+func (s *spillStore) store(lines Lines) (*spilledLines, error) {
+	buf := make([]byte, 0, len(lines)*4*binary.MaxVarintLen64)
+	for _, line := range lines {
+		covered, valid := line.branchCounts()
+		buf = binary.AppendUvarint(buf, uint64(line.Number))
+		buf = binary.AppendVarint(buf, line.Hits)
+		buf = binary.AppendUvarint(buf, uint64(covered))
+		buf = binary.AppendUvarint(buf, uint64(valid))
+	}
+	if _, err := s.file.WriteAt(buf, s.size); err != nil {
+		return nil, fmt.Errorf("write spill file: %w", err)
+	}
+	spilled := &spilledLines{
+		store:              s,
+		offset:             s.size,
+		length:             len(buf),
+		numLines:           lines.NumLines(),
+		numLinesWithHits:   lines.NumLinesWithHits(),
+		numBranches:        lines.NumBranches(),
+		numBranchesCovered: lines.NumBranchesCovered(),
+	}
+	s.size += int64(len(buf))
+	return spilled, nil
+}
+
+func (s *spilledLines) load() (Lines, error) {
+	buf := make([]byte, s.length)
+	if _, err := s.store.file.ReadAt(buf, s.offset); err != nil {
+		return nil, fmt.Errorf("read spill file: %w", err)
+	}
+	lines := make(Lines, 0, s.numLines)
+	for len(buf) > 0 {
+		number, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupted spill file at offset %d", s.offset)
+		}
+		buf = buf[n:]
+		hits, n := binary.Varint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupted spill file at offset %d", s.offset)
+		}
+		buf = buf[n:]
+		covered, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupted spill file at offset %d", s.offset)
+		}
+		buf = buf[n:]
+		valid, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupted spill file at offset %d", s.offset)
+		}
+		buf = buf[n:]
+		line := &Line{Number: int(number), Hits: hits}
+		line.SetBranches(int(covered), int(valid))
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// spillIfNeeded accounts for the lines recorded from profile, and spills
+// the lines held in memory once they exceed maxMemory bytes.
+func (cov *Coverage) spillIfNeeded(profile *Profile, maxMemory int64, logger *slog.Logger) error {
+	for _, block := range profile.Blocks {
+		// lines are recorded both on the method and on its class
+		cov.linesInMemory += 2 * int64(block.EndLine-block.StartLine+1)
+	}
+	if cov.linesInMemory*lineMemorySize <= maxMemory {
+		return nil
+	}
+
+	if cov.spillStore == nil {
+		store, err := newSpillStore()
+		if err != nil {
+			return err
+		}
+		cov.spillStore = store
+		logger.Debug("spilling line data to disk", "file", store.file.Name())
+	}
+	return cov.spill()
+}
+
+// spill moves the lines of every method and class held in memory to the
+// spill store.
+func (cov *Coverage) spill() error {
+	for _, pkg := range cov.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				if method.spilled != nil {
+					continue
+				}
+				spilled, err := cov.spillStore.store(method.Lines)
+				if err != nil {
+					return err
+				}
+				method.spilled, method.Lines = spilled, nil
+			}
+			if class.spilled != nil {
+				continue
+			}
+			spilled, err := cov.spillStore.store(class.Lines)
+			if err != nil {
+				return err
+			}
+			class.spilled, class.Lines = spilled, nil
+		}
+	}
+	cov.linesInMemory = 0
+	return nil
+}
+
+// loadLines returns the lines of the method, reading them back from the
+// spill store if they were spilled.
+func (method *Method) loadLines() (Lines, error) {
+	if method.spilled == nil {
+		return method.Lines, nil
+	}
+	return method.spilled.load()
+}
+
+// loadLines returns the lines of the class, reading them back from the
+// spill store if they were spilled.
+func (class *Class) loadLines() (Lines, error) {
+	if class.spilled == nil {
+		return class.Lines, nil
+	}
+	return class.spilled.load()
+}
+
+// unspill reads the lines of the class and of its methods back in memory.
+func (class *Class) unspill() error {
+	for _, method := range class.Methods {
+		lines, err := method.loadLines()
+		if err != nil {
+			return err
+		}
+		method.Lines, method.spilled = lines, nil
+	}
+	lines, err := class.loadLines()
+	if err != nil {
+		return err
+	}
+	class.Lines, class.spilled = lines, nil
+	return nil
+}
+
+// MarshalXML encodes the method, reading its lines back from the spill
+// store if they were spilled.
+func (method Method) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plainMethod Method
+	lines, err := method.loadLines()
+	if err != nil {
+		return err
+	}
+	method.Lines = lines
+	return e.EncodeElement(plainMethod(method), start)
+}
+
+// MarshalXML encodes the class, reading its lines back from the spill store
+// if they were spilled.
+func (class Class) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plainClass Class
+	lines, err := class.loadLines()
+	if err != nil {
+		return err
+	}
+	class.Lines = lines
+	return e.EncodeElement(plainClass(class), start)
+}
+
+// parseMemorySize parses a size in bytes with an optional K, M or G suffix,
+// as 512M.
+func parseMemorySize(s string) (int64, error) {
+	multiplier := int64(1)
+	upper := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	switch {
+	case strings.HasSuffix(upper, "K"):
+		multiplier = 1 << 10
+	case strings.HasSuffix(upper, "M"):
+		multiplier = 1 << 20
+	case strings.HasSuffix(upper, "G"):
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		upper = upper[:len(upper)-1]
+	}
+	size, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("bad memory size %q", s)
+	}
+	return size * multiplier, nil
+}
@@ -0,0 +1,78 @@
+package cobertura
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestConvertWithSpill(t *testing.T) {
+	t.Parallel()
+
+	convert := func(opts *Options) string {
+		in, err := os.Open("testdata/testdata_set.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = in.Close() })
+
+		var out bytes.Buffer
+		if err := ConvertWithOptions(in, &out, &Ignore{}, opts, "testdata"); err != nil {
+			t.Fatal(err)
+		}
+		// the timestamp differs between conversions
+		return regexp.MustCompile(`timestamp="\d+"`).ReplaceAllString(out.String(), "")
+	}
+
+	expected := convert(nil)
+	if actual := convert(&Options{MaxMemory: 1}); actual != expected {
+		t.Errorf("spilled conversion differs:\n%s\nexpected:\n%s", actual, expected)
+	}
+}
+
+func TestCommandsWithSpill(t *testing.T) {
+	t.Parallel()
+
+	const profile = `mode: count
+github.com/franchb/gocover-cobertura/testdata/func2.go:8.34,9.16 1 120
+github.com/franchb/gocover-cobertura/testdata/func2.go:9.16,11.3 1 7
+`
+	for _, args := range [][]string{{"stats"}, {"explain", "testdata/func2.go:9"}} {
+		run := func(opts *Options) string {
+			var out bytes.Buffer
+			if err := runCommand(args, strings.NewReader(profile), nil, &out, &Ignore{}, opts, []string{"testdata"}); err != nil {
+				t.Fatal(err)
+			}
+			return out.String()
+		}
+		expected := run(nil)
+		if actual := run(&Options{MaxMemory: 1}); actual != expected {
+			t.Errorf("%s with spilled lines:\n%s\nexpected:\n%s", args[0], actual, expected)
+		}
+	}
+}
+
+func TestParseMemorySize(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]int64{
+		"0":     0,
+		"1024":  1024,
+		"64k":   64 << 10,
+		"512M":  512 << 20,
+		"512MB": 512 << 20,
+		"2G":    2 << 30,
+	} {
+		if actual, err := parseMemorySize(input); err != nil || actual != expected {
+			t.Errorf("parseMemorySize(%s) == %d, %v but should be %d", input, actual, err, expected)
+		}
+	}
+
+	for _, input := range []string{"", "M", "-1", "12X"} {
+		if _, err := parseMemorySize(input); err == nil {
+			t.Errorf("parseMemorySize(%s) should fail", input)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package cobertura
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// splitManifest is the index written alongside the per-package reports.
+type splitManifest struct {
+	LinesCovered int64                  `json:"lines_covered"`
+	LinesValid   int64                  `json:"lines_valid"`
+	LineRate     float32                `json:"line_rate"`
+	Packages     []*splitManifestReport `json:"packages"`
+}
+
+type splitManifestReport struct {
+	Package      string  `json:"package"`
+	File         string  `json:"file"`
+	LinesCovered int64   `json:"lines_covered"`
+	LinesValid   int64   `json:"lines_valid"`
+	LineRate     float32 `json:"line_rate"`
+}
+
+// writeSplitByPackage writes a Cobertura report per package to dir, named
+// after the package import path, and an index.json manifest listing them.
+func (cov *Coverage) writeSplitByPackage(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	manifest := splitManifest{
+		LinesCovered: cov.LinesCovered,
+		LinesValid:   cov.LinesValid,
+		LineRate:     cov.LineRate,
+		Packages:     []*splitManifestReport{},
+	}
+	used := map[string]bool{}
+	for _, pkg := range cov.Packages {
+		pkgCov := &Coverage{
+			Version:         cov.Version,
+			Timestamp:       cov.Timestamp,
+			Sources:         cov.Sources,
+			Packages:        []*Package{pkg},
+			LinesCovered:    pkg.NumLinesWithHits(),
+			LinesValid:      pkg.NumLines(),
+			LineRate:        pkg.LineRate,
+			timestampMillis: cov.timestampMillis,
+		}
+
+		name := splitFileName(pkg.Name, used)
+		if err := writeFile(filepath.Join(dir, name), pkgCov.writeXML); err != nil {
+			return err
+		}
+		manifest.Packages = append(manifest.Packages, &splitManifestReport{
+			Package:      pkg.Name,
+			File:         name,
+			LinesCovered: pkgCov.LinesCovered,
+			LinesValid:   pkgCov.LinesValid,
+			LineRate:     pkgCov.LineRate,
+		})
+	}
+
+	return writeFile(filepath.Join(dir, "index.json"), func(out io.Writer) error {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(manifest)
+	})
+}
+
+// splitFileName returns a file name for the report of the package, unique
+// among the used ones.
+func splitFileName(pkgName string, used map[string]bool) string {
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, strings.Trim(pkgName, "/"))
+	if base == "" {
+		base = "root"
+	}
+
+	name := base + ".xml"
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s_%d.xml", base, n)
+	}
+	used[name] = true
+	return name
+}
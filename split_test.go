@@ -0,0 +1,88 @@
+package cobertura
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteSplitByPackage(t *testing.T) {
+	t.Parallel()
+
+	coverage := Coverage{
+		LinesCovered: 2,
+		LinesValid:   3,
+		LineRate:     2.0 / 3,
+		Packages: []*Package{
+			{Name: "example.com/foo", LineRate: 0.5, Classes: []*Class{
+				{Name: "-", Filename: "foo/foo.go", Methods: []*Method{
+					{Name: "Foo", Lines: Lines{{Number: 1, Hits: 1}, {Number: 2}}},
+				}},
+			}},
+			{Name: "example.com/foo/bar", LineRate: 1, Classes: []*Class{
+				{Name: "-", Filename: "foo/bar/bar.go", Methods: []*Method{
+					{Name: "Bar", Lines: Lines{{Number: 1, Hits: 3}}},
+				}},
+			}},
+		},
+	}
+
+	dir := t.TempDir()
+	if err := coverage.writeSplitByPackage(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest splitManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.LinesCovered != 2 || manifest.LinesValid != 3 || len(manifest.Packages) != 2 {
+		t.Fatalf("unexpected manifest %s", data)
+	}
+
+	for i, expected := range []splitManifestReport{
+		{Package: "example.com/foo", File: "example.com_foo.xml", LinesCovered: 1, LinesValid: 2, LineRate: 0.5},
+		{Package: "example.com/foo/bar", File: "example.com_foo_bar.xml", LinesCovered: 1, LinesValid: 1, LineRate: 1},
+	} {
+		if *manifest.Packages[i] != expected {
+			t.Errorf("unexpected manifest entry %+v, expected %+v", *manifest.Packages[i], expected)
+		}
+
+		report, err := os.ReadFile(filepath.Join(dir, expected.File))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pkgCov Coverage
+		if err := xml.Unmarshal(report, &pkgCov); err != nil {
+			t.Fatal(err)
+		}
+		if len(pkgCov.Packages) != 1 || pkgCov.Packages[0].Name != expected.Package {
+			t.Errorf("unexpected packages in %s", expected.File)
+		}
+		if pkgCov.LinesCovered != expected.LinesCovered || pkgCov.LinesValid != expected.LinesValid {
+			t.Errorf("unexpected totals in %s: %d/%d", expected.File, pkgCov.LinesCovered, pkgCov.LinesValid)
+		}
+	}
+}
+
+func TestSplitFileName(t *testing.T) {
+	t.Parallel()
+
+	used := map[string]bool{}
+	for _, tc := range []struct{ pkgName, expected string }{
+		{"example.com/a-b/c", "example.com_a-b_c.xml"},
+		{"example.com/a_b/c", "example.com_a_b_c.xml"},
+		{"example.com/a_b_c", "example.com_a_b_c_2.xml"},
+		{"", "root.xml"},
+	} {
+		if actual := splitFileName(tc.pkgName, used); actual != tc.expected {
+			t.Errorf("splitFileName(%q) = %q, expected %q", tc.pkgName, actual, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package cobertura
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+const hottestFunctions = 10
+
+type methodHits struct {
+	pkg    string
+	class  string
+	method string
+	hits   int64
+}
+
+// Stats prints the distribution of the block hit counts of a count or atomic
+// profile: quantiles, a histogram, the hottest functions and the blocks which
+// were never hit.
+func Stats(in io.Reader, out io.Writer, ignore Matcher, buildTags ...string) error {
+	return stats(in, out, ignore, nil, buildTags)
+}
+
+// stats is Stats, converting with opts.
+func stats(in io.Reader, out io.Writer, ignore Matcher, opts *Options, buildTags []string) error {
+	profiles, err := parseProfiles(context.Background(), in, ignore, opts)
+	if err != nil {
+		return err
+	}
+
+	counts := []int{}
+	for _, profile := range profiles {
+		if profile.Mode == "set" {
+			return fmt.Errorf("stats require a count or atomic profile, got mode %q", profile.Mode)
+		}
+		for _, block := range profile.Blocks {
+			counts = append(counts, block.Count)
+		}
+	}
+	sort.Ints(counts)
+
+	_, _ = fmt.Fprintf(out, "blocks: %d\n", len(counts))
+	if len(counts) == 0 {
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "hit count quantiles: p50=%d p90=%d p99=%d max=%d\n",
+		quantile(counts, 0.5), quantile(counts, 0.9), quantile(counts, 0.99), counts[len(counts)-1])
+
+	_, _ = fmt.Fprintln(out, "histogram:")
+	for _, bucket := range histogram(counts) {
+		_, _ = fmt.Fprintf(out, "  %-12s %d\n", bucket.label, bucket.count)
+	}
+
+	coverage, err := convertProfiles(context.Background(), profiles, ignore, buildTags, opts)
+	if err != nil {
+		return err
+	}
+	defer coverage.close()
+
+	hottest := []methodHits{}
+	for _, pkg := range coverage.Packages {
+		for _, class := range pkg.Classes {
+			for _, method := range class.Methods {
+				lines, err := method.loadLines()
+				if err != nil {
+					return err
+				}
+				var maxHits int64
+				for _, line := range lines {
+					if line.Hits > maxHits {
+						maxHits = line.Hits
+					}
+				}
+				hottest = append(hottest, methodHits{pkg: pkg.Name, class: class.Name, method: method.Name, hits: maxHits})
+			}
+		}
+	}
+	sort.SliceStable(hottest, func(i, j int) bool { return hottest[i].hits > hottest[j].hits })
+	if len(hottest) > hottestFunctions {
+		hottest = hottest[:hottestFunctions]
+	}
+
+	_, _ = fmt.Fprintln(out, "hottest functions:")
+	for _, m := range hottest {
+		_, _ = fmt.Fprintf(out, "  %-10d %s %s.%s\n", m.hits, m.pkg, m.class, m.method)
+	}
+
+	_, _ = fmt.Fprintln(out, "never-hit blocks:")
+	for _, profile := range profiles {
+		for _, block := range profile.Blocks {
+			if block.Count == 0 {
+				_, _ = fmt.Fprintf(out, "  %s:%d.%d,%d.%d\n",
+					profile.FileName, block.StartLine, block.StartCol, block.EndLine, block.EndCol)
+			}
+		}
+	}
+
+	return nil
+}
+
+// quantile returns the nearest-rank q quantile of the sorted counts.
+func quantile(sorted []int, q float64) int {
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+type histogramBucket struct {
+	label string
+	count int
+}
+
+// histogram groups the sorted counts in buckets of growing powers of ten.
+func histogram(sorted []int) []histogramBucket {
+	buckets := []histogramBucket{{label: "0"}, {label: "1-9"}}
+	for _, count := range sorted {
+		index := 0
+		if count > 0 {
+			index = 1 + int(math.Log10(float64(count)))
+		}
+		for len(buckets) <= index {
+			lower := int(math.Pow10(len(buckets) - 1))
+			buckets = append(buckets, histogramBucket{label: fmt.Sprintf("%d-%d", lower, lower*10-1)})
+		}
+		buckets[index].count++
+	}
+	return buckets
+}
@@ -0,0 +1,43 @@
+package cobertura
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: count
+github.com/franchb/gocover-cobertura/testdata/func2.go:8.34,9.16 1 120
+github.com/franchb/gocover-cobertura/testdata/func2.go:9.16,11.3 1 7
+github.com/franchb/gocover-cobertura/testdata/func2.go:14.36,15.2 0 0
+github.com/franchb/gocover-cobertura/testdata/func2.go:17.36,18.2 0 1
+`
+	var out strings.Builder
+	if err := Stats(strings.NewReader(profile), &out, &Ignore{}, "testdata"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"blocks: 4\n",
+		"hit count quantiles: p50=1 p90=120 p99=120 max=120\n",
+		"  0            1\n  1-9          2\n  10-99        0\n  100-999      1\n",
+		"github.com/franchb/gocover-cobertura/testdata Type1.Func2a\n",
+		"never-hit blocks:\n  github.com/franchb/gocover-cobertura/testdata/func2.go:14.36,15.2\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("stats output does not contain %q:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestStatsSetMode(t *testing.T) {
+	t.Parallel()
+
+	profile := "mode: set\ngithub.com/franchb/gocover-cobertura/testdata/func2.go:8.34,9.16 1 1\n"
+	err := Stats(strings.NewReader(profile), &strings.Builder{}, &Ignore{}, "testdata")
+	if err == nil || !strings.Contains(err.Error(), "count or atomic profile") {
+		t.Errorf("expected a mode error, got %v", err)
+	}
+}
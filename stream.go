@@ -0,0 +1,202 @@
+package cobertura
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ConvertStream is like ConvertContext, writing the packages of the report
+// as they are converted instead of once the whole coverage is built, so that
+// the memory is bounded by the largest package instead of the repository.
+// The packages are buffered in a temporary file, as the totals of the report
+// come before them.  The report is identical to the one of ConvertContext.
+func ConvertStream(ctx context.Context, in io.Reader, out io.Writer, ignore Matcher, opts *Options, buildTags ...string) error {
+	coverage, err := convertStream(ctx, in, ignore, opts, buildTags)
+	if coverage == nil {
+		return err
+	}
+	defer coverage.close()
+
+	if writeErr := coverage.writeXML(out); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// convertStream parses the profiles read from in and converts them to a
+// coverage whose packages are written to a temporary file, returned along
+// with the error of the failed files with Options.Partial.  The caller must
+// close the returned coverage.
+func convertStream(ctx context.Context, in io.Reader, ignore Matcher, opts *Options, buildTags []string) (*Coverage, error) {
+	logger := opts.logger()
+
+	start := time.Now()
+	profiles, err := parseProfiles(ctx, in, ignore, opts)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, withExitCode(profileExitCode(err), err)
+	}
+	logger.Debug("parsed profiles", "files", len(profiles), "duration", time.Since(start))
+	opts.phaseTimings().record("parse profiles", time.Since(start))
+
+	stream, err := newPackageStream()
+	if err != nil {
+		return nil, err
+	}
+	coverage, err := buildCoverage(ctx, profiles, ignore, buildTags, opts, stream)
+	if err != nil {
+		if coverage != nil {
+			return coverage, err
+		}
+		_ = stream.Close()
+		return nil, err
+	}
+
+	if opts.failOnEmpty() && coverage.LinesValid == 0 {
+		coverage.close()
+		return nil, errEmptyCoverage
+	}
+	return coverage, nil
+}
+
+// packageStream buffers the packages written while converting in a
+// temporary file, counting their lines and branches for the totals of the
+// report.
+type packageStream struct {
+	file     *os.File
+	buffered *bufio.Writer
+	encoder  *xml.Encoder
+	packages int
+
+	lines, linesWithHits      int64
+	branches, branchesCovered int64
+}
+
+func newPackageStream() (*packageStream, error) {
+	file, err := os.CreateTemp("", "gocover-cobertura-stream-*.xml")
+	if err != nil {
+		return nil, fmt.Errorf("create stream file: %w", err)
+	}
+	buffered := bufio.NewWriter(file)
+	encoder := xml.NewEncoder(buffered)
+	// as nested in <coverage><packages> by writeXML
+	encoder.Indent("    ", "  ")
+	return &packageStream{file: file, buffered: buffered, encoder: encoder}, nil
+}
+
+// flush writes the packages converted so far and drops them from the
+// coverage.
+func (s *packageStream) flush(cov *Coverage) error {
+	for _, pkg := range cov.Packages {
+		s.lines += pkg.NumLines()
+		s.linesWithHits += pkg.NumLinesWithHits()
+		s.branches += pkg.NumBranches()
+		s.branchesCovered += pkg.NumBranchesCovered()
+		if err := s.encoder.EncodeElement(pkg, xml.StartElement{Name: xml.Name{Local: "package"}}); err != nil {
+			return fmt.Errorf("write stream file: %w", err)
+		}
+		s.packages++
+	}
+	cov.Packages = []*Package{}
+	cov.sourceFiles = nil
+	cov.linesInMemory = 0
+	return nil
+}
+
+// setTotals sets the totals and rates of the coverage from the packages
+// written.
+func (s *packageStream) setTotals(cov *Coverage) {
+	cov.LinesValid = s.lines
+	cov.LinesCovered = s.linesWithHits
+	cov.LineRate = float32(s.linesWithHits) / float32(s.lines)
+	cov.BranchesValid = s.branches
+	cov.BranchesCovered = s.branchesCovered
+	cov.BranchRate = branchRate(s.branchesCovered, s.branches)
+}
+
+// writeXML writes the report of cov, whose packages are the ones written to
+// the stream, with the same layout as Coverage.writeXML.
+func (s *packageStream) writeXML(out io.Writer, cov *Coverage) error {
+	var head bytes.Buffer
+	if err := encodeXML(&head, cov, DTDDecl); err != nil {
+		return err
+	}
+	if s.packages == 0 {
+		_, err := out.Write(head.Bytes())
+		return err
+	}
+
+	if err := s.encoder.Flush(); err != nil {
+		return fmt.Errorf("write stream file: %w", err)
+	}
+	if err := s.buffered.Flush(); err != nil {
+		return fmt.Errorf("write stream file: %w", err)
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("read stream file: %w", err)
+	}
+
+	// the packages replace the empty <packages> element ending the report
+	const empty, end = "\n  <packages></packages>", "\n</coverage>\n"
+	doc, ok := bytes.CutSuffix(head.Bytes(), []byte(empty+end))
+	if !ok {
+		return fmt.Errorf("unexpected end of report %q", head.Bytes()[max(0, head.Len()-len(empty+end)):])
+	}
+	if _, err := out.Write(doc); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(out, "\n  <packages>\n"); err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, s.file); err != nil {
+		return fmt.Errorf("read stream file: %w", err)
+	}
+	_, err := io.WriteString(out, "\n  </packages>"+end)
+	return err
+}
+
+// Close removes the stream file.
+func (s *packageStream) Close() error {
+	name := s.file.Name()
+	err := s.file.Close()
+	if removeErr := os.Remove(name); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// streamIncompatibleFlags are the flags needing the whole coverage in memory,
+// which -stream does not keep.
+var streamIncompatibleFlags = []string{
+	"merge", "path-map", "deterministic", "baseline", "delta", "thresholds", "markdown", "show-uncovered",
+	"html-dir", "split-by-package", "azure-devops", "codecov-upload", "post-cmd", "check-only",
+}
+
+// checkStreamFlags returns an error if a flag set in flags cannot be
+// combined with -stream.
+func checkStreamFlags(flags *flag.FlagSet, format string) error {
+	if format != "cobertura" {
+		return fmt.Errorf("'-stream' requires the cobertura format")
+	}
+	var incompatible []string
+	flags.Visit(func(f *flag.Flag) {
+		if slices.Contains(streamIncompatibleFlags, f.Name) {
+			incompatible = append(incompatible, "'-"+f.Name+"'")
+		}
+	})
+	if len(incompatible) > 0 {
+		return fmt.Errorf("'-stream' cannot be combined with %s", strings.Join(incompatible, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package cobertura
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestConvertStream(t *testing.T) {
+	t.Parallel()
+
+	convertWith := func(convert func(*os.File, *bytes.Buffer) error) string {
+		in, err := os.Open("testdata/testdata_set.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer in.Close()
+
+		var out bytes.Buffer
+		if err := convert(in, &out); err != nil {
+			t.Fatal(err)
+		}
+		// the timestamp differs between conversions
+		return regexp.MustCompile(`timestamp="\d+"`).ReplaceAllString(out.String(), "")
+	}
+
+	expected := convertWith(func(in *os.File, out *bytes.Buffer) error {
+		return ConvertWithOptions(in, out, &Ignore{}, nil, "testdata")
+	})
+	for _, opts := range []*Options{nil, {MaxMemory: 1}} {
+		actual := convertWith(func(in *os.File, out *bytes.Buffer) error {
+			return ConvertStream(context.Background(), in, out, &Ignore{}, opts, "testdata")
+		})
+		if actual != expected {
+			t.Errorf("streamed report with %+v differs:\n%s\nexpected:\n%s", opts, actual, expected)
+		}
+	}
+}
+
+func TestConvertStreamReleasesBlocks(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	profiles, err := ParseProfiles(in, &Ignore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := newPackageStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	coverage, err := buildCoverage(context.Background(), profiles, &Ignore{}, []string{"testdata"}, nil, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer coverage.close()
+
+	if coverage.LinesValid == 0 {
+		t.Error("no line is reported")
+	}
+	for _, profile := range profiles {
+		if profile.Blocks != nil {
+			t.Errorf("%d blocks of %s are kept once written", len(profile.Blocks), profile.FileName)
+		}
+	}
+}
+
+func TestConvertStreamEmpty(t *testing.T) {
+	t.Parallel()
+
+	var expected, actual bytes.Buffer
+	if err := Convert(strings.NewReader("mode: set\n"), &expected, &Ignore{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConvertStream(context.Background(), strings.NewReader("mode: set\n"), &actual, &Ignore{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	timestamp := regexp.MustCompile(`timestamp="\d+"`)
+	if e, a := timestamp.ReplaceAllString(expected.String(), ""), timestamp.ReplaceAllString(actual.String(), ""); a != e {
+		t.Errorf("streamed empty report differs:\n%s\nexpected:\n%s", a, e)
+	}
+}
+
+func TestCheckStreamFlags(t *testing.T) {
+	t.Parallel()
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.Bool("stream", false, "")
+	flags.String("html-dir", "", "")
+	flags.String("to", "", "")
+	if err := flags.Parse([]string{"-stream", "-to", "coverage.xml"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkStreamFlags(flags, "cobertura"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := checkStreamFlags(flags, "clover"); err == nil {
+		t.Error("expected an error for the clover format")
+	}
+
+	if err := flags.Parse([]string{"-html-dir", "html"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkStreamFlags(flags, "cobertura"); err == nil || !strings.Contains(err.Error(), "'-html-dir'") {
+		t.Errorf("expected an error for -html-dir, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package cobertura
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// resolveSource returns the path and content of the source of the profile,
+// or why it cannot be read.
+func resolveSource(profile *Profile, pkgPkg *packages.Package, fsys fs.FS) (string, []byte, error) {
+	if pkgPkg == nil || pkgPkg.Module == nil {
+		return "", nil, &kindError{kind: ErrPackageNotFound, msg: "package not found in a module"}
+	}
+	absFilePath := findAbsFilePath(pkgPkg, profile.FileName, fsys)
+	if absFilePath == "" {
+		if err := excludedFileError(pkgPkg, profile.FileName); err != nil {
+			return "", nil, err
+		}
+		return "", nil, &kindError{kind: ErrSourceMissing, msg: "missing from the files of package " + pkgPkg.ID}
+	}
+	data, err := readSource(fsys, absFilePath)
+	if err != nil {
+		return "", nil, &kindError{kind: ErrSourceMissing, msg: fmt.Sprintf("unreadable source: %v", err)}
+	}
+	return absFilePath, data, nil
+}
+
+// checkSources returns an error listing every profile whose source does not
+// resolve to an existing, parsable file, or nil if all of them do, so that
+// -strict reports them together before converting anything.  The sources
+// are checked on parallel workers.
+func checkSources(profiles []*Profile, pkgMap map[string]*packages.Package, fsys fs.FS, parallel int) error {
+	checked := parseFiles(profiles, parallel, func(profile *Profile) (*parsedFile, error) {
+		absFilePath, data, err := resolveSource(profile, lookupPackage(pkgMap, getPackageName(profile.FileName)), fsys)
+		if err == nil {
+			if _, parseErr := parser.ParseFile(token.NewFileSet(), absFilePath, data, parser.SkipObjectResolution); parseErr != nil {
+				err = fmt.Errorf("unparsable source: %w", parseErr)
+			}
+		}
+		return nil, err
+	})
+	defer checked.stop()
+
+	var problems []error
+	for _, profile := range profiles {
+		if _, err := checked.next(); err != nil {
+			problems = append(problems, &fileError{file: profile.FileName, err: fmt.Errorf("%s: %w", profile.FileName, err)})
+		}
+	}
+	if len(problems) > 0 {
+		return newListError(fmt.Sprintf("%d source file(s) cannot be converted:", len(problems)), "\n\t", problems)
+	}
+	return nil
+}
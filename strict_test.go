@@ -0,0 +1,52 @@
+package cobertura
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestConvertStrict(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 0
+github.com/franchb/gocover-cobertura/testdata/missing.go:1.1,2.2 1 0
+github.com/franchb/gocover-cobertura/testdata/other.go:1.1,2.2 1 0
+`
+	_, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{Strict: true, Parallel: 4}, []string{"testdata"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if code := ExitCode(err); code != exitPackages {
+		t.Errorf("exit code %d, expected %d", code, exitPackages)
+	}
+	for _, expected := range []string{
+		"2 source file(s)",
+		"testdata/missing.go: missing from the files of package",
+		"testdata/other.go: missing from the files of package",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("missing %q in error %v", expected, err)
+		}
+	}
+	if strings.Contains(err.Error(), "func4.go") {
+		t.Errorf("func4.go reported in error %v", err)
+	}
+	if strings.Index(err.Error(), "missing.go") > strings.Index(err.Error(), "other.go") {
+		t.Errorf("error %v, expected the files in the order of the profile", err)
+	}
+}
+
+func TestConvertStrictNoProblem(t *testing.T) {
+	t.Parallel()
+
+	profile := `mode: set
+github.com/franchb/gocover-cobertura/testdata/func4.go:5.23,6.16 1 0
+`
+	coverage, err := convert(context.Background(), strings.NewReader(profile), &Ignore{}, &Options{Strict: true}, []string{"testdata"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	coverage.close()
+}
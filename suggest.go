@@ -0,0 +1,167 @@
+package cobertura
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	mockFileRe = regexp.MustCompile(`(?:^|/)mock_[^/]*\.go$|_mocks?\.go$`)
+	mockDirRe  = regexp.MustCompile(`(?:^|/)mocks?$`)
+)
+
+// SuggestIgnores scans the Go files below root for files which are likely
+// candidates for exclusion from the report, and prints ready-to-use ignore
+// flags for them.  The files are named relative to the module holding
+// root, as the class filenames the flags match.
+func SuggestIgnores(root string, out io.Writer) error {
+	var generated, mocks, empty []string
+	mockDirs := map[string]bool{}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	modDir := moduleDir(absRoot)
+
+	genIgnore := Ignore{GeneratedFiles: true}
+
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && (name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(modDir, abs)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read file %s: %w", path, err)
+		}
+
+		switch {
+		case genIgnore.Match(rel, data):
+			generated = append(generated, rel)
+		case mockFileRe.MatchString(rel):
+			mocks = append(mocks, rel)
+		case mockDirRe.MatchString(filepath.ToSlash(filepath.Dir(rel))):
+			mockDirs[filepath.ToSlash(filepath.Dir(rel))] = true
+		case !hasStatements(path, data):
+			empty = append(empty, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var flags []string
+
+	if len(generated) > 0 {
+		printSuggestion(out, "generated files", generated)
+		flags = append(flags, "-ignore-gen-files")
+	}
+
+	if len(mockDirs) > 0 {
+		dirs := make([]string, 0, len(mockDirs))
+		for dir := range mockDirs {
+			dirs = append(dirs, dir)
+		}
+		sort.Strings(dirs)
+		printSuggestion(out, "mock directories", dirs)
+		flags = append(flags, fmt.Sprintf("-ignore-dirs '%s'", mockDirRe))
+	}
+
+	var files []string
+	if len(mocks) > 0 {
+		printSuggestion(out, "mock files", mocks)
+		files = append(files, mockFileRe.String())
+	}
+
+	if len(empty) > 0 {
+		printSuggestion(out, "files without statements", empty)
+		for _, name := range empty {
+			// anchored on a path element, not to match datatypes.go for types.go
+			files = append(files, "(?:^|/)"+regexp.QuoteMeta(name)+"$")
+		}
+	}
+
+	if len(files) > 0 {
+		flags = append(flags, fmt.Sprintf("-ignore-files '%s'", strings.Join(files, "|")))
+	}
+
+	if len(flags) == 0 {
+		_, _ = fmt.Fprintln(out, "no ignore suggestions")
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(out, "suggested flags:")
+	for _, f := range flags {
+		_, _ = fmt.Fprintf(out, "  %s\n", f)
+	}
+	return nil
+}
+
+func printSuggestion(out io.Writer, title string, names []string) {
+	_, _ = fmt.Fprintf(out, "%s:\n", title)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(out, "  %s\n", name)
+	}
+}
+
+// moduleDir returns the directory of the go.mod file of the module holding
+// dir, or dir itself out of modules.
+func moduleDir(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// hasStatements reports whether the file declares at least one function
+// with a body, the only places holding statements the profile can count.
+// Unparsable files are assumed to have statements.
+func hasStatements(path string, data []byte) bool {
+	parsed, err := parser.ParseFile(token.NewFileSet(), path, data, parser.SkipObjectResolution)
+	if err != nil {
+		return true
+	}
+	for _, decl := range parsed.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil && len(fn.Body.List) > 0 {
+			return true
+		}
+	}
+	return false
+}
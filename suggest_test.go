@@ -0,0 +1,94 @@
+package cobertura
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSuggestIgnores(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for name, contents := range map[string]string{
+		"foo.go":          "package foo\n\nfunc Foo() int {\n\treturn 1\n}\n",
+		"types.go":        "package foo\n\ntype Bar struct{}\n",
+		"gen.go":          "// Code generated by zzz; DO NOT EDIT.\n\npackage foo\n\nfunc Gen() int {\n\treturn 1\n}\n",
+		"mock_store.go":   "package foo\n\nfunc Mock() int {\n\treturn 1\n}\n",
+		"mocks/client.go": "package mocks\n\nfunc Client() int {\n\treturn 1\n}\n",
+		"foo_test.go":     "package foo\n",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out strings.Builder
+	if err := SuggestIgnores(root, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"generated files:\n  gen.go\n",
+		"mock directories:\n  mocks\n",
+		"mock files:\n  mock_store.go\n",
+		"files without statements:\n  types.go\n",
+		"  -ignore-gen-files\n",
+		`  -ignore-files '(?:^|/)mock_[^/]*\.go$|_mocks?\.go$|(?:^|/)types\.go$'`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("output does not contain %q:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "foo") {
+		t.Errorf("output should not mention foo.go nor foo_test.go:\n%s", out.String())
+	}
+}
+
+func TestSuggestIgnoresModuleRelative(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for name, contents := range map[string]string{
+		"go.mod":         "module example.com/m\n",
+		"pkg/types.go":   "package pkg\n\ntype Bar struct{}\n",
+		"pkg/pkg.go":     "package pkg\n\nfunc Foo() int {\n\treturn 1\n}\n",
+		"other/other.go": "package other\n\ntype Baz struct{}\n",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out strings.Builder
+	if err := SuggestIgnores(filepath.Join(root, "pkg"), &out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `  -ignore-files '(?:^|/)pkg/types\.go$'`
+	if !strings.Contains(out.String(), expected) {
+		t.Fatalf("output does not contain %q:\n%s", expected, out.String())
+	}
+
+	files := regexp.MustCompile(`(?:^|/)pkg/types\.go$`)
+	for name, ignored := range map[string]bool{
+		"pkg/types.go":                    true,
+		"example.com/m/pkg/types.go":      true,
+		"pkg/datatypes.go":                false,
+		"otherpkg/types.go":               false,
+		"example.com/m/otherpkg/types.go": false,
+	} {
+		if files.MatchString(name) != ignored {
+			t.Errorf("%s ignored %t, expected %t", name, !ignored, ignored)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+// Counts are the lines and branch outcomes of a part of a report, covered
+// and in total, and their rates, 0 when there is nothing to cover.
+type Counts struct {
+	LinesCovered    int64   `json:"lines_covered"`
+	LinesValid      int64   `json:"lines_valid"`
+	LineRate        float64 `json:"line_rate"`
+	BranchesCovered int64   `json:"branches_covered"`
+	BranchesValid   int64   `json:"branches_valid"`
+	BranchRate      float64 `json:"branch_rate"`
+}
+
+// CoverageSummary is the coverage of a report, in total and per package.
+type CoverageSummary struct {
+	Counts
+	Packages []PackageSummary `json:"packages"`
+}
+
+// PackageSummary is the coverage of a package, in total and per file.
+type PackageSummary struct {
+	Name string `json:"name"`
+	Counts
+	Files []FileSummary `json:"files"`
+}
+
+// FileSummary is the coverage of a source file, in total and per function.
+type FileSummary struct {
+	Filename string `json:"filename"`
+	Counts
+	Functions []FunctionSummary `json:"functions"`
+}
+
+// FunctionSummary is the coverage of a function, declared at Line of its
+// file when known.
+type FunctionSummary struct {
+	Name  string `json:"name"`
+	Class string `json:"class"`
+	Line  int    `json:"line,omitempty"`
+	Counts
+}
+
+// Summary returns the covered and valid counts and the rates of the report,
+// of its packages, of their files in the order of their classes, and of the
+// functions of the files.
+func Summary(cov *Coverage) *CoverageSummary {
+	summary := &CoverageSummary{Packages: make([]PackageSummary, 0, len(cov.Packages))}
+	for _, pkg := range cov.Packages {
+		pkgSummary := PackageSummary{Name: pkg.Name, Files: []FileSummary{}}
+		byName := map[string]int{}
+		for _, class := range pkg.Classes {
+			index, ok := byName[class.Filename]
+			if !ok {
+				index = len(pkgSummary.Files)
+				byName[class.Filename] = index
+				pkgSummary.Files = append(pkgSummary.Files, FileSummary{Filename: class.Filename, Functions: []FunctionSummary{}})
+			}
+			file := &pkgSummary.Files[index]
+			for _, method := range class.Methods {
+				function := FunctionSummary{Name: method.Name, Class: class.Name, Line: method.line}
+				function.add(method.NumLinesWithHits(), method.NumLines(), method.NumBranchesCovered(), method.NumBranches())
+				file.Functions = append(file.Functions, function)
+				file.add(function.LinesCovered, function.LinesValid, function.BranchesCovered, function.BranchesValid)
+			}
+		}
+		for _, file := range pkgSummary.Files {
+			pkgSummary.add(file.LinesCovered, file.LinesValid, file.BranchesCovered, file.BranchesValid)
+		}
+		summary.add(pkgSummary.LinesCovered, pkgSummary.LinesValid, pkgSummary.BranchesCovered, pkgSummary.BranchesValid)
+		summary.Packages = append(summary.Packages, pkgSummary)
+	}
+	return summary
+}
+
+// add adds the counts of a part and updates the rates.
+func (c *Counts) add(linesCovered, linesValid, branchesCovered, branchesValid int64) {
+	c.LinesCovered += linesCovered
+	c.LinesValid += linesValid
+	c.BranchesCovered += branchesCovered
+	c.BranchesValid += branchesValid
+	c.LineRate = countRate(c.LinesCovered, c.LinesValid)
+	c.BranchRate = countRate(c.BranchesCovered, c.BranchesValid)
+}
+
+// countRate returns covered/valid, 0 when there is nothing to cover.
+func countRate(covered, valid int64) float64 {
+	if valid == 0 {
+		return 0
+	}
+	return float64(covered) / float64(valid)
+}
+
+// Line coverage percentages from which the summary is yellow, then green.
+const (
+	summaryYellow = 50.0
+	summaryGreen  = 80.0
+)
+
+// writeSummary writes a tree of the packages and their files with their line
+// coverage, then the total, for humans in a terminal.  With color, the
+// percentages are green, yellow or red according to the thresholds.
+func (cov *Coverage) writeSummary(out io.Writer, color bool) error {
+	tabber := tabwriter.NewWriter(out, 1, 8, 2, ' ', 0)
+	for _, pkg := range Summary(cov).Packages {
+		_, _ = fmt.Fprintf(tabber, "%s\t%s\n", pkg.Name,
+			summaryPercent(pkg.LinesCovered, pkg.LinesValid, color))
+		for _, file := range pkg.Files {
+			_, _ = fmt.Fprintf(tabber, "  %s\t%s\n", file.Filename,
+				summaryPercent(file.LinesCovered, file.LinesValid, color))
+		}
+	}
+	_, _ = fmt.Fprintf(tabber, "total\t%s (%d/%d lines)\n",
+		summaryPercent(cov.LinesCovered, cov.LinesValid, color), cov.LinesCovered, cov.LinesValid)
+	return tabber.Flush()
+}
+
+func summaryPercent(covered, valid int64, color bool) string {
+	p := percent(covered, valid)
+	if !color || valid == 0 {
+		return p
+	}
+	code := "31" // red
+	switch actual := linePercent(covered, valid); {
+	case actual >= summaryGreen:
+		code = "32"
+	case actual >= summaryYellow:
+		code = "33"
+	}
+	return "\x1b[" + code + "m" + p + "\x1b[0m"
+}
+
+// colorOutput reports whether out is a terminal, and colors are not disabled
+// by the NO_COLOR environment variable.
+func colorOutput(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	return ok && isTerminal(file) && os.Getenv("NO_COLOR") == ""
+}
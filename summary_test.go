@@ -0,0 +1,101 @@
+package cobertura
+
+import (
+	"bytes"
+	"testing"
+)
+
+func summaryCoverage() *Coverage {
+	lines := func(hits ...int64) Lines {
+		lines := Lines{}
+		for number, hit := range hits {
+			lines = append(lines, &Line{Number: number + 1, Hits: hit})
+		}
+		return lines
+	}
+	return &Coverage{LinesCovered: 5, LinesValid: 8, Packages: []*Package{
+		{Name: "example.com/app/auth", Classes: []*Class{
+			{Name: "-", Filename: "auth/token.go", Methods: []*Method{{Name: "Parse", Lines: lines(1, 1, 1, 1)}}},
+			{Name: "-", Filename: "auth/login.go", Methods: []*Method{{Name: "Login", Lines: lines(1, 0, 0)}}},
+		}},
+		{Name: "example.com/app/db", Classes: []*Class{
+			{Name: "-", Filename: "db/db.go", Methods: []*Method{{Name: "Open", Lines: lines(0)}}},
+		}},
+	}}
+}
+
+func TestWriteSummary(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := summaryCoverage().writeSummary(&out, false); err != nil {
+		t.Fatal(err)
+	}
+	expected := `example.com/app/auth  71.4%
+  auth/token.go       100.0%
+  auth/login.go       33.3%
+example.com/app/db    0.0%
+  db/db.go            0.0%
+total                 62.5% (5/8 lines)
+`
+	if out.String() != expected {
+		t.Errorf("summary:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestWriteSummaryColor(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := summaryCoverage().writeSummary(&out, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"\x1b[33m71.4%\x1b[0m\n",
+		"\x1b[32m100.0%\x1b[0m\n",
+		"\x1b[31m33.3%\x1b[0m\n",
+	} {
+		if !bytes.Contains(out.Bytes(), []byte(expected)) {
+			t.Errorf("missing %q in %q", expected, out.String())
+		}
+	}
+	if colorOutput(&out) {
+		t.Error("colors enabled for a buffer")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+
+	cov := NewCoverage()
+	pkg := cov.AddPackage("example.com/p")
+	cache := pkg.AddClass("Cache", "p/cache.go")
+	get := cache.AddMethod("Get", "")
+	cache.AddLine(get, 10, 1)
+	cache.AddLine(get, 11, 0).SetBranches(1, 2)
+	fileFuncs := pkg.AddClass("-", "p/cache.go")
+	fileFuncs.AddLine(fileFuncs.AddMethod("New", ""), 3, 2)
+	other := pkg.AddClass("-", "p/other.go")
+	other.AddLine(other.AddMethod("Unused", ""), 5, 0)
+	cov.AddPackage("example.com/empty")
+
+	summary := Summary(cov)
+	if summary.LinesCovered != 2 || summary.LinesValid != 4 || summary.LineRate != 0.5 {
+		t.Errorf("total %+v, expected 2/4 lines", summary.Counts)
+	}
+	if summary.BranchesCovered != 1 || summary.BranchesValid != 2 || summary.BranchRate != 0.5 {
+		t.Errorf("total %+v, expected 1/2 branches", summary.Counts)
+	}
+	if len(summary.Packages) != 2 || summary.Packages[1].LinesValid != 0 || summary.Packages[1].LineRate != 0 {
+		t.Fatalf("packages %+v, expected an empty second package", summary.Packages)
+	}
+	files := summary.Packages[0].Files
+	if len(files) != 2 || files[0].Filename != "p/cache.go" || files[0].LinesValid != 3 || files[1].LineRate != 0 {
+		t.Fatalf("files %+v, expected p/cache.go with 3 lines then p/other.go", files)
+	}
+	functions := files[0].Functions
+	if len(functions) != 2 || functions[0].Name != "Get" || functions[0].Class != "Cache" ||
+		functions[0].LineRate != 0.5 || functions[1].Name != "New" || functions[1].LinesCovered != 1 {
+		t.Errorf("functions %+v, expected Get at 0.5 then New", functions)
+	}
+}
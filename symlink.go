@@ -0,0 +1,46 @@
+package cobertura
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// realPaths caches the paths with their symlinks resolved, as the files are
+// named concurrently.
+var realPaths sync.Map
+
+// realPath returns the path with its symlinks resolved, or the path itself
+// if it cannot be resolved, as when it does not exist on this host.
+func realPath(path string) string {
+	if resolved, ok := realPaths.Load(path); ok {
+		return resolved.(string)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		resolved = path
+	}
+	realPaths.Store(path, resolved)
+	return resolved
+}
+
+// relPath returns path relative to dir, and whether it is dir or below it.
+// When it is not, their symlinks are resolved, as the checkout may be behind
+// a symlink, common with Bazel or Buildkite workspaces, which go list
+// resolves for some of the paths only.
+func relPath(dir, path string) (string, bool) {
+	if rel, ok := below(dir, path); ok {
+		return rel, true
+	}
+	return below(realPath(dir), realPath(path))
+}
+
+// below returns path relative to dir, and whether it is dir or below it,
+// without resolving symlinks.
+func below(dir, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,42 @@
+package cobertura
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestSymlinkedModule(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	realDir := filepath.Join(dir, "realDir")
+	link := filepath.Join(dir, "link")
+	if err := os.MkdirAll(filepath.Join(realDir, "internal"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(realDir, link); err != nil {
+		t.Skipf("no symlink: %v", err)
+	}
+	file := filepath.Join(realDir, "internal", "a.go")
+	if err := os.WriteFile(file, []byte("package internal\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the module directory behind the symlink, the files resolved
+	module := &packages.Module{Path: "example.com/m", Dir: link}
+	if actual := moduleFileName("a.go", file, module); actual != "internal/a.go" {
+		t.Errorf("file name %s, expected internal/a.go", actual)
+	}
+	if _, ok := relPath(link, filepath.Join(dir, "other")); ok {
+		t.Error("a path out of the symlinked directory is below it")
+	}
+
+	pkg := &packages.Package{ID: "example.com/m/internal", GoFiles: []string{file}}
+	pkgMap := map[string]*packages.Package{pkg.ID: pkg}
+	if actual := lookupPackage(pkgMap, filepath.Join(link, "internal")); actual != pkg {
+		t.Errorf("package %v, expected example.com/m/internal through the symlink", actual)
+	}
+}
@@ -0,0 +1,53 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+)
+
+// writeTeamCity writes the coverage as TeamCity build statistic service
+// messages, for classes, methods and lines.
+func (cov *Coverage) writeTeamCity(out io.Writer) error {
+	var classes, coveredClasses, methods, coveredMethods int64
+	for _, pkg := range cov.Packages {
+		for _, class := range pkg.Classes {
+			classes++
+			if class.NumLinesWithHits() > 0 {
+				coveredClasses++
+			}
+			for _, method := range class.Methods {
+				methods++
+				if method.NumLinesWithHits() > 0 {
+					coveredMethods++
+				}
+			}
+		}
+	}
+
+	for _, stat := range []struct {
+		key   string
+		value string
+	}{
+		{"CodeCoverageAbsCCovered", fmt.Sprint(coveredClasses)},
+		{"CodeCoverageAbsCTotal", fmt.Sprint(classes)},
+		{"CodeCoverageC", teamCityRate(coveredClasses, classes)},
+		{"CodeCoverageAbsMCovered", fmt.Sprint(coveredMethods)},
+		{"CodeCoverageAbsMTotal", fmt.Sprint(methods)},
+		{"CodeCoverageM", teamCityRate(coveredMethods, methods)},
+		{"CodeCoverageAbsLCovered", fmt.Sprint(cov.LinesCovered)},
+		{"CodeCoverageAbsLTotal", fmt.Sprint(cov.LinesValid)},
+		{"CodeCoverageL", teamCityRate(cov.LinesCovered, cov.LinesValid)},
+	} {
+		if _, err := fmt.Fprintf(out, "##teamcity[buildStatisticValue key='%s' value='%s']\n", stat.key, stat.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func teamCityRate(covered, valid int64) string {
+	if valid == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.2f", 100*float64(covered)/float64(valid))
+}
@@ -0,0 +1,41 @@
+package cobertura
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteTeamCity(t *testing.T) {
+	t.Parallel()
+
+	coverage := Coverage{
+		LinesCovered: 2,
+		LinesValid:   3,
+		Packages: []*Package{{Classes: []*Class{
+			{Methods: []*Method{
+				{Lines: Lines{{Number: 1, Hits: 1}, {Number: 2, Hits: 1}}},
+				{Lines: Lines{{Number: 4, Hits: 0}}},
+			}},
+			{Methods: []*Method{}},
+		}}},
+	}
+
+	var out strings.Builder
+	if err := coverage.writeTeamCity(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `##teamcity[buildStatisticValue key='CodeCoverageAbsCCovered' value='1']
+##teamcity[buildStatisticValue key='CodeCoverageAbsCTotal' value='2']
+##teamcity[buildStatisticValue key='CodeCoverageC' value='50.00']
+##teamcity[buildStatisticValue key='CodeCoverageAbsMCovered' value='1']
+##teamcity[buildStatisticValue key='CodeCoverageAbsMTotal' value='2']
+##teamcity[buildStatisticValue key='CodeCoverageM' value='50.00']
+##teamcity[buildStatisticValue key='CodeCoverageAbsLCovered' value='2']
+##teamcity[buildStatisticValue key='CodeCoverageAbsLTotal' value='3']
+##teamcity[buildStatisticValue key='CodeCoverageL' value='66.67']
+`
+	if out.String() != expected {
+		t.Errorf("unexpected service messages:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
@@ -0,0 +1,175 @@
+package cobertura
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Rules are the line coverage requirements of a report checked by
+// Evaluate.
+type Rules struct {
+	// Min is the minimum total line coverage percentage, as -fail-under.
+	// Zero means none.
+	Min float64
+	// Packages are the minimums of the packages, as -thresholds, the last
+	// rule matching a package applying to it.
+	Packages []PackageRule
+	// Baseline, when not nil, is a previous report which the total and
+	// package line coverage must not drop from by more than Tolerance
+	// percentage points, as -baseline.
+	Baseline  *Coverage
+	Tolerance float64
+}
+
+// PackageRule is the minimum line coverage percentage of the packages
+// matching Pattern, the end of their import paths on path element
+// boundaries, which may end with /... to match the sub-packages too.
+type PackageRule struct {
+	Pattern string
+	Min     float64
+}
+
+// Evaluate returns the violations of the rules by the report: the total
+// below its minimum, the packages below theirs, then the regressions from
+// the baseline.  Packages without lines to cover are skipped.
+func Evaluate(cov *Coverage, rules Rules) []*ThresholdError {
+	var violations []*ThresholdError
+	if rules.Min > 0 {
+		if actual := linePercent(cov.LinesCovered, cov.LinesValid); actual < rules.Min {
+			violations = append(violations, &ThresholdError{Actual: actual, Required: rules.Min})
+		}
+	}
+	if len(rules.Packages) > 0 {
+		violations = append(violations, packageViolations(cov, rules.Packages)...)
+	}
+	if rules.Baseline != nil {
+		violations = append(violations, baselineViolations(cov, rules.Baseline, rules.Tolerance)...)
+	}
+	return violations
+}
+
+// packageViolations returns the packages whose line coverage is below the
+// minimum of the last rule matching them.
+func packageViolations(cov *Coverage, rules []PackageRule) []*ThresholdError {
+	var violations []*ThresholdError
+	for _, pkg := range Summary(cov).Packages {
+		if pkg.LinesValid == 0 {
+			continue
+		}
+		var rule *PackageRule
+		for index := range rules {
+			if matchPackagePattern(rules[index].Pattern, pkg.Name) {
+				rule = &rules[index]
+			}
+		}
+		if rule == nil {
+			continue
+		}
+		if actual := linePercent(pkg.LinesCovered, pkg.LinesValid); actual < rule.Min {
+			violations = append(violations, &ThresholdError{
+				Kind: ThresholdPackage, Package: pkg.Name, Pattern: rule.Pattern, Actual: actual, Required: rule.Min,
+			})
+		}
+	}
+	return violations
+}
+
+// checkRules returns the errors of the violations of the rules: the total
+// below its minimum, then the lists of the packages below theirs and of the
+// regressions from the baseline.
+func checkRules(coverage *Coverage, rules Rules) error {
+	var errs []error
+	var packages, regressions []*ThresholdError
+	for _, violation := range Evaluate(coverage, rules) {
+		switch violation.Kind {
+		case ThresholdMin:
+			errs = append(errs, violation)
+		case ThresholdPackage:
+			packages = append(packages, violation)
+		case ThresholdBaseline:
+			regressions = append(regressions, violation)
+		}
+	}
+	errs = append(errs,
+		violationsError("packages below their coverage threshold:", packages),
+		violationsError("line coverage regressed from the baseline:", regressions))
+	return errors.Join(errs...)
+}
+
+// violationsError returns an error of the header and the violations, one
+// per line, or nil without violations.
+func violationsError(header string, violations []*ThresholdError) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	errs := make([]error, len(violations))
+	for index, violation := range violations {
+		errs[index] = violation
+	}
+	return newListError(header, "\n  ", errs)
+}
+
+// parseThresholds reads threshold rules, one "pattern: percent" per line,
+// such as "internal/auth/...: 90".  Empty lines and lines starting with #
+// are skipped.
+func parseThresholds(in io.Reader) ([]PackageRule, error) {
+	var rules []PackageRule
+	scanner := bufio.NewScanner(in)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		index := strings.LastIndex(line, ":")
+		if index < 0 {
+			return nil, fmt.Errorf("line %d: expected pattern: percent, got %q", lineNo, line)
+		}
+		pattern := strings.Trim(strings.TrimSpace(line[:index]), `"'`)
+		min, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(line[index+1:]), "%"), 64)
+		if err != nil || pattern == "" {
+			return nil, fmt.Errorf("line %d: expected pattern: percent, got %q", lineNo, line)
+		}
+		rules = append(rules, PackageRule{Pattern: pattern, Min: min})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// matchPackagePattern reports whether the package import path name matches
+// pattern, which may end with /... to match the sub-packages too.  Patterns
+// match the end of import paths, on path element boundaries, so module
+// relative patterns such as internal/auth match.
+func matchPackagePattern(pattern, name string) bool {
+	matches := func(prefix string) bool {
+		return name == prefix || strings.HasSuffix(name, "/"+prefix)
+	}
+	if base, ok := strings.CutSuffix(pattern, "/..."); ok {
+		if matches(base) {
+			return true
+		}
+		for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if dir == base || strings.HasSuffix(dir, "/"+base) {
+				return true
+			}
+		}
+		return false
+	}
+	return matches(pattern)
+}
+
+func readThresholds(name string) ([]PackageRule, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseThresholds(file)
+}
@@ -0,0 +1,145 @@
+package cobertura
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckRulesMin(t *testing.T) {
+	t.Parallel()
+
+	coverage := &Coverage{LinesCovered: 3, LinesValid: 4}
+	if err := checkRules(coverage, Rules{Min: 75}); err != nil {
+		t.Errorf("unexpected error at the threshold: %v", err)
+	}
+	err := checkRules(coverage, Rules{Min: 80})
+	if err == nil || err.Error() != "line coverage 75.00% is below the threshold of 80.00%" {
+		t.Errorf("unexpected error %v", err)
+	}
+	var threshold *ThresholdError
+	if !errors.As(err, &threshold) || threshold.Actual != 75 || threshold.Required != 80 || threshold.Package != "" {
+		t.Errorf("error %#v, expected a ThresholdError of the total", err)
+	}
+	if err := checkRules(&Coverage{}, Rules{Min: 1}); err == nil {
+		t.Error("expected an error for an empty coverage")
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	t.Parallel()
+
+	rules, err := parseThresholds(strings.NewReader(`# security sensitive packages
+internal/auth/...: 90
+
+"example.com/cmd": 50%
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PackageRule{{"internal/auth/...", 90}, {"example.com/cmd", 50}}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+
+	for _, input := range []string{"internal/auth 90", "internal/auth: high", ": 90"} {
+		if _, err := parseThresholds(strings.NewReader(input)); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestMatchPackagePattern(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		pattern, name string
+		expected      bool
+	}{
+		{"internal/auth", "example.com/repo/internal/auth", true},
+		{"internal/auth", "example.com/repo/internal/auth/jwt", false},
+		{"internal/auth", "example.com/repo/internal/oauth", false},
+		{"internal/auth/...", "example.com/repo/internal/auth", true},
+		{"internal/auth/...", "example.com/repo/internal/auth/jwt/keys", true},
+		{"internal/auth/...", "example.com/repo/internal/authz", false},
+		{"example.com/repo/...", "example.com/repo/cmd", true},
+		{"example.com/repo", "example.com/repo", true},
+	} {
+		if actual := matchPackagePattern(tc.pattern, tc.name); actual != tc.expected {
+			t.Errorf("matchPackagePattern(%q, %q) = %v, expected %v", tc.pattern, tc.name, actual, tc.expected)
+		}
+	}
+}
+
+func TestCheckRulesPackages(t *testing.T) {
+	t.Parallel()
+
+	pkg := func(name string, covered, valid int) *Package {
+		lines := Lines{}
+		for number := 1; number <= valid; number++ {
+			hits := int64(0)
+			if number <= covered {
+				hits = 1
+			}
+			lines = append(lines, &Line{Number: number, Hits: hits})
+		}
+		return &Package{Name: name, Classes: []*Class{{Methods: []*Method{{Lines: lines}}}}}
+	}
+	coverage := &Coverage{Packages: []*Package{
+		pkg("example.com/repo/internal/auth", 8, 10),
+		pkg("example.com/repo/internal/auth/legacy", 1, 10),
+		pkg("example.com/repo/internal/auth/jwt", 9, 10),
+		pkg("example.com/repo/cmd", 0, 10),
+		pkg("example.com/repo/internal/auth/empty", 0, 0),
+	}}
+
+	err := checkRules(coverage, Rules{Packages: []PackageRule{
+		{"internal/auth/...", 90},
+		{"internal/auth/legacy", 10},
+	}})
+	expected := `packages below their coverage threshold:
+  example.com/repo/internal/auth: 80.00% < 90.00% (internal/auth/...)`
+	if err == nil || err.Error() != expected {
+		t.Errorf("unexpected error:\n%v\nexpected:\n%s", err, expected)
+	}
+	var threshold *ThresholdError
+	if !errors.As(err, &threshold) || threshold.Package != "example.com/repo/internal/auth" || threshold.Actual != 80 {
+		t.Errorf("error %#v, expected a ThresholdError of internal/auth", err)
+	}
+	if code := ExitCode(err); code != exitThreshold {
+		t.Errorf("exit code %d, expected %d", code, exitThreshold)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	baseline := baselineCoverage([]int64{1, 1, 0, 0}, []int64{1, 1})
+	coverage := baselineCoverage([]int64{1, 0, 0, 0}, []int64{1, 1})
+	coverage.Recompute()
+
+	violations := Evaluate(coverage, Rules{
+		Min:      70,
+		Packages: []PackageRule{{Pattern: "example.com/...", Min: 50}, {Pattern: "example.com/b", Min: 0}},
+		Baseline: baseline,
+	})
+	expected := []ThresholdError{
+		{Kind: ThresholdMin, Actual: 50, Required: 70},
+		{Kind: ThresholdPackage, Package: "example.com/a", Pattern: "example.com/...", Actual: 25, Required: 50},
+		{Kind: ThresholdBaseline, Actual: 50, Required: 100 * 4.0 / 6, Baseline: 100 * 4.0 / 6},
+		{Kind: ThresholdBaseline, Package: "example.com/a", Actual: 25, Required: 50, Baseline: 50},
+	}
+	if len(violations) != len(expected) {
+		t.Fatalf("violations %v, expected %v", violations, expected)
+	}
+	for index, violation := range violations {
+		if *violation != expected[index] {
+			t.Errorf("violation %d: %+v, expected %+v", index, *violation, expected[index])
+		}
+	}
+
+	if violations := Evaluate(coverage, Rules{Baseline: baseline, Tolerance: 30}); len(violations) != 0 {
+		t.Errorf("violations %v within the tolerance", violations)
+	}
+}
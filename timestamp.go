@@ -0,0 +1,75 @@
+package cobertura
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Units of the timestamp of the Cobertura reports, kept in milliseconds in
+// the coverage.
+const (
+	timestampUnitSeconds = "seconds" // as the Cobertura DTD and most consumers
+	timestampUnitMillis  = "ms"      // as the earlier versions
+)
+
+// MarshalXML encodes the coverage, with its timestamp in seconds, as the
+// Cobertura DTD and most consumers, or in milliseconds as the earlier
+// versions with Options.TimestampMillis.
+func (cov Coverage) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type coverage Coverage // without the methods, not to recurse
+	doc := coverage(cov)
+	if !cov.timestampMillis {
+		doc.Timestamp /= 1000
+	}
+	start.Name = xml.Name{Local: "coverage"} // not the name of the type
+	return e.EncodeElement(doc, start)
+}
+
+// UnmarshalXML decodes a coverage, with its timestamp in milliseconds.  The
+// unit of the timestamp of the document is guessed: seconds hold in 11
+// digits until the year 5138, while milliseconds since 1973 need more.
+func (cov *Coverage) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type coverage Coverage // without the methods, not to recurse
+	if err := d.DecodeElement((*coverage)(cov), &start); err != nil {
+		return err
+	}
+	if cov.Timestamp > 0 && cov.Timestamp < 100_000_000_000 {
+		cov.Timestamp *= 1000
+	}
+	return nil
+}
+
+// reportTime returns the time of the report given by the -timestamp flag
+// value or else by the SOURCE_DATE_EPOCH environment variable, for
+// reproducible builds, and false if neither is set.
+func reportTime(value string, lookup func(string) (string, bool)) (time.Time, bool, error) {
+	if value != "" {
+		t, err := parseTimestamp(value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("bad '-timestamp' value: %w", err)
+		}
+		return t, true, nil
+	}
+	if epoch, ok := lookup("SOURCE_DATE_EPOCH"); ok && epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("bad SOURCE_DATE_EPOCH value: %w", err)
+		}
+		return time.Unix(seconds, 0), true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// parseTimestamp parses a time given as Unix seconds or in RFC 3339 format.
+func parseTimestamp(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither Unix seconds nor RFC 3339", value)
+	}
+	return t, nil
+}
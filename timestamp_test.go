@@ -0,0 +1,106 @@
+package cobertura
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportTime(t *testing.T) {
+	t.Parallel()
+
+	env := func(value string) func(string) (string, bool) {
+		return func(name string) (string, bool) {
+			if name == "SOURCE_DATE_EPOCH" && value != "" {
+				return value, true
+			}
+			return "", false
+		}
+	}
+	for _, test := range []struct {
+		value, epoch string
+		expected     int64
+		ok           bool
+	}{
+		{"", "", 0, false},
+		{"1700000000", "", 1700000000, true},
+		{"2023-11-14T22:13:20Z", "", 1700000000, true},
+		{"", "1600000000", 1600000000, true},
+		{"1700000000", "1600000000", 1700000000, true},
+	} {
+		actual, ok, err := reportTime(test.value, env(test.epoch))
+		if err != nil {
+			t.Errorf("reportTime(%q) with SOURCE_DATE_EPOCH=%q: %v", test.value, test.epoch, err)
+			continue
+		}
+		if ok != test.ok || (ok && actual.Unix() != test.expected) {
+			t.Errorf("reportTime(%q) with SOURCE_DATE_EPOCH=%q = %v, %v, expected %v, %v",
+				test.value, test.epoch, actual.Unix(), ok, test.expected, test.ok)
+		}
+	}
+
+	if _, _, err := reportTime("yesterday", env("")); err == nil {
+		t.Error("no error for a bad -timestamp")
+	}
+	if _, _, err := reportTime("", env("2023-11-14")); err == nil {
+		t.Error("no error for a bad SOURCE_DATE_EPOCH")
+	}
+	if actual, _, _ := reportTime("0", env("")); !actual.Equal(time.Unix(0, 0)) {
+		t.Errorf("reportTime(0) = %v", actual)
+	}
+}
+
+func TestTimestampUnit(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		unit     string
+		expected string
+	}{
+		{timestampUnitSeconds, `timestamp="1700000000"`},
+		{timestampUnitMillis, `timestamp="1700000000123"`},
+	} {
+		cov := &Coverage{Timestamp: 1700000000123, timestampMillis: test.unit == timestampUnitMillis}
+		var out bytes.Buffer
+		if err := cov.writeXML(&out); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), test.expected) {
+			t.Errorf("%s: report without %s:\n%s", test.unit, test.expected, out.String())
+		}
+		read, err := readCobertura(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.Timestamp/1000 != cov.Timestamp/1000 {
+			t.Errorf("%s: timestamp %d read, expected %d", test.unit, read.Timestamp, cov.Timestamp)
+		}
+		if cov.Timestamp != 1700000000123 {
+			t.Errorf("%s: timestamp %d of the coverage changed by writing it", test.unit, cov.Timestamp)
+		}
+	}
+}
+
+func TestMarshalCoverageTimestamp(t *testing.T) {
+	t.Parallel()
+
+	cov := NewCoverage("/src")
+	cov.Timestamp = time.Unix(1700000000, 0).UnixMilli()
+	data, err := xml.Marshal(cov)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`timestamp="1700000000"`)) {
+		t.Errorf("report without the timestamp in seconds:\n%s", data)
+	}
+
+	var read Coverage
+	if err := xml.Unmarshal(data, &read); err != nil {
+		t.Fatal(err)
+	}
+	if read.Timestamp != cov.Timestamp {
+		t.Errorf("timestamp %d unmarshaled, expected the milliseconds %d", read.Timestamp, cov.Timestamp)
+	}
+}
@@ -0,0 +1,53 @@
+package cobertura
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// writeUncovered writes the spans of lines without hits, as file:first-last,
+// grouped by package, for developers to jump straight to the gaps.
+func (cov *Coverage) writeUncovered(out io.Writer) error {
+	for _, pkg := range cov.Packages {
+		var fileNames []string
+		uncovered := map[string][]int{}
+		for _, class := range pkg.Classes {
+			lines, err := class.loadLines()
+			if err != nil {
+				return err
+			}
+			for _, line := range lines {
+				if line.Hits > 0 {
+					continue
+				}
+				if _, ok := uncovered[class.Filename]; !ok {
+					fileNames = append(fileNames, class.Filename)
+				}
+				uncovered[class.Filename] = append(uncovered[class.Filename], line.Number)
+			}
+		}
+		if len(fileNames) == 0 {
+			continue
+		}
+
+		_, _ = fmt.Fprintln(out, pkg.Name)
+		for _, fileName := range fileNames {
+			numbers := uncovered[fileName]
+			sort.Ints(numbers)
+			for start := 0; start < len(numbers); {
+				end := start
+				for end+1 < len(numbers) && numbers[end+1] <= numbers[end]+1 {
+					end++
+				}
+				if numbers[end] == numbers[start] {
+					_, _ = fmt.Fprintf(out, "  %s:%d\n", fileName, numbers[start])
+				} else {
+					_, _ = fmt.Fprintf(out, "  %s:%d-%d\n", fileName, numbers[start], numbers[end])
+				}
+				start = end + 1
+			}
+		}
+	}
+	return nil
+}
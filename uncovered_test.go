@@ -0,0 +1,36 @@
+package cobertura
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteUncovered(t *testing.T) {
+	t.Parallel()
+
+	coverage := &Coverage{Packages: []*Package{
+		{Name: "example.com/a", Classes: []*Class{
+			{Name: "T", Filename: "a/t.go", Lines: Lines{
+				{Number: 3, Hits: 0}, {Number: 4, Hits: 0}, {Number: 5, Hits: 2}, {Number: 7, Hits: 0},
+			}},
+			{Name: "-", Filename: "a/f.go", Lines: Lines{{Number: 10, Hits: 1}}},
+			{Name: "U", Filename: "a/t.go", Lines: Lines{{Number: 8, Hits: 0}, {Number: 12, Hits: 0}}},
+		}},
+		{Name: "example.com/b", Classes: []*Class{
+			{Name: "-", Filename: "b/b.go", Lines: Lines{{Number: 1, Hits: 1}}},
+		}},
+	}}
+
+	var out bytes.Buffer
+	if err := coverage.writeUncovered(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `example.com/a
+  a/t.go:3-4
+  a/t.go:7-8
+  a/t.go:12
+`
+	if out.String() != expected {
+		t.Errorf("uncovered:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
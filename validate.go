@@ -0,0 +1,177 @@
+package cobertura
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// particle is an element of a content model, occurring between min and max
+// times; max < 0 means unbounded.
+type particle struct {
+	name     string
+	min, max int
+}
+
+// elementRule mirrors an element declaration of the coverage-04 DTD.
+type elementRule struct {
+	children []particle // in sequence
+	text     bool       // #PCDATA
+	required []string
+	numeric  []string
+}
+
+var coberturaDTD = map[string]elementRule{
+	"coverage": {
+		children: []particle{{"sources", 0, 1}, {"packages", 1, 1}},
+		required: []string{
+			"line-rate", "branch-rate", "lines-covered", "lines-valid", "branches-covered",
+			"branches-valid", "complexity", "version", "timestamp",
+		},
+		numeric: []string{
+			"line-rate", "branch-rate", "lines-covered", "lines-valid", "branches-covered",
+			"branches-valid", "complexity", "timestamp",
+		},
+	},
+	"sources":  {children: []particle{{"source", 0, -1}}},
+	"source":   {text: true},
+	"packages": {children: []particle{{"package", 0, -1}}},
+	"package": {
+		children: []particle{{"classes", 1, 1}},
+		required: []string{"name", "line-rate", "branch-rate", "complexity"},
+		numeric:  []string{"line-rate", "branch-rate", "complexity"},
+	},
+	"classes": {children: []particle{{"class", 0, -1}}},
+	"class": {
+		children: []particle{{"methods", 1, 1}, {"lines", 1, 1}},
+		required: []string{"name", "filename", "line-rate", "branch-rate", "complexity"},
+		numeric:  []string{"line-rate", "branch-rate", "complexity"},
+	},
+	"methods": {children: []particle{{"method", 0, -1}}},
+	"method": {
+		children: []particle{{"lines", 1, 1}},
+		required: []string{"name", "signature", "line-rate", "branch-rate", "complexity"},
+		numeric:  []string{"line-rate", "branch-rate", "complexity"},
+	},
+	"lines": {children: []particle{{"line", 0, -1}}},
+	"line": {
+		children: []particle{{"conditions", 0, -1}},
+		required: []string{"number", "hits"},
+		numeric:  []string{"number", "hits"},
+	},
+	"conditions": {children: []particle{{"condition", 0, -1}}},
+	"condition": {
+		required: []string{"number", "type", "coverage"},
+		numeric:  []string{"number"},
+	},
+}
+
+type openElement struct {
+	name     string
+	rule     elementRule
+	children []string
+}
+
+// ValidateXML checks that the Cobertura document read from in conforms to
+// the coverage-04 DTD: element nesting and order, required attributes and
+// numeric values.
+func ValidateXML(in io.Reader) error {
+	decoder := xml.NewDecoder(in)
+	var stack []*openElement
+	root := false
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			rule, ok := coberturaDTD[name]
+			if !ok {
+				return fmt.Errorf("unknown element <%s>", name)
+			}
+			if len(stack) == 0 {
+				if root || name != "coverage" {
+					return fmt.Errorf("unexpected root element <%s>, expected a single <coverage>", name)
+				}
+				root = true
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, name)
+			}
+			if err := validateAttributes(name, rule, t.Attr); err != nil {
+				return err
+			}
+			stack = append(stack, &openElement{name: name, rule: rule})
+		case xml.EndElement:
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if err := validateChildren(current); err != nil {
+				return err
+			}
+		case xml.CharData:
+			if len(stack) > 0 && !stack[len(stack)-1].rule.text && strings.TrimSpace(string(t)) != "" {
+				return fmt.Errorf("unexpected text in <%s>", stack[len(stack)-1].name)
+			}
+		}
+	}
+
+	if !root {
+		return fmt.Errorf("missing <coverage> element")
+	}
+	return nil
+}
+
+func validateAttributes(name string, rule elementRule, attrs []xml.Attr) error {
+	values := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		values[attr.Name.Local] = attr.Value
+	}
+	for _, required := range rule.required {
+		if _, ok := values[required]; !ok {
+			return fmt.Errorf("<%s> is missing the required %q attribute", name, required)
+		}
+	}
+	for _, numeric := range rule.numeric {
+		if value, ok := values[numeric]; ok {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("<%s> attribute %q is not a number: %q", name, numeric, value)
+			}
+		}
+	}
+	return nil
+}
+
+func validateChildren(element *openElement) error {
+	if element.rule.text {
+		if len(element.children) > 0 {
+			return fmt.Errorf("<%s> cannot contain <%s>", element.name, element.children[0])
+		}
+		return nil
+	}
+
+	children := element.children
+	for _, p := range element.rule.children {
+		count := 0
+		for len(children) > 0 && children[0] == p.name && (p.max < 0 || count < p.max) {
+			children = children[1:]
+			count++
+		}
+		if count < p.min {
+			return fmt.Errorf("<%s> requires a <%s> element", element.name, p.name)
+		}
+	}
+	if len(children) > 0 {
+		return fmt.Errorf("unexpected <%s> in <%s>", children[0], element.name)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cobertura
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateXMLConvertOutput(t *testing.T) {
+	t.Parallel()
+
+	in, err := os.Open("testdata/testdata_set.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = in.Close() })
+
+	var out bytes.Buffer
+	if err := Convert(in, &out, &Ignore{}, "testdata"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateXML(&out); err != nil {
+		t.Errorf("converted report is invalid: %v", err)
+	}
+}
+
+func TestValidateXML(t *testing.T) {
+	t.Parallel()
+
+	const attrs = `line-rate="1" branch-rate="0" lines-covered="1" lines-valid="1" branches-covered="0" ` +
+		`branches-valid="0" complexity="0" version="" timestamp="1"`
+
+	for _, test := range []struct {
+		Doc   string
+		Error string
+	}{
+		{
+			Doc: `<coverage ` + attrs + `><sources><source>/src</source></sources><packages>` +
+				`<package name="p" line-rate="1" branch-rate="0" complexity="0"><classes>` +
+				`<class name="c" filename="f.go" line-rate="1" branch-rate="0" complexity="0">` +
+				`<methods><method name="m" signature="" line-rate="1" branch-rate="0" complexity="0">` +
+				`<lines><line number="1" hits="1"/></lines></method></methods>` +
+				`<lines><line number="1" hits="1"/></lines></class></classes></package></packages></coverage>`,
+		},
+		{Doc: `<coverage ` + attrs + `></coverage>`, Error: "<coverage> requires a <packages> element"},
+		{Doc: `<coverage ` + attrs + `><packages/><sources/></coverage>`, Error: "unexpected <sources> in <coverage>"},
+		{Doc: `<coverage line-rate="1"><packages/></coverage>`, Error: `<coverage> is missing the required "branch-rate" attribute`},
+		{Doc: `<coverage ` + attrs + `><packages><foo/></packages></coverage>`, Error: "unknown element <foo>"},
+		{Doc: `<coverage ` + attrs + `><packages>text</packages></coverage>`, Error: "unexpected text in <packages>"},
+		{Doc: `<packages/>`, Error: "unexpected root element <packages>"},
+		{Doc: ``, Error: "missing <coverage> element"},
+		{
+			Doc:   `<coverage ` + strings.Replace(attrs, `lines-valid="1"`, `lines-valid="x"`, 1) + `><packages/></coverage>`,
+			Error: `<coverage> attribute "lines-valid" is not a number: "x"`,
+		},
+	} {
+		err := ValidateXML(strings.NewReader(test.Doc))
+		switch {
+		case test.Error == "" && err != nil:
+			t.Errorf("unexpected error %v for %s", err, test.Doc)
+		case test.Error != "" && (err == nil || !strings.Contains(err.Error(), test.Error)):
+			t.Errorf("expected error %q, got %v for %s", test.Error, err, test.Doc)
+		}
+	}
+}
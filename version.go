@@ -0,0 +1,54 @@
+package cobertura
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+const modulePath = "github.com/franchb/gocover-cobertura"
+
+// version returns the version of the converter, from the build information
+// embedded by the Go toolchain.
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "gocover-cobertura (unknown version)"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			// built as a library, the main module is the one importing it
+			info.Main = *dep
+		}
+	}
+	return formatVersion(info)
+}
+
+// formatVersion formats the module version, VCS revision and Go version of
+// info, as gocover-cobertura v1.2.0 (revision 0123abc, modified) go1.22.1.
+func formatVersion(info *debug.BuildInfo) string {
+	version := info.Main.Version
+	if version == "" {
+		version = "(devel)"
+	}
+
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			if setting.Value == "true" {
+				modified = ", modified"
+			}
+		}
+	}
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "gocover-cobertura %s", version)
+	if revision != "" {
+		_, _ = fmt.Fprintf(&b, " (revision %s%s)", revision, modified)
+	}
+	_, _ = fmt.Fprintf(&b, " %s", info.GoVersion)
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package cobertura
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFormatVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		info     debug.BuildInfo
+		expected string
+	}{
+		{
+			info: debug.BuildInfo{
+				GoVersion: "go1.22.1",
+				Main:      debug.Module{Version: "v1.2.0"},
+			},
+			expected: "gocover-cobertura v1.2.0 go1.22.1",
+		},
+		{
+			info: debug.BuildInfo{
+				GoVersion: "go1.22.1",
+				Settings: []debug.BuildSetting{
+					{Key: "vcs", Value: "git"},
+					{Key: "vcs.revision", Value: "0123abc"},
+					{Key: "vcs.modified", Value: "true"},
+				},
+			},
+			expected: "gocover-cobertura (devel) (revision 0123abc, modified) go1.22.1",
+		},
+	} {
+		if actual := formatVersion(&tc.info); actual != tc.expected {
+			t.Errorf("formatVersion() = %q, expected %q", actual, tc.expected)
+		}
+	}
+}